@@ -1,11 +1,14 @@
 package app
 
 import (
+	"database/sql"
 	"fmt"
 	"os"
 	"pokemon/config"
 	postgres "pokemon/config/postgre"
+	"pokemon/config/sqlite"
 
+	"pokemon/pokeapi"
 	"pokemon/repository"
 	"pokemon/services"
 
@@ -15,10 +18,61 @@ import (
 var router = gin.New()
 
 func StartApplication() {
-	pokemonRepo := repository.NewPokeRepo(postgres.PSQL.DB.DB)
+	pokemonRepo := newStore()
 	app := services.NewPokeUsecase(pokemonRepo)
 	config.RegisterApi(router, app)
 
 	port := os.Getenv("APP_PORT")
 	router.Run(fmt.Sprintf(":%s", port))
 }
+
+// newStore reads STORE, "sqlite" keeps everything in the SQLITE_PATH file and
+// "memory" only as long as the process runs
+func newStore() repository.PokeRepoInterface {
+	switch store := os.Getenv("STORE"); store {
+	case "", repository.StorePostgres:
+		if err := postgres.InitPostgre(); err != nil {
+			panic(err)
+		}
+		return repository.NewPokeRepo(postgres.PSQL.DB.DB, newPokedex(repository.StorePostgres, postgres.PSQL.DB.DB))
+	case repository.StoreSQLite:
+		db, err := sqlite.OpenSQLite()
+		if err != nil {
+			panic(err)
+		}
+		return repository.NewSQLiteRepo(db, newPokedex(repository.StoreSQLite, db))
+	case repository.StoreMemory:
+		return repository.NewMemoryRepo(newPokedex(repository.StoreMemory, nil))
+	default:
+		panic(fmt.Sprintf("unknown STORE %q", store))
+	}
+}
+
+// newPokedex reads POKEDEX_SOURCE, "offline" serves the snapshot imported into
+// postgres and never calls PokeAPI. The PokeAPI documents are cached in db
+// when there is one.
+func newPokedex(store string, db *sql.DB) repository.Pokedex {
+	switch source := os.Getenv("POKEDEX_SOURCE"); source {
+	case "", repository.PokedexPokeAPI:
+	case repository.PokedexOffline:
+		if store != repository.StorePostgres {
+			panic("POKEDEX_SOURCE=offline needs STORE=postgres, the snapshot is imported there")
+		}
+		return repository.NewOfflinePokedex(db)
+	default:
+		panic(fmt.Sprintf("unknown POKEDEX_SOURCE %q", source))
+	}
+
+	var cacheStore pokeapi.Store
+	if db != nil {
+		cacheStore = repository.NewPokeAPICacheStore(db)
+	}
+
+	pokeAPIConfig := pokeapi.ConfigFromEnv()
+	pokeAPIConfig.Cache = pokeapi.NewCache(
+		pokeAPIConfig.CacheSize,
+		pokeAPIConfig.CacheTTL,
+		cacheStore,
+	)
+	return repository.NewPokeAPIPokedex(pokeapi.NewClient(pokeAPIConfig))
+}
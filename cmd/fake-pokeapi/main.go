@@ -0,0 +1,42 @@
+// Command fake-pokeapi serves the fakeapi fixtures so the api can run against
+// it with POKEAPI_BASE_URL=http://localhost:8081
+//
+//	go run ./cmd/fake-pokeapi -addr :8081 -mode slow -delay 2s
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"pokemon/pokeapi/fakeapi"
+	"time"
+)
+
+func main() {
+	var (
+		addr     = flag.String("addr", ":8081", "address to listen on")
+		mode     = flag.String("mode", string(fakeapi.ModeNormal), "normal, error, rate_limit or slow")
+		delay    = flag.Duration("delay", time.Second, "how long the slow mode waits before answering")
+		failNext = flag.Int("fail-next", 0, "answer the first requests with a 503")
+		dir      = flag.String("fixtures", "", "directory with pokemon/, pokemon-species/ and move/ fixtures, the bundled ones by default")
+	)
+	flag.Parse()
+
+	serverMode, err := fakeapi.ParseMode(*mode)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	server := fakeapi.New()
+	if *dir != "" {
+		if server, err = fakeapi.NewFromFS(os.DirFS(*dir)); err != nil {
+			log.Fatal(err)
+		}
+	}
+	server.SetMode(serverMode, *delay)
+	server.FailNext(*failNext)
+
+	log.Printf("fake PokeAPI serving %d pokemon on %s in %s mode", len(server.Pokemon()), *addr, serverMode)
+	log.Fatal(http.ListenAndServe(*addr, server))
+}
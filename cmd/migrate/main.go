@@ -0,0 +1,84 @@
+// Command migrate applies, reverts and lists the schema migrations embedded
+// in the binary.
+//
+//	go run ./cmd/migrate up
+//	go run ./cmd/migrate down -steps 1
+//	go run ./cmd/migrate status
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	postgres "pokemon/config/postgre"
+	"pokemon/migrations"
+	"text/tabwriter"
+
+	"github.com/joho/godotenv"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: migrate up | down [-steps n] | status\n")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	godotenv.Load()
+	if err := postgres.ConnectPostgre(); err != nil {
+		log.Fatal(err)
+	}
+
+	migrator, err := migrations.New(postgres.PSQL.DB.DB)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch os.Args[1] {
+	case "up":
+		applied, err := migrator.Up()
+		for _, m := range applied {
+			log.Printf("applied %04d %s", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(applied) == 0 {
+			log.Print("schema is up to date")
+		}
+	case "down":
+		flags := flag.NewFlagSet("down", flag.ExitOnError)
+		steps := flags.Int("steps", 1, "how many migrations to revert")
+		flags.Parse(os.Args[2:])
+
+		reverted, err := migrator.Down(*steps)
+		for _, m := range reverted {
+			log.Printf("reverted %04d %s", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+	case "status":
+		status, err := migrator.Status()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range status {
+			appliedAt := "pending"
+			if s.Applied {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		w.Flush()
+	default:
+		usage()
+	}
+}
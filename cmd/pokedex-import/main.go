@@ -0,0 +1,56 @@
+// Command pokedex-import loads a local pokedex snapshot into postgres so the
+// api can run with POKEDEX_SOURCE=offline.
+//
+//	go run ./cmd/pokedex-import -file pokedex.ndjson
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	postgres "pokemon/config/postgre"
+	"pokemon/migrations"
+	"pokemon/repository"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	file := flag.String("file", "", "snapshot to import, a JSON array or NDJSON of PokeAPI pokemon")
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	godotenv.Load()
+	if err := postgres.ConnectPostgre(); err != nil {
+		log.Fatal(err)
+	}
+
+	migrator, err := migrations.New(postgres.PSQL.DB.DB)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		log.Fatal(err)
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	pokemons, err := repository.ReadSnapshot(f)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := repository.NewOfflinePokedex(postgres.PSQL.DB.DB).Import(pokemons); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("imported %d pokemon from %s", len(pokemons), *file)
+}
@@ -14,6 +14,18 @@ func RegisterApi(r *gin.Engine, app services.PokeUsecaseInterface) {
 		api.POST("/battle", pokeSrv.PostBattle)
 		api.GET("/", pokeSrv.GetAllPokemons)
 		api.GET("/battles", pokeSrv.GetBattle)
+		api.POST("/battles/:id/replay", pokeSrv.ReplayBattle)
+		api.GET("/battles/:id/events", pokeSrv.GetBattleEvents)
+		api.POST("/battles/:id/annul", pokeSrv.AnnulPokemon)
+		api.POST("/tournaments", pokeSrv.CreateTournament)
+		api.POST("/tournaments/:id/advance", pokeSrv.AdvanceTournament)
+		api.GET("/tournaments/:id", pokeSrv.GetTournament)
+		api.POST("/leagues", pokeSrv.CreateLeague)
+		api.POST("/leagues/:id/play", pokeSrv.PlayLeagueRound)
+		api.GET("/leagues/:id", pokeSrv.GetLeague)
+		api.GET("/leagues/:id/standings", pokeSrv.GetLeagueStandings)
 		api.GET("/scores", pokeSrv.GetPokemonScore)
+		api.GET("/ratings", pokeSrv.GetRatings)
+		api.GET("/cache", pokeSrv.GetCacheStats)
 	}
 }
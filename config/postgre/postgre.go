@@ -3,8 +3,8 @@ package postgre
 import (
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"os"
+	"pokemon/migrations"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
@@ -39,6 +39,26 @@ var (
 )
 
 func InitPostgre() error {
+	if err := ConnectPostgre(); err != nil {
+		return err
+	}
+
+	// table migration, only the migrations not applied yet run
+	migrator, err := migrations.New(PSQL.DB.DB)
+	if err != nil {
+		return err
+	}
+
+	if _, err := migrator.Up(); err != nil {
+		PSQL.DB.Close()
+		return fmt.Errorf("migrate postgres: %w", err)
+	}
+
+	return nil
+}
+
+// ConnectPostgre connects without migrating the schema
+func ConnectPostgre() error {
 	PSQL = new(PsqlDb)
 
 	//init psql model
@@ -60,16 +80,6 @@ func InitPostgre() error {
 		return err
 	}
 
-	// table migration
-	query, err := ioutil.ReadFile("./migrations/migration.sql")
-	if err != nil {
-		return err
-	}
-
-	if _, err := PSQL.DB.Exec(string(query)); err != nil {
-		panic(err)
-	}
-
 	return nil
 }
 
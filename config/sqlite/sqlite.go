@@ -0,0 +1,52 @@
+package sqlite
+
+import (
+	"database/sql"
+	"os"
+	"pokemon/migrations"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const DefaultPath = "pokemon.db"
+
+// OpenSQLite opens the database at SQLITE_PATH, creating the file when it's
+// missing, and applies the migrations not applied yet
+func OpenSQLite() (*sql.DB, error) {
+	path := os.Getenv("SQLITE_PATH")
+	if path == "" {
+		path = DefaultPath
+	}
+	return Open(path)
+}
+
+// Open opens and migrates the database at path, ":memory:" is a database
+// that lives as long as the returned *sql.DB
+func Open(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", "file:"+path+"?_foreign_keys=1&_busy_timeout=5000")
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite writes one at a time, and every connection to :memory: would
+	// be a database of its own
+	db.SetMaxOpenConns(1)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	migrator, err := migrations.NewSQLite(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if _, err := migrator.Up(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"pokemon/pokeapi"
+	"pokemon/repository"
+	"pokemon/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	ErrInvalidBattleID     = errors.New("battle id must be a number")
+	ErrInvalidTournamentID = errors.New("tournament id must be a number")
+	ErrInvalidLeagueID     = errors.New("league id must be a number")
+)
+
+// abortWithError writes the error as JSON with the status code that matches it
+func abortWithError(c *gin.Context, err error) {
+	var (
+		status   = http.StatusInternalServerError
+		unknown  *services.UnknownPokemonError
+		upstream *pokeapi.UpstreamError
+	)
+
+	switch {
+	case errors.As(err, &unknown):
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "unknown": unknown.Names})
+		return
+	case errors.Is(err, services.ErrNotEnoughPokemons):
+		status = http.StatusUnprocessableEntity
+	case errors.Is(err, services.ErrInvalidPokemons),
+		errors.Is(err, services.ErrDuplicatePokemon),
+		errors.Is(err, services.ErrInvalidMovePolicy),
+		errors.Is(err, services.ErrInvalidEngine),
+		errors.Is(err, services.ErrInvalidFilter),
+		errors.Is(err, repository.ErrSpeciesUnavailable),
+		errors.Is(err, services.ErrInvalidSearch),
+		errors.Is(err, services.ErrInvalidLeaderboard),
+		errors.Is(err, services.ErrInvalidTournamentSize),
+		errors.Is(err, services.ErrInvalidTournamentRoster),
+		errors.Is(err, services.ErrInvalidLeagueRoster),
+		errors.Is(err, services.ErrInvalidTieBreaker),
+		errors.Is(err, ErrInvalidBattleID),
+		errors.Is(err, ErrInvalidTournamentID),
+		errors.Is(err, ErrInvalidLeagueID):
+		status = http.StatusBadRequest
+	case errors.Is(err, repository.ErrBattleNotFound),
+		errors.Is(err, repository.ErrTournamentNotFound),
+		errors.Is(err, repository.ErrLeagueNotFound),
+		errors.Is(err, repository.ErrPokemonNotInBattle):
+		status = http.StatusNotFound
+	case errors.Is(err, repository.ErrAlreadyAnnulled),
+		errors.Is(err, services.ErrLastParticipant),
+		errors.Is(err, services.ErrEngineChanged),
+		errors.Is(err, services.ErrTournamentFinished),
+		errors.Is(err, repository.ErrTournamentAdvanced),
+		errors.Is(err, repository.ErrMatchPlayed),
+		errors.Is(err, repository.ErrLeagueAdvanced),
+		errors.Is(err, repository.ErrFixturePlayed),
+		errors.Is(err, services.ErrLeagueFinished):
+		status = http.StatusConflict
+	case errors.As(err, &upstream):
+		status = http.StatusBadGateway
+	}
+
+	c.AbortWithStatusJSON(status, gin.H{"error": err.Error()})
+}
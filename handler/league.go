@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"net/http"
+	"pokemon/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (p *PokemonHttpServer) CreateLeague(c *gin.Context) {
+	var req models.RequestLeague
+	err := c.BindJSON(&req)
+	if err != nil {
+		return
+	}
+
+	res, err := p.app.CreateLeague(c.Request.Context(), req)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, res)
+}
+
+func (p *PokemonHttpServer) PlayLeagueRound(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, ErrInvalidLeagueID)
+		return
+	}
+
+	res, err := p.app.PlayLeagueRound(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (p *PokemonHttpServer) GetLeague(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, ErrInvalidLeagueID)
+		return
+	}
+
+	data, err := p.app.GetLeague(id)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, data)
+}
+
+func (p *PokemonHttpServer) GetLeagueStandings(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, ErrInvalidLeagueID)
+		return
+	}
+
+	data, err := p.app.GetLeagueStandings(id)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, data)
+}
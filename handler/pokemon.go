@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"pokemon/models"
 	"pokemon/services"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -24,8 +25,9 @@ func (p *PokemonHttpServer) PostBattle(c *gin.Context) {
 		return
 	}
 
-	res, err := p.app.PostBattle(req.Pokemons)
+	res, err := p.app.PostBattle(c.Request.Context(), req)
 	if err != nil {
+		abortWithError(c, err)
 		return
 	}
 
@@ -33,9 +35,65 @@ func (p *PokemonHttpServer) PostBattle(c *gin.Context) {
 
 }
 
+func (p *PokemonHttpServer) ReplayBattle(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, ErrInvalidBattleID)
+		return
+	}
+
+	res, err := p.app.ReplayBattle(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (p *PokemonHttpServer) GetBattleEvents(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, ErrInvalidBattleID)
+		return
+	}
+
+	data, err := p.app.GetBattleEvents(id)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, data)
+}
+
+func (p *PokemonHttpServer) AnnulPokemon(c *gin.Context) {
+	var req models.RequestAnnul
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, ErrInvalidBattleID)
+		return
+	}
+
+	err = c.BindJSON(&req)
+	if err != nil {
+		return
+	}
+
+	res, err := p.app.AnnulPokemon(id, req.Name)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
 func (p *PokemonHttpServer) GetAllPokemons(c *gin.Context) {
-	data, err := p.app.GetAllPokemons()
+	data, err := p.app.GetAllPokemons(c.Request.Context())
 	if err != nil {
+		abortWithError(c, err)
 		return
 	}
 
@@ -43,19 +101,31 @@ func (p *PokemonHttpServer) GetAllPokemons(c *gin.Context) {
 }
 
 func (p *PokemonHttpServer) GetBattle(c *gin.Context) {
-	start := c.Query("start_time")
-	end := c.Query("end_time")
-	data, err := p.app.GetBattle(start, end)
+	var req models.RequestBattleSearch
+	err := c.BindQuery(&req)
 	if err != nil {
 		return
 	}
 
+	data, err := p.app.GetBattle(req)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
 	c.JSON(http.StatusOK, data)
 }
 
 func (p *PokemonHttpServer) GetPokemonScore(c *gin.Context) {
-	data, err := p.app.GetPokemonScore()
+	var req models.RequestLeaderboard
+	err := c.BindQuery(&req)
+	if err != nil {
+		return
+	}
+
+	data, err := p.app.GetPokemonScore(req)
 	if err != nil {
+		abortWithError(c, err)
 		return
 	}
 
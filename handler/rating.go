@@ -0,0 +1,17 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (p *PokemonHttpServer) GetRatings(c *gin.Context) {
+	data, err := p.app.GetRatings()
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, data)
+}
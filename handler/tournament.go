@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"net/http"
+	"pokemon/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (p *PokemonHttpServer) CreateTournament(c *gin.Context) {
+	var req models.RequestTournament
+	err := c.BindJSON(&req)
+	if err != nil {
+		return
+	}
+
+	res, err := p.app.CreateTournament(c.Request.Context(), req)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, res)
+}
+
+func (p *PokemonHttpServer) AdvanceTournament(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, ErrInvalidTournamentID)
+		return
+	}
+
+	res, err := p.app.AdvanceTournament(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (p *PokemonHttpServer) GetTournament(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, ErrInvalidTournamentID)
+		return
+	}
+
+	data, err := p.app.GetTournament(id)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, data)
+}
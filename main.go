@@ -2,7 +2,6 @@ package main
 
 import (
 	"pokemon/app"
-	config "pokemon/config/postgre"
 
 	"github.com/joho/godotenv"
 )
@@ -10,9 +9,6 @@ import (
 
 func init() {
 	godotenv.Load()
-	if err := config.InitPostgre(); err != nil {
-		panic(err)
-	}
 }
 
 func main() {
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS pokeapi_cache;
+DROP TABLE IF EXISTS rating_history;
+DROP TABLE IF EXISTS rating;
+DROP TABLE IF EXISTS league_fixture;
+DROP TABLE IF EXISTS league;
+DROP TABLE IF EXISTS tournament_match;
+DROP TABLE IF EXISTS tournament;
+DROP TABLE IF EXISTS battle_events;
+DROP TABLE IF EXISTS battle;
+DROP TABLE IF EXISTS pokemon;
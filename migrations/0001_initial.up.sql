@@ -0,0 +1,105 @@
+CREATE TABLE IF NOT EXISTS pokemon(
+   pokemon_id SERIAL PRIMARY KEY,
+   name varchar(255) NOT NULL,
+   battle_id int NOT NULL,
+   placement int NOT NULL,
+   scores int NOT NULL,
+   annulled boolean NOT NULL DEFAULT false
+);
+
+CREATE TABLE IF NOT EXISTS battle(
+   battle_id SERIAL PRIMARY KEY,
+   winner varchar(255) NOT NULL,
+   seed bigint NOT NULL,
+   roster text NOT NULL,
+   move_policy varchar(255) NOT NULL,
+   engine varchar(255) NOT NULL,
+   engine_version int NOT NULL,
+   start_time timestamp NOT NULL,
+   end_time timestamp NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS battle_events(
+   event_id SERIAL PRIMARY KEY,
+   battle_id int NOT NULL,
+   turn int NOT NULL,
+   actor varchar(255) NOT NULL,
+   target varchar(255) NOT NULL,
+   move varchar(255) NOT NULL,
+   damage int NOT NULL,
+   remaining_hp int NOT NULL,
+   eliminated boolean NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS tournament(
+   tournament_id SERIAL PRIMARY KEY,
+   name varchar(255) NOT NULL,
+   size int NOT NULL,
+   seed bigint NOT NULL,
+   round int NOT NULL,
+   winner varchar(255) NOT NULL,
+   created_at timestamp NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS tournament_match(
+   match_id SERIAL PRIMARY KEY,
+   tournament_id int NOT NULL,
+   round int NOT NULL,
+   slot int NOT NULL,
+   pokemon_a varchar(255) NOT NULL,
+   pokemon_b varchar(255) NOT NULL,
+   winner varchar(255) NOT NULL,
+   battle_id int NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS league(
+   league_id SERIAL PRIMARY KEY,
+   name varchar(255) NOT NULL,
+   seed bigint NOT NULL,
+   round int NOT NULL,
+   rounds int NOT NULL,
+   points_win int NOT NULL,
+   points_draw int NOT NULL,
+   points_loss int NOT NULL,
+   tie_breakers text NOT NULL,
+   created_at timestamp NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS league_fixture(
+   fixture_id SERIAL PRIMARY KEY,
+   league_id int NOT NULL,
+   round int NOT NULL,
+   pokemon_a varchar(255) NOT NULL,
+   pokemon_b varchar(255) NOT NULL,
+   played boolean NOT NULL,
+   winner varchar(255) NOT NULL,
+   draw boolean NOT NULL,
+   battle_id int NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS rating(
+   name varchar(255) PRIMARY KEY,
+   rating double precision NOT NULL,
+   rd double precision NOT NULL,
+   volatility double precision NOT NULL,
+   games int NOT NULL,
+   updated_at timestamp NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS rating_history(
+   history_id SERIAL PRIMARY KEY,
+   battle_id int NOT NULL,
+   name varchar(255) NOT NULL,
+   rating_before double precision NOT NULL,
+   rating_after double precision NOT NULL,
+   rd_before double precision NOT NULL,
+   rd_after double precision NOT NULL,
+   created_at timestamp NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS pokeapi_cache(
+   url text PRIMARY KEY,
+   etag varchar(255) NOT NULL,
+   body bytea NOT NULL,
+   fetched_at timestamp NOT NULL
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS pokedex_types;
+DROP TABLE IF EXISTS pokedex_stats;
+DROP TABLE IF EXISTS pokedex_species;
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS pokedex_species(
+   name varchar(255) PRIMARY KEY,
+   id int NOT NULL,
+   position int NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS pokedex_stats(
+   stat_id SERIAL PRIMARY KEY,
+   name varchar(255) NOT NULL,
+   stat varchar(255) NOT NULL,
+   base_stat int NOT NULL,
+   effort int NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS pokedex_types(
+   name varchar(255) NOT NULL,
+   slot int NOT NULL,
+   type varchar(255) NOT NULL,
+   PRIMARY KEY (name, slot)
+);
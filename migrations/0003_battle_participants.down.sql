@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS pokemon(
+   pokemon_id SERIAL PRIMARY KEY,
+   name varchar(255) NOT NULL,
+   battle_id int NOT NULL,
+   placement int NOT NULL,
+   scores int NOT NULL,
+   annulled boolean NOT NULL DEFAULT false
+);
+
+INSERT INTO pokemon(name, battle_id, placement, scores, annulled)
+SELECT s.name, bp.battle_id, bp.placement, bp.score, bp.annulled
+FROM battle_participants bp
+JOIN species s ON s.species_id = bp.species_id
+ORDER BY bp.participant_id;
+
+CREATE TABLE IF NOT EXISTS battle(
+   battle_id SERIAL PRIMARY KEY,
+   winner varchar(255) NOT NULL,
+   seed bigint NOT NULL,
+   roster text NOT NULL,
+   move_policy varchar(255) NOT NULL,
+   engine varchar(255) NOT NULL,
+   engine_version int NOT NULL,
+   start_time timestamp NOT NULL,
+   end_time timestamp NOT NULL
+);
+
+INSERT INTO battle(battle_id, winner, seed, roster, move_policy, engine, engine_version, start_time, end_time)
+SELECT b.battle_id, s.name, b.seed, b.roster, b.move_policy, b.engine, b.engine_version, b.start_time, b.end_time
+FROM battles b
+JOIN species s ON s.species_id = b.winner_id;
+
+SELECT setval(pg_get_serial_sequence('battle', 'battle_id'), COALESCE(MAX(battle_id), 0) + 1, false) FROM battle;
+
+DROP TABLE battle_participants;
+DROP TABLE battles;
+DROP TABLE species;
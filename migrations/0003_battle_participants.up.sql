@@ -0,0 +1,57 @@
+-- pokemon was the participants of a battle keyed by free text names, it
+-- becomes battle_participants pointing at species and battles
+
+CREATE TABLE IF NOT EXISTS species(
+   species_id SERIAL PRIMARY KEY,
+   name varchar(255) NOT NULL UNIQUE
+);
+
+INSERT INTO species(name)
+SELECT name FROM pokemon
+UNION
+SELECT winner FROM battle
+ON CONFLICT (name) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS battles(
+   battle_id SERIAL PRIMARY KEY,
+   winner_id int NOT NULL REFERENCES species(species_id),
+   seed bigint NOT NULL,
+   roster text NOT NULL,
+   move_policy varchar(255) NOT NULL,
+   engine varchar(255) NOT NULL,
+   engine_version int NOT NULL,
+   start_time timestamp NOT NULL,
+   end_time timestamp NOT NULL
+);
+
+INSERT INTO battles(battle_id, winner_id, seed, roster, move_policy, engine, engine_version, start_time, end_time)
+SELECT b.battle_id, s.species_id, b.seed, b.roster, b.move_policy, b.engine, b.engine_version, b.start_time, b.end_time
+FROM battle b
+JOIN species s ON s.name = b.winner;
+
+SELECT setval(pg_get_serial_sequence('battles', 'battle_id'), COALESCE(MAX(battle_id), 0) + 1, false) FROM battles;
+
+CREATE TABLE IF NOT EXISTS battle_participants(
+   participant_id SERIAL PRIMARY KEY,
+   battle_id int NOT NULL REFERENCES battles(battle_id) ON DELETE CASCADE,
+   species_id int NOT NULL REFERENCES species(species_id),
+   placement int NOT NULL,
+   score int NOT NULL,
+   annulled boolean NOT NULL DEFAULT false,
+   UNIQUE (battle_id, species_id)
+);
+
+CREATE INDEX IF NOT EXISTS battle_participants_species_id ON battle_participants(species_id);
+
+-- participants of battles that no longer exist are left behind, so is the
+-- second row of a pokemon listed twice in the same battle
+INSERT INTO battle_participants(battle_id, species_id, placement, score, annulled)
+SELECT p.battle_id, s.species_id, p.placement, p.scores, p.annulled
+FROM pokemon p
+JOIN species s ON s.name = p.name
+JOIN battles b ON b.battle_id = p.battle_id
+ORDER BY p.pokemon_id
+ON CONFLICT (battle_id, species_id) DO NOTHING;
+
+DROP TABLE pokemon;
+DROP TABLE battle;
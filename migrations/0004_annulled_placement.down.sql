@@ -0,0 +1,3 @@
+-- the placements the annulled pokemon had are gone, the ranking stays as it
+-- is now
+SELECT 1;
@@ -0,0 +1,11 @@
+-- a round drawn twice by advances that failed half way keeps the matches
+-- drawn first
+
+DELETE FROM tournament_match
+WHERE match_id NOT IN (
+   SELECT MIN(match_id)
+   FROM tournament_match
+   GROUP BY tournament_id, round, slot
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS tournament_match_round_slot ON tournament_match(tournament_id, round, slot);
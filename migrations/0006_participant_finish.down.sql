@@ -0,0 +1 @@
+ALTER TABLE battle_participants DROP COLUMN finish;
@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS league_fixture_battle_id;
+DROP INDEX IF EXISTS tournament_match_battle_id;
+DROP INDEX IF EXISTS rating_history_battle_id;
+DROP INDEX IF EXISTS battle_events_battle_id;
+
+ALTER TABLE league_fixture DROP CONSTRAINT IF EXISTS league_fixture_battle_id_fkey;
+ALTER TABLE tournament_match DROP CONSTRAINT IF EXISTS tournament_match_battle_id_fkey;
+ALTER TABLE rating_history DROP CONSTRAINT IF EXISTS rating_history_battle_id_fkey;
+ALTER TABLE battle_events DROP CONSTRAINT IF EXISTS battle_events_battle_id_fkey;
+
+UPDATE league_fixture SET battle_id = 0 WHERE battle_id IS NULL;
+ALTER TABLE league_fixture ALTER COLUMN battle_id SET NOT NULL;
+
+UPDATE tournament_match SET battle_id = 0 WHERE battle_id IS NULL;
+ALTER TABLE tournament_match ALTER COLUMN battle_id SET NOT NULL;
@@ -0,0 +1,40 @@
+-- the event log, the rating history, the tournament matches and the league
+-- fixtures kept the battle_id of their battle without referencing it. The
+-- log and the history go with their battle, a match or a fixture keeps its
+-- result and loses the battle. Unplayed matches and fixtures had battle_id
+-- 0, they get NULL.
+
+DELETE FROM battle_events
+WHERE battle_id NOT IN (SELECT battle_id FROM battles);
+
+DELETE FROM rating_history
+WHERE battle_id NOT IN (SELECT battle_id FROM battles);
+
+ALTER TABLE tournament_match ALTER COLUMN battle_id DROP NOT NULL;
+
+UPDATE tournament_match
+SET battle_id = NULL
+WHERE battle_id NOT IN (SELECT battle_id FROM battles);
+
+ALTER TABLE league_fixture ALTER COLUMN battle_id DROP NOT NULL;
+
+UPDATE league_fixture
+SET battle_id = NULL
+WHERE battle_id NOT IN (SELECT battle_id FROM battles);
+
+ALTER TABLE battle_events
+   ADD CONSTRAINT battle_events_battle_id_fkey FOREIGN KEY (battle_id) REFERENCES battles(battle_id) ON DELETE CASCADE;
+
+ALTER TABLE rating_history
+   ADD CONSTRAINT rating_history_battle_id_fkey FOREIGN KEY (battle_id) REFERENCES battles(battle_id) ON DELETE CASCADE;
+
+ALTER TABLE tournament_match
+   ADD CONSTRAINT tournament_match_battle_id_fkey FOREIGN KEY (battle_id) REFERENCES battles(battle_id) ON DELETE SET NULL;
+
+ALTER TABLE league_fixture
+   ADD CONSTRAINT league_fixture_battle_id_fkey FOREIGN KEY (battle_id) REFERENCES battles(battle_id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS battle_events_battle_id ON battle_events(battle_id);
+CREATE INDEX IF NOT EXISTS rating_history_battle_id ON rating_history(battle_id);
+CREATE INDEX IF NOT EXISTS tournament_match_battle_id ON tournament_match(battle_id);
+CREATE INDEX IF NOT EXISTS league_fixture_battle_id ON league_fixture(battle_id);
@@ -0,0 +1,280 @@
+// Package migrations keeps the database schema up to date. Every change to
+// the schema is a numbered pair of files, NNNN_name.up.sql applies it and
+// NNNN_name.down.sql reverts it, both embedded in the binary. The versions
+// applied so far are recorded in the schema_migrations table.
+//
+// The files at the top are the Postgres schema, the SQLite store has its own
+// under sqlite/. A change to the schema needs a migration in both.
+package migrations
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+//go:embed sqlite/*.sql
+var sqliteFiles embed.FS
+
+var (
+	ErrInvalidMigration = errors.New("invalid migration")
+	ErrNothingToRevert  = errors.New("no migration to revert")
+)
+
+const (
+	createSchemaMigrations = `
+		CREATE TABLE IF NOT EXISTS schema_migrations(
+			version int PRIMARY KEY,
+			name varchar(255) NOT NULL,
+			applied_at timestamp NOT NULL
+		)
+	`
+
+	getSchemaMigrations = `
+		SELECT
+			version,
+			applied_at
+		FROM schema_migrations
+		ORDER BY version
+	`
+
+	postSchemaMigration = `
+		INSERT INTO
+			schema_migrations(
+				version,
+				name,
+				applied_at
+			)
+		VALUES(
+			$1, $2, $3
+		)
+	`
+
+	deleteSchemaMigration = `
+		DELETE FROM schema_migrations
+		WHERE version = $1
+	`
+)
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status tells whether a migration was applied and when
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Load reads the migrations of fsys in version order, every version needs
+// both its up and its down file
+func Load(fsys fs.FS) (res []Migration, err error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, file := range names {
+		base := strings.TrimSuffix(path.Base(file), ".sql")
+
+		direction := path.Ext(base)
+		base = strings.TrimSuffix(base, direction)
+
+		number, name, ok := strings.Cut(base, "_")
+		version, err := strconv.Atoi(number)
+		if !ok || err != nil || version <= 0 || (direction != ".up" && direction != ".down") {
+			return nil, fmt.Errorf("%w: %s isn't named NNNN_name.up.sql or NNNN_name.down.sql", ErrInvalidMigration, file)
+		}
+
+		body, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		m, found := byVersion[version]
+		if !found {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if m.Name != name {
+			return nil, fmt.Errorf("%w: version %d is both %s and %s", ErrInvalidMigration, version, m.Name, name)
+		}
+
+		if direction == ".up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("%w: version %d needs both an up and a down file", ErrInvalidMigration, m.Version)
+		}
+		res = append(res, *m)
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Version < res[j].Version })
+	return res, nil
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	now        func() time.Time
+}
+
+// New migrates db with the migrations embedded in the binary
+func New(db *sql.DB) (*Migrator, error) {
+	migrations, err := Load(files)
+	if err != nil {
+		return nil, err
+	}
+	return NewWithMigrations(db, migrations), nil
+}
+
+// NewSQLite migrates a SQLite db with the migrations under sqlite/
+func NewSQLite(db *sql.DB) (*Migrator, error) {
+	sqliteFS, err := fs.Sub(sqliteFiles, "sqlite")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations, err := Load(sqliteFS)
+	if err != nil {
+		return nil, err
+	}
+	return NewWithMigrations(db, migrations), nil
+}
+
+func NewWithMigrations(db *sql.DB, migrations []Migration) *Migrator {
+	return &Migrator{db: db, migrations: migrations, now: time.Now}
+}
+
+// Up applies every migration that wasn't applied yet, each in its own
+// transaction, and returns them
+func (m *Migrator) Up() (res []Migration, err error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		err = m.run(migration.Up, postSchemaMigration, migration.Version, migration.Name, m.now())
+		if err != nil {
+			return res, fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+		res = append(res, migration)
+	}
+
+	return res, nil
+}
+
+// Down reverts the latest steps applied migrations, newest first
+func (m *Migrator) Down(steps int) (res []Migration, err error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0 && len(res) < steps; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		err = m.run(migration.Down, deleteSchemaMigration, migration.Version)
+		if err != nil {
+			return res, fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+		res = append(res, migration)
+	}
+
+	if len(res) == 0 && steps > 0 {
+		return nil, ErrNothingToRevert
+	}
+	return res, nil
+}
+
+// Status lists every known migration in version order
+func (m *Migrator) Status() (res []Status, err error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, migration := range m.migrations {
+		at, ok := applied[migration.Version]
+		res = append(res, Status{Migration: migration, Applied: ok, AppliedAt: at})
+	}
+	return res, nil
+}
+
+// applied creates schema_migrations when it's missing and tells when every
+// applied version was applied
+func (m *Migrator) applied() (res map[int]time.Time, err error) {
+	if _, err = m.db.Exec(createSchemaMigrations); err != nil {
+		return nil, err
+	}
+
+	row, err := m.db.Query(getSchemaMigrations)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	res = make(map[int]time.Time)
+	for row.Next() {
+		var (
+			version int
+			at      time.Time
+		)
+		if err = row.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		res[version] = at
+	}
+	return res, row.Err()
+}
+
+// run executes the migration script and records it in the same transaction
+func (m *Migrator) run(script, record string, args ...interface{}) (err error) {
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.Exec(script); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(record, args...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
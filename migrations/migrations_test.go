@@ -0,0 +1,249 @@
+package migrations
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testMigrations = []Migration{
+	{Version: 1, Name: "initial", Up: "CREATE TABLE battle(battle_id int)", Down: "DROP TABLE battle"},
+	{Version: 2, Name: "pokedex", Up: "CREATE TABLE pokedex_species(name text)", Down: "DROP TABLE pokedex_species"},
+}
+
+func Test_Load(t *testing.T) {
+	type testCase struct {
+		name           string
+		fsys           fstest.MapFS
+		wantError      bool
+		expectedResult []Migration
+	}
+
+	var (
+		testTable []testCase
+		file      = func(body string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(body)} }
+	)
+
+	testTable = append(testTable, testCase{
+		name: "success",
+		fsys: fstest.MapFS{
+			"0002_pokedex.up.sql":   file("CREATE TABLE pokedex_species(name text)"),
+			"0002_pokedex.down.sql": file("DROP TABLE pokedex_species"),
+			"0001_initial.up.sql":   file("CREATE TABLE battle(battle_id int)"),
+			"0001_initial.down.sql": file("DROP TABLE battle"),
+		},
+		expectedResult: testMigrations,
+	})
+
+	testTable = append(testTable, testCase{
+		name: "failed missing down",
+		fsys: fstest.MapFS{
+			"0001_initial.up.sql": file("CREATE TABLE battle(battle_id int)"),
+		},
+		wantError: true,
+	})
+
+	testTable = append(testTable, testCase{
+		name: "failed bad name",
+		fsys: fstest.MapFS{
+			"initial.up.sql": file("CREATE TABLE battle(battle_id int)"),
+		},
+		wantError: true,
+	})
+
+	testTable = append(testTable, testCase{
+		name: "failed version reused",
+		fsys: fstest.MapFS{
+			"0001_initial.up.sql": file("CREATE TABLE battle(battle_id int)"),
+			"0001_other.down.sql": file("DROP TABLE battle"),
+		},
+		wantError: true,
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := Load(tc.fsys)
+			if tc.wantError {
+				assert.ErrorIs(t, err, ErrInvalidMigration)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tc.expectedResult, res)
+			}
+		})
+	}
+}
+
+func Test_Load_Embedded(t *testing.T) {
+	res, err := Load(files)
+	require.NoError(t, err)
+	require.NotEmpty(t, res)
+
+	for i, m := range res {
+		assert.Equal(t, i+1, m.Version, "versions must follow each other")
+	}
+}
+
+func expectApplied(mock sqlmock.Sqlmock, versions ...int) {
+	mock.ExpectExec(regexp.QuoteMeta(createSchemaMigrations)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	rows := sqlmock.NewRows([]string{"version", "applied_at"})
+	for _, v := range versions {
+		rows.AddRow(v, time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC))
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(getSchemaMigrations)).
+		WillReturnRows(rows)
+}
+
+func Test_Migrator_Up(t *testing.T) {
+	type testCase struct {
+		name          string
+		wantError     bool
+		mockQuery     func(mock sqlmock.Sqlmock)
+		expectedError error
+		expectedCount int
+	}
+
+	var (
+		testTable []testCase
+		now       = time.Date(2022, 10, 2, 0, 0, 0, 0, time.UTC)
+	)
+
+	testTable = append(testTable, testCase{
+		name: "success only pending",
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			expectApplied(mock, 1)
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(testMigrations[1].Up)).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta(postSchemaMigration)).
+				WithArgs(2, "pokedex", now).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+		},
+		expectedCount: 1,
+	})
+
+	testTable = append(testTable, testCase{
+		name: "success up to date",
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			expectApplied(mock, 1, 2)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "failed rolls back",
+		wantError: true,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			expectApplied(mock)
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(testMigrations[0].Up)).
+				WillReturnError(errors.New("syntax error"))
+			mock.ExpectRollback()
+		},
+		expectedError: errors.New("migration 1 initial: syntax error"),
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+
+			tc.mockQuery(mock)
+			migrator := NewWithMigrations(db, testMigrations)
+			migrator.now = func() time.Time { return now }
+
+			res, serr := migrator.Up()
+			if tc.wantError {
+				assert.EqualError(t, serr, tc.expectedError.Error())
+			} else {
+				assert.Nil(t, serr)
+				assert.Len(t, res, tc.expectedCount)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_Migrator_Down(t *testing.T) {
+	type testCase struct {
+		name          string
+		steps         int
+		wantError     bool
+		mockQuery     func(mock sqlmock.Sqlmock)
+		expectedError error
+		expectedNames []string
+	}
+
+	var testTable []testCase
+
+	testTable = append(testTable, testCase{
+		name:  "success latest first",
+		steps: 2,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			expectApplied(mock, 1, 2)
+			for _, m := range []Migration{testMigrations[1], testMigrations[0]} {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(m.Down)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta(deleteSchemaMigration)).
+					WithArgs(m.Version).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			}
+		},
+		expectedNames: []string{"pokedex", "initial"},
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "failed nothing applied",
+		steps:         1,
+		wantError:     true,
+		mockQuery:     func(mock sqlmock.Sqlmock) { expectApplied(mock) },
+		expectedError: ErrNothingToRevert,
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+
+			tc.mockQuery(mock)
+			migrator := NewWithMigrations(db, testMigrations)
+
+			res, serr := migrator.Down(tc.steps)
+			if tc.wantError {
+				assert.EqualError(t, serr, tc.expectedError.Error())
+			} else {
+				assert.Nil(t, serr)
+				var names []string
+				for _, m := range res {
+					names = append(names, m.Name)
+				}
+				assert.Equal(t, tc.expectedNames, names)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_Migrator_Status(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	expectApplied(mock, 1)
+
+	res, err := NewWithMigrations(db, testMigrations).Status()
+	assert.Nil(t, err)
+	require.Len(t, res, 2)
+	assert.True(t, res[0].Applied)
+	assert.Equal(t, time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC), res[0].AppliedAt)
+	assert.False(t, res[1].Applied)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,116 @@
+-- the schema the Postgres migrations have reached, a SQLite database starts
+-- with no history to carry over
+
+CREATE TABLE IF NOT EXISTS species(
+   species_id INTEGER PRIMARY KEY AUTOINCREMENT,
+   name varchar(255) NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS battles(
+   battle_id INTEGER PRIMARY KEY AUTOINCREMENT,
+   winner_id int NOT NULL REFERENCES species(species_id),
+   seed bigint NOT NULL,
+   roster text NOT NULL,
+   move_policy varchar(255) NOT NULL,
+   engine varchar(255) NOT NULL,
+   engine_version int NOT NULL,
+   start_time timestamp NOT NULL,
+   end_time timestamp NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS battle_participants(
+   participant_id INTEGER PRIMARY KEY AUTOINCREMENT,
+   battle_id int NOT NULL REFERENCES battles(battle_id) ON DELETE CASCADE,
+   species_id int NOT NULL REFERENCES species(species_id),
+   placement int NOT NULL,
+   score int NOT NULL,
+   annulled boolean NOT NULL DEFAULT false,
+   UNIQUE (battle_id, species_id)
+);
+
+CREATE INDEX IF NOT EXISTS battle_participants_species_id ON battle_participants(species_id);
+
+CREATE TABLE IF NOT EXISTS battle_events(
+   event_id INTEGER PRIMARY KEY AUTOINCREMENT,
+   battle_id int NOT NULL,
+   turn int NOT NULL,
+   actor varchar(255) NOT NULL,
+   target varchar(255) NOT NULL,
+   move varchar(255) NOT NULL,
+   damage int NOT NULL,
+   remaining_hp int NOT NULL,
+   eliminated boolean NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS tournament(
+   tournament_id INTEGER PRIMARY KEY AUTOINCREMENT,
+   name varchar(255) NOT NULL,
+   size int NOT NULL,
+   seed bigint NOT NULL,
+   round int NOT NULL,
+   winner varchar(255) NOT NULL,
+   created_at timestamp NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS tournament_match(
+   match_id INTEGER PRIMARY KEY AUTOINCREMENT,
+   tournament_id int NOT NULL,
+   round int NOT NULL,
+   slot int NOT NULL,
+   pokemon_a varchar(255) NOT NULL,
+   pokemon_b varchar(255) NOT NULL,
+   winner varchar(255) NOT NULL,
+   battle_id int NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS league(
+   league_id INTEGER PRIMARY KEY AUTOINCREMENT,
+   name varchar(255) NOT NULL,
+   seed bigint NOT NULL,
+   round int NOT NULL,
+   rounds int NOT NULL,
+   points_win int NOT NULL,
+   points_draw int NOT NULL,
+   points_loss int NOT NULL,
+   tie_breakers text NOT NULL,
+   created_at timestamp NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS league_fixture(
+   fixture_id INTEGER PRIMARY KEY AUTOINCREMENT,
+   league_id int NOT NULL,
+   round int NOT NULL,
+   pokemon_a varchar(255) NOT NULL,
+   pokemon_b varchar(255) NOT NULL,
+   played boolean NOT NULL,
+   winner varchar(255) NOT NULL,
+   draw boolean NOT NULL,
+   battle_id int NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS rating(
+   name varchar(255) PRIMARY KEY,
+   rating double precision NOT NULL,
+   rd double precision NOT NULL,
+   volatility double precision NOT NULL,
+   games int NOT NULL,
+   updated_at timestamp NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS rating_history(
+   history_id INTEGER PRIMARY KEY AUTOINCREMENT,
+   battle_id int NOT NULL,
+   name varchar(255) NOT NULL,
+   rating_before double precision NOT NULL,
+   rating_after double precision NOT NULL,
+   rd_before double precision NOT NULL,
+   rd_after double precision NOT NULL,
+   created_at timestamp NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS pokeapi_cache(
+   url text PRIMARY KEY,
+   etag varchar(255) NOT NULL,
+   body blob NOT NULL,
+   fetched_at timestamp NOT NULL
+);
@@ -0,0 +1,18 @@
+-- annulling a pokemon used to leave every placement as it was, the pokemon
+-- still ranked are numbered again from 1 and the annulled ones leave the
+-- ranking with placement 0
+
+UPDATE battle_participants
+SET placement = ranked.placement
+FROM (
+   SELECT
+      participant_id,
+      ROW_NUMBER() OVER (PARTITION BY battle_id ORDER BY placement) AS placement
+   FROM battle_participants
+   WHERE annulled = false
+) ranked
+WHERE battle_participants.participant_id = ranked.participant_id;
+
+UPDATE battle_participants
+SET placement = 0
+WHERE annulled = true;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS tournament_match_round_slot;
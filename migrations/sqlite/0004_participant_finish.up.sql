@@ -0,0 +1,30 @@
+-- placement follows the annulments, the order the engine finished the
+-- battle in is kept in its own column for the replays. The battles played
+-- before get it back from the scores: an annulled pokemon keeps the score
+-- it finished with, so the order is exact for battles with at most one
+-- annulment
+
+ALTER TABLE battle_participants ADD COLUMN finish int NOT NULL DEFAULT 0;
+
+UPDATE battle_participants
+SET finish = ranked.finish
+FROM (
+   SELECT
+      bp.participant_id,
+      ROW_NUMBER() OVER (
+         PARTITION BY bp.battle_id
+         ORDER BY
+            CASE
+               WHEN bp.annulled THEN 2 * (n.participants + 1 - bp.score) - 1
+               ELSE 2 * bp.placement
+            END,
+            bp.participant_id
+      ) AS finish
+   FROM battle_participants bp
+   JOIN (
+      SELECT battle_id, COUNT(*) AS participants
+      FROM battle_participants
+      GROUP BY battle_id
+   ) n ON n.battle_id = bp.battle_id
+) ranked
+WHERE battle_participants.participant_id = ranked.participant_id;
@@ -0,0 +1,82 @@
+-- the tables are built again without the references, unplayed matches and
+-- fixtures get battle_id 0 back
+
+CREATE TABLE battle_events_new(
+   event_id INTEGER PRIMARY KEY AUTOINCREMENT,
+   battle_id int NOT NULL,
+   turn int NOT NULL,
+   actor varchar(255) NOT NULL,
+   target varchar(255) NOT NULL,
+   move varchar(255) NOT NULL,
+   damage int NOT NULL,
+   remaining_hp int NOT NULL,
+   eliminated boolean NOT NULL
+);
+
+INSERT INTO battle_events_new(event_id, battle_id, turn, actor, target, move, damage, remaining_hp, eliminated)
+SELECT event_id, battle_id, turn, actor, target, move, damage, remaining_hp, eliminated
+FROM battle_events;
+
+DROP TABLE battle_events;
+ALTER TABLE battle_events_new RENAME TO battle_events;
+
+CREATE TABLE rating_history_new(
+   history_id INTEGER PRIMARY KEY AUTOINCREMENT,
+   battle_id int NOT NULL,
+   name varchar(255) NOT NULL,
+   rating_before double precision NOT NULL,
+   rating_after double precision NOT NULL,
+   rd_before double precision NOT NULL,
+   rd_after double precision NOT NULL,
+   created_at timestamp NOT NULL
+);
+
+INSERT INTO rating_history_new(history_id, battle_id, name, rating_before, rating_after, rd_before, rd_after, created_at)
+SELECT history_id, battle_id, name, rating_before, rating_after, rd_before, rd_after, created_at
+FROM rating_history;
+
+DROP TABLE rating_history;
+ALTER TABLE rating_history_new RENAME TO rating_history;
+
+CREATE TABLE tournament_match_new(
+   match_id INTEGER PRIMARY KEY AUTOINCREMENT,
+   tournament_id int NOT NULL,
+   round int NOT NULL,
+   slot int NOT NULL,
+   pokemon_a varchar(255) NOT NULL,
+   pokemon_b varchar(255) NOT NULL,
+   winner varchar(255) NOT NULL,
+   battle_id int NOT NULL
+);
+
+INSERT INTO tournament_match_new(match_id, tournament_id, round, slot, pokemon_a, pokemon_b, winner, battle_id)
+SELECT
+   match_id, tournament_id, round, slot, pokemon_a, pokemon_b, winner,
+   COALESCE(battle_id, 0)
+FROM tournament_match;
+
+DROP TABLE tournament_match;
+ALTER TABLE tournament_match_new RENAME TO tournament_match;
+
+CREATE UNIQUE INDEX IF NOT EXISTS tournament_match_round_slot ON tournament_match(tournament_id, round, slot);
+
+CREATE TABLE league_fixture_new(
+   fixture_id INTEGER PRIMARY KEY AUTOINCREMENT,
+   league_id int NOT NULL,
+   round int NOT NULL,
+   pokemon_a varchar(255) NOT NULL,
+   pokemon_b varchar(255) NOT NULL,
+   played boolean NOT NULL,
+   winner varchar(255) NOT NULL,
+   draw boolean NOT NULL,
+   battle_id int NOT NULL
+);
+
+INSERT INTO league_fixture_new(fixture_id, league_id, round, pokemon_a, pokemon_b, played, winner, draw, battle_id)
+SELECT
+   fixture_id, league_id, round, pokemon_a, pokemon_b, played, winner, draw,
+   COALESCE(battle_id, 0)
+FROM league_fixture;
+
+DROP TABLE league_fixture;
+ALTER TABLE league_fixture_new RENAME TO league_fixture;
@@ -0,0 +1,93 @@
+-- the event log, the rating history, the tournament matches and the league
+-- fixtures kept the battle_id of their battle without referencing it. The
+-- log and the history go with their battle, a match or a fixture keeps its
+-- result and loses the battle. Unplayed matches and fixtures had battle_id
+-- 0, they get NULL. SQLite can't add a reference to a table, every table is
+-- built again.
+
+CREATE TABLE battle_events_new(
+   event_id INTEGER PRIMARY KEY AUTOINCREMENT,
+   battle_id int NOT NULL REFERENCES battles(battle_id) ON DELETE CASCADE,
+   turn int NOT NULL,
+   actor varchar(255) NOT NULL,
+   target varchar(255) NOT NULL,
+   move varchar(255) NOT NULL,
+   damage int NOT NULL,
+   remaining_hp int NOT NULL,
+   eliminated boolean NOT NULL
+);
+
+INSERT INTO battle_events_new(event_id, battle_id, turn, actor, target, move, damage, remaining_hp, eliminated)
+SELECT event_id, battle_id, turn, actor, target, move, damage, remaining_hp, eliminated
+FROM battle_events
+WHERE battle_id IN (SELECT battle_id FROM battles);
+
+DROP TABLE battle_events;
+ALTER TABLE battle_events_new RENAME TO battle_events;
+
+CREATE TABLE rating_history_new(
+   history_id INTEGER PRIMARY KEY AUTOINCREMENT,
+   battle_id int NOT NULL REFERENCES battles(battle_id) ON DELETE CASCADE,
+   name varchar(255) NOT NULL,
+   rating_before double precision NOT NULL,
+   rating_after double precision NOT NULL,
+   rd_before double precision NOT NULL,
+   rd_after double precision NOT NULL,
+   created_at timestamp NOT NULL
+);
+
+INSERT INTO rating_history_new(history_id, battle_id, name, rating_before, rating_after, rd_before, rd_after, created_at)
+SELECT history_id, battle_id, name, rating_before, rating_after, rd_before, rd_after, created_at
+FROM rating_history
+WHERE battle_id IN (SELECT battle_id FROM battles);
+
+DROP TABLE rating_history;
+ALTER TABLE rating_history_new RENAME TO rating_history;
+
+CREATE TABLE tournament_match_new(
+   match_id INTEGER PRIMARY KEY AUTOINCREMENT,
+   tournament_id int NOT NULL,
+   round int NOT NULL,
+   slot int NOT NULL,
+   pokemon_a varchar(255) NOT NULL,
+   pokemon_b varchar(255) NOT NULL,
+   winner varchar(255) NOT NULL,
+   battle_id int REFERENCES battles(battle_id) ON DELETE SET NULL
+);
+
+INSERT INTO tournament_match_new(match_id, tournament_id, round, slot, pokemon_a, pokemon_b, winner, battle_id)
+SELECT
+   match_id, tournament_id, round, slot, pokemon_a, pokemon_b, winner,
+   CASE WHEN battle_id IN (SELECT battle_id FROM battles) THEN battle_id END
+FROM tournament_match;
+
+DROP TABLE tournament_match;
+ALTER TABLE tournament_match_new RENAME TO tournament_match;
+
+CREATE UNIQUE INDEX IF NOT EXISTS tournament_match_round_slot ON tournament_match(tournament_id, round, slot);
+
+CREATE TABLE league_fixture_new(
+   fixture_id INTEGER PRIMARY KEY AUTOINCREMENT,
+   league_id int NOT NULL,
+   round int NOT NULL,
+   pokemon_a varchar(255) NOT NULL,
+   pokemon_b varchar(255) NOT NULL,
+   played boolean NOT NULL,
+   winner varchar(255) NOT NULL,
+   draw boolean NOT NULL,
+   battle_id int REFERENCES battles(battle_id) ON DELETE SET NULL
+);
+
+INSERT INTO league_fixture_new(fixture_id, league_id, round, pokemon_a, pokemon_b, played, winner, draw, battle_id)
+SELECT
+   fixture_id, league_id, round, pokemon_a, pokemon_b, played, winner, draw,
+   CASE WHEN battle_id IN (SELECT battle_id FROM battles) THEN battle_id END
+FROM league_fixture;
+
+DROP TABLE league_fixture;
+ALTER TABLE league_fixture_new RENAME TO league_fixture;
+
+CREATE INDEX IF NOT EXISTS battle_events_battle_id ON battle_events(battle_id);
+CREATE INDEX IF NOT EXISTS rating_history_battle_id ON rating_history(battle_id);
+CREATE INDEX IF NOT EXISTS tournament_match_battle_id ON tournament_match(battle_id);
+CREATE INDEX IF NOT EXISTS league_fixture_battle_id ON league_fixture(battle_id);
@@ -3,29 +3,79 @@ package models
 import "time"
 
 type Battle struct {
-	BattleID  int       `json:"battle_id"`
-	Winner    string    `json:"winner"`
-	StartTime time.Time `json:"start_time"`
-	EndTime   time.Time `json:"end_time"`
+	BattleID   int      `json:"battle_id"`
+	Winner     string   `json:"winner"`
+	Seed       int64    `json:"seed"`
+	Roster     []string `json:"roster"`
+	MovePolicy string   `json:"move_policy"`
+	// Engine and EngineVersion tell which rules decided the battle
+	Engine        string    `json:"engine"`
+	EngineVersion int       `json:"engine_version"`
+	StartTime     time.Time `json:"start_time"`
+	EndTime       time.Time `json:"end_time"`
 }
 
 type RequestBattle struct {
-	Pokemons int `json:"pokemons"`
+	Pokemons   int      `json:"pokemons"`
+	Roster     []string `json:"roster"`
+	Seed       *int64   `json:"seed"`
+	MovePolicy string   `json:"move_policy"`
+	Engine     string   `json:"engine"`
+	// Filter narrows the random draw, it's ignored with a roster
+	Filter RosterFilter `json:"filter"`
 }
 
 type BattleInput struct {
-	Winner    string    `json:"winner"`
-	StartTime time.Time `json:"start_time"`
-	EndTime   time.Time `json:"end_time"`
+	Winner     string   `json:"winner"`
+	Seed       int64    `json:"seed"`
+	Roster     []string `json:"roster"`
+	MovePolicy string   `json:"move_policy"`
+	// Engine and EngineVersion tell which rules decided the battle
+	Engine        string    `json:"engine"`
+	EngineVersion int       `json:"engine_version"`
+	StartTime     time.Time `json:"start_time"`
+	EndTime       time.Time `json:"end_time"`
 }
 
 type BattleResponse struct {
-	BattleID int             `json:"battle_id"`
-	Winner   string          `json:"winner"`
-	Player   []DetailPlayers `json:"player"`
+	BattleID      int             `json:"battle_id"`
+	Winner        string          `json:"winner"`
+	Seed          int64           `json:"seed"`
+	Engine        string          `json:"engine"`
+	EngineVersion int             `json:"engine_version"`
+	Player        []DetailPlayers `json:"player"`
+}
+
+type ReplayResponse struct {
+	BattleID      int      `json:"battle_id"`
+	Seed          int64    `json:"seed"`
+	Engine        string   `json:"engine"`
+	EngineVersion int      `json:"engine_version"`
+	Matches       bool     `json:"matches"`
+	Recorded      []string `json:"recorded"`
+	Replayed      []string `json:"replayed"`
 }
 
 type DetailPlayers struct {
 	Name   string `json:"name"`
 	Scores int    `json:"scores"`
+	// Placement is the rank left by the annulments, 0 once the pokemon is
+	// annulled. Where the engine finished it is kept apart for the replays.
+	Placement int  `json:"placement,omitempty"`
+	Annulled  bool `json:"annulled,omitempty"`
+}
+
+type RequestAnnul struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type BattleEvent struct {
+	BattleID    int    `json:"battle_id"`
+	Turn        int    `json:"turn"`
+	Actor       string `json:"actor"`
+	Target      string `json:"target"`
+	Move        string `json:"move"`
+	Damage      int    `json:"damage"`
+	RemainingHP int    `json:"remaining_hp"`
+	Eliminated  bool   `json:"eliminated"`
 }
@@ -0,0 +1,9 @@
+package models
+
+type CacheStats struct {
+	MemoryHits    int64 `json:"memory_hits"`
+	StoreHits     int64 `json:"store_hits"`
+	Misses        int64 `json:"misses"`
+	Revalidations int64 `json:"revalidations"`
+	MemoryEntries int   `json:"memory_entries"`
+}
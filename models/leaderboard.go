@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// RequestLeaderboard is the query string of GET /scores, every parameter is
+// optional
+type RequestLeaderboard struct {
+	// Window is all_time, daily, weekly, monthly or custom. A daily, weekly
+	// or monthly window is the one holding From, today without it.
+	Window string `form:"window"`
+	// From and To are the first and the last day of a custom window, To is
+	// today when it's missing
+	From   string `form:"from"`
+	To     string `form:"to"`
+	Limit  int    `form:"limit"`
+	Cursor string `form:"cursor"`
+}
+
+// ScoreRange bounds the start_time of the battles a leaderboard counts, zero
+// fields don't bound it
+type ScoreRange struct {
+	From time.Time
+	// Before is exclusive
+	Before time.Time
+}
+
+type LeaderboardEntry struct {
+	// Rank is shared by the pokemon with the same scores, the next rank
+	// skips as many places
+	Rank   int    `json:"rank"`
+	Name   string `json:"name"`
+	Scores int    `json:"scores"`
+	// PreviousRank is the rank in the window before, Movement compares the
+	// two: up, down, same or new
+	PreviousRank int    `json:"previous_rank,omitempty"`
+	Movement     string `json:"movement,omitempty"`
+}
+
+type Leaderboard struct {
+	Window string `json:"window"`
+	// From and To are the first and the last day counted, empty for all_time
+	From    string             `json:"from,omitempty"`
+	To      string             `json:"to,omitempty"`
+	Entries []LeaderboardEntry `json:"entries"`
+	// NextCursor fetches the following page, empty on the last one
+	NextCursor string `json:"next_cursor"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+type League struct {
+	LeagueID    int       `json:"league_id"`
+	Name        string    `json:"name"`
+	Seed        int64     `json:"seed"`
+	Round       int       `json:"round"`
+	Rounds      int       `json:"rounds"`
+	PointsWin   int       `json:"points_win"`
+	PointsDraw  int       `json:"points_draw"`
+	PointsLoss  int       `json:"points_loss"`
+	TieBreakers []string  `json:"tie_breakers"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type RequestLeague struct {
+	Name        string   `json:"name"`
+	Roster      []string `json:"roster"`
+	Seed        *int64   `json:"seed"`
+	PointsWin   *int     `json:"points_win"`
+	PointsDraw  *int     `json:"points_draw"`
+	PointsLoss  *int     `json:"points_loss"`
+	TieBreakers []string `json:"tie_breakers"`
+}
+
+type LeagueFixture struct {
+	FixtureID int    `json:"fixture_id"`
+	LeagueID  int    `json:"league_id"`
+	Round     int    `json:"round"`
+	PokemonA  string `json:"pokemon_a"`
+	PokemonB  string `json:"pokemon_b"`
+	Played    bool   `json:"played"`
+	Winner    string `json:"winner"`
+	Draw      bool   `json:"draw"`
+	BattleID  int    `json:"battle_id"`
+}
+
+type Standing struct {
+	Rank   int    `json:"rank"`
+	Name   string `json:"name"`
+	Played int    `json:"played"`
+	Wins   int    `json:"wins"`
+	Losses int    `json:"losses"`
+	Draws  int    `json:"draws"`
+	Points int    `json:"points"`
+}
+
+type LeagueResponse struct {
+	League
+	Standings []Standing      `json:"standings"`
+	Fixtures  []LeagueFixture `json:"fixtures"`
+}
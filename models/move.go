@@ -0,0 +1,26 @@
+package models
+
+type Moves struct {
+	Move NamedMove `json:"move"`
+}
+
+type NamedMove struct {
+	Name string `json:"name"`
+	Url  string `json:"url"`
+}
+
+type Move struct {
+	Name string `json:"name"`
+	// Power is zero for status moves, PokeAPI sends null for them
+	Power int `json:"power"`
+	// Accuracy is zero for moves that never miss
+	Accuracy    int         `json:"accuracy"`
+	PP          int         `json:"pp"`
+	DamageClass DamageClass `json:"damage_class"`
+	Type        Type        `json:"type"`
+}
+
+type DamageClass struct {
+	Name string `json:"name"`
+	Url  string `json:"url"`
+}
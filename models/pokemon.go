@@ -1,15 +1,24 @@
 package models
 
-type Pokemon struct {
-	PokemonID int    `json:"pokemon_id"`
-	Name      string `json:"name"`
-	BattleID  int    `json:"battle_id"`
-	Scores    int    `json:"scores"`
+// Participant is a pokemon taking part in a battle, Name is its species
+type Participant struct {
+	ParticipantID int    `json:"participant_id"`
+	Name          string `json:"name"`
+	BattleID      int    `json:"battle_id"`
+	Placement     int    `json:"placement"`
+	Scores        int    `json:"scores"`
+	Annulled      bool   `json:"annulled"`
 }
 
 type GetPokemon struct {
+	// ID is the national dex number
+	ID    int     `json:"id"`
 	Name  string  `json:"name"`
 	Stats []Stats `json:"stats"`
+	Types []Types `json:"types"`
+	Moves []Moves `json:"moves"`
+	// Species tells which species the pokemon, or the form of it, belongs to
+	Species NamedSpecies `json:"species"`
 }
 
 type Stats struct {
@@ -23,6 +32,16 @@ type Stat struct {
 	Url  string `json:"url"`
 }
 
+type Types struct {
+	Slot int  `json:"slot"`
+	Type Type `json:"type"`
+}
+
+type Type struct {
+	Name string `json:"name"`
+	Url  string `json:"url"`
+}
+
 type AllPokemon struct {
 	Count    int    `json:"count"`
 	Next     string `json:"next"`
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+type Rating struct {
+	Name       string    `json:"name"`
+	Rating     float64   `json:"rating"`
+	RD         float64   `json:"rating_deviation"`
+	Volatility float64   `json:"volatility"`
+	Games      int       `json:"games"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type RatingHistory struct {
+	BattleID     int       `json:"battle_id"`
+	Name         string    `json:"name"`
+	RatingBefore float64   `json:"rating_before"`
+	RatingAfter  float64   `json:"rating_after"`
+	RDBefore     float64   `json:"rd_before"`
+	RDAfter      float64   `json:"rd_after"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// RequestBattleSearch is the query string of GET /battles, every filter is
+// optional
+type RequestBattleSearch struct {
+	// StartTime and EndTime bound the day the battle started, both inclusive
+	StartTime       string `form:"start_time"`
+	EndTime         string `form:"end_time"`
+	Winner          string `form:"winner"`
+	Participant     string `form:"participant"`
+	MinParticipants int    `form:"min_participants"`
+	Engine          string `form:"engine"`
+	// Order is desc, newest battles first, or asc
+	Order  string `form:"order"`
+	Limit  int    `form:"limit"`
+	Cursor string `form:"cursor"`
+}
+
+// BattleSearch is a validated RequestBattleSearch, zero fields don't filter
+type BattleSearch struct {
+	StartFrom time.Time
+	// StartBefore is exclusive
+	StartBefore     time.Time
+	Winner          string
+	Participant     string
+	MinParticipants int
+	Engine          string
+	Ascending       bool
+	// AfterID resumes the search after the last battle of the previous page
+	AfterID int
+	Limit   int
+}
+
+type BattlePage struct {
+	Battles []BattleResponse `json:"battles"`
+	// NextCursor fetches the following page, empty on the last one
+	NextCursor string `json:"next_cursor"`
+}
@@ -0,0 +1,34 @@
+package models
+
+type NamedSpecies struct {
+	Name string `json:"name"`
+	Url  string `json:"url"`
+}
+
+// Species is the part of the PokeAPI pokemon-species document the roster
+// filters need
+type Species struct {
+	Name        string     `json:"name"`
+	IsLegendary bool       `json:"is_legendary"`
+	IsMythical  bool       `json:"is_mythical"`
+	Generation  Generation `json:"generation"`
+}
+
+type Generation struct {
+	// Name is the roman numbered generation, like generation-iv
+	Name string `json:"name"`
+	Url  string `json:"url"`
+}
+
+// RosterFilter narrows the pokemon a random roster is drawn from, the zero
+// value lets every pokemon in
+type RosterFilter struct {
+	// Generation is the generation number the pokemon was introduced in
+	Generation       int    `json:"generation"`
+	Type             string `json:"type"`
+	ExcludeLegendary bool   `json:"exclude_legendary"`
+	ExcludeMythical  bool   `json:"exclude_mythical"`
+	// MinBST and MaxBST bound the base stat total, 0 leaves it open
+	MinBST int `json:"min_bst"`
+	MaxBST int `json:"max_bst"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+type Tournament struct {
+	TournamentID int       `json:"tournament_id"`
+	Name         string    `json:"name"`
+	Size         int       `json:"size"`
+	Seed         int64     `json:"seed"`
+	Round        int       `json:"round"`
+	Winner       string    `json:"winner"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type RequestTournament struct {
+	Name   string   `json:"name"`
+	Size   int      `json:"size"`
+	Roster []string `json:"roster"`
+	Seed   *int64   `json:"seed"`
+}
+
+type TournamentMatch struct {
+	MatchID      int    `json:"match_id"`
+	TournamentID int    `json:"tournament_id"`
+	Round        int    `json:"round"`
+	Slot         int    `json:"slot"`
+	PokemonA     string `json:"pokemon_a"`
+	PokemonB     string `json:"pokemon_b"`
+	Winner       string `json:"winner"`
+	BattleID     int    `json:"battle_id"`
+}
+
+// BracketNode is a match of the bracket, Children are the two matches whose
+// winners meet in it
+type BracketNode struct {
+	TournamentMatch
+	Children []*BracketNode `json:"children,omitempty"`
+}
+
+type TournamentResponse struct {
+	Tournament
+	Bracket *BracketNode `json:"bracket"`
+}
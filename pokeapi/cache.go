@@ -0,0 +1,160 @@
+package pokeapi
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"pokemon/models"
+)
+
+// Entry is a document downloaded from PokeAPI
+type Entry struct {
+	URL       string
+	ETag      string
+	Body      []byte
+	FetchedAt time.Time
+}
+
+// Store keeps entries somewhere that outlives the process
+type Store interface {
+	GetEntry(ctx context.Context, url string) (res Entry, found bool, err error)
+	PutEntry(ctx context.Context, entry Entry) error
+}
+
+// Cache serves documents from an in-process LRU first and from the Store
+// behind it second. Entries older than the TTL are stale: they aren't served
+// but their ETag lets the client revalidate them. The cache is best effort, a
+// Store that fails is treated like an empty one.
+type Cache struct {
+	ttl    time.Duration
+	memory *lru
+	store  Store
+	now    func() time.Time
+
+	memoryHits    int64
+	storeHits     int64
+	misses        int64
+	revalidations int64
+}
+
+// NewCache keeps up to size documents in memory for ttl, store may be nil
+func NewCache(size int, ttl time.Duration, store Store) *Cache {
+	return &Cache{
+		ttl:    ttl,
+		memory: newLRU(size),
+		store:  store,
+		now:    time.Now,
+	}
+}
+
+func (c *Cache) Stats() models.CacheStats {
+	return models.CacheStats{
+		MemoryHits:    atomic.LoadInt64(&c.memoryHits),
+		StoreHits:     atomic.LoadInt64(&c.storeHits),
+		Misses:        atomic.LoadInt64(&c.misses),
+		Revalidations: atomic.LoadInt64(&c.revalidations),
+		MemoryEntries: c.memory.len(),
+	}
+}
+
+// lookup finds the entry of url and tells whether it can be served as is,
+// every lookup counts as a hit or a miss
+func (c *Cache) lookup(ctx context.Context, url string) (res Entry, found, fresh bool) {
+	if res, found = c.memory.get(url); found && c.fresh(res) {
+		atomic.AddInt64(&c.memoryHits, 1)
+		return res, true, true
+	}
+
+	if c.store != nil {
+		stored, ok, err := c.store.GetEntry(ctx, url)
+		if err == nil && ok && (!found || stored.FetchedAt.After(res.FetchedAt)) {
+			res, found = stored, true
+			if c.fresh(res) {
+				c.memory.put(res)
+				atomic.AddInt64(&c.storeHits, 1)
+				return res, true, true
+			}
+		}
+	}
+
+	atomic.AddInt64(&c.misses, 1)
+	return res, found, false
+}
+
+// save stamps the entry as fetched now and keeps it in both tiers,
+// revalidated tells that PokeAPI only confirmed an entry the cache already had
+func (c *Cache) save(ctx context.Context, entry Entry, revalidated bool) {
+	entry.FetchedAt = c.now()
+	if revalidated {
+		atomic.AddInt64(&c.revalidations, 1)
+	}
+
+	c.memory.put(entry)
+	if c.store != nil {
+		c.store.PutEntry(ctx, entry)
+	}
+}
+
+func (c *Cache) fresh(entry Entry) bool {
+	return c.now().Sub(entry.FetchedAt) < c.ttl
+}
+
+// lru is a fixed size map that forgets the least recently used entry first
+type lru struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+func newLRU(size int) *lru {
+	return &lru{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (l *lru) get(url string) (res Entry, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	el, ok := l.entries[url]
+	if !ok {
+		return res, false
+	}
+	l.order.MoveToFront(el)
+	return el.Value.(Entry), true
+}
+
+func (l *lru) put(entry Entry) {
+	if l.size <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if el, ok := l.entries[entry.URL]; ok {
+		el.Value = entry
+		l.order.MoveToFront(el)
+		return
+	}
+
+	l.entries[entry.URL] = l.order.PushFront(entry)
+	if l.order.Len() > l.size {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(Entry).URL)
+	}
+}
+
+func (l *lru) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.order.Len()
+}
@@ -0,0 +1,209 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"pokemon/models"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStore struct {
+	mu      sync.Mutex
+	entries map[string]Entry
+	err     error
+}
+
+func (s *memoryStore) GetEntry(ctx context.Context, url string) (res Entry, found bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return res, false, s.err
+	}
+	res, found = s.entries[url]
+	return res, found, nil
+}
+
+func (s *memoryStore) PutEntry(ctx context.Context, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+	s.entries[entry.URL] = entry
+	return nil
+}
+
+func Test_LRU(t *testing.T) {
+	l := newLRU(2)
+	l.put(Entry{URL: "a"})
+	l.put(Entry{URL: "b"})
+
+	// reading a makes b the least recently used
+	_, ok := l.get("a")
+	assert.True(t, ok)
+
+	l.put(Entry{URL: "c"})
+
+	_, ok = l.get("b")
+	assert.False(t, ok)
+	_, ok = l.get("a")
+	assert.True(t, ok)
+	_, ok = l.get("c")
+	assert.True(t, ok)
+	assert.Equal(t, 2, l.len())
+}
+
+func Test_Cache_Lookup(t *testing.T) {
+	type testCase struct {
+		name          string
+		memory        []Entry
+		stored        []Entry
+		storeErr      error
+		expectedFound bool
+		expectedFresh bool
+		expectedETag  string
+		expectedStats models.CacheStats
+	}
+
+	var (
+		testTable []testCase
+		now       = time.Date(2022, 10, 12, 10, 0, 0, 0, time.UTC)
+		fresh     = now.Add(-time.Minute)
+		stale     = now.Add(-2 * time.Hour)
+	)
+
+	testTable = append(testTable, testCase{
+		name:          "miss",
+		expectedStats: models.CacheStats{Misses: 1},
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "memory hit",
+		memory:        []Entry{{URL: "u", ETag: "m", FetchedAt: fresh}},
+		expectedFound: true,
+		expectedFresh: true,
+		expectedETag:  "m",
+		expectedStats: models.CacheStats{MemoryHits: 1, MemoryEntries: 1},
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "store hit is kept in memory",
+		stored:        []Entry{{URL: "u", ETag: "s", FetchedAt: fresh}},
+		expectedFound: true,
+		expectedFresh: true,
+		expectedETag:  "s",
+		expectedStats: models.CacheStats{StoreHits: 1, MemoryEntries: 1},
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "stale memory entry beaten by a fresh stored one",
+		memory:        []Entry{{URL: "u", ETag: "m", FetchedAt: stale}},
+		stored:        []Entry{{URL: "u", ETag: "s", FetchedAt: fresh}},
+		expectedFound: true,
+		expectedFresh: true,
+		expectedETag:  "s",
+		expectedStats: models.CacheStats{StoreHits: 1, MemoryEntries: 1},
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "stale entry is found for revalidation",
+		stored:        []Entry{{URL: "u", ETag: "s", FetchedAt: stale}},
+		expectedFound: true,
+		expectedFresh: false,
+		expectedETag:  "s",
+		expectedStats: models.CacheStats{Misses: 1},
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "failing store is a miss",
+		storeErr:      errors.New("unexpected error"),
+		expectedStats: models.CacheStats{Misses: 1},
+	})
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			store := &memoryStore{entries: make(map[string]Entry), err: testCase.storeErr}
+			for _, entry := range testCase.stored {
+				store.entries[entry.URL] = entry
+			}
+
+			cache := NewCache(10, time.Hour, store)
+			cache.now = func() time.Time { return now }
+			for _, entry := range testCase.memory {
+				cache.memory.put(entry)
+			}
+
+			res, found, isFresh := cache.lookup(context.Background(), "u")
+
+			assert.Equal(t, testCase.expectedFound, found)
+			assert.Equal(t, testCase.expectedFresh, isFresh)
+			assert.Equal(t, testCase.expectedETag, res.ETag)
+			assert.Equal(t, testCase.expectedStats, cache.Stats())
+		})
+	}
+}
+
+func Test_Client_Cache(t *testing.T) {
+	var (
+		calls   int32
+		matched int32
+		body    = `{"name":"pikachu","stats":[],"types":[]}`
+		now     = time.Now()
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&matched, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	store := &memoryStore{entries: make(map[string]Entry)}
+	cache := NewCache(10, time.Hour, store)
+	cache.now = func() time.Time { return now }
+
+	client := NewClient(Config{BaseURL: srv.URL, Cache: cache})
+
+	for i := 0; i < 3; i++ {
+		res, err := client.GetPokemon(context.Background(), "pikachu")
+		require.NoError(t, err)
+		assert.Equal(t, "pikachu", res.Name)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Equal(t, models.CacheStats{MemoryHits: 2, Misses: 1, MemoryEntries: 1}, client.CacheStats())
+
+	stored, found, err := store.GetEntry(context.Background(), srv.URL+"/api/v2/pokemon/pikachu")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, `"v1"`, stored.ETag)
+	assert.Equal(t, body, string(stored.Body))
+
+	// once the entry goes stale it's revalidated instead of downloaded again
+	cache.now = func() time.Time { return now.Add(2 * time.Hour) }
+
+	res, err := client.GetPokemon(context.Background(), "pikachu")
+	require.NoError(t, err)
+	assert.Equal(t, "pikachu", res.Name)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&matched))
+	assert.Equal(t, int64(1), client.CacheStats().Revalidations)
+
+	_, err = client.GetPokemon(context.Background(), "pikachu")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,283 @@
+// Package pokeapi is a small client for the endpoints of https://pokeapi.co
+// the battles need
+package pokeapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"pokemon/models"
+)
+
+const (
+	DefaultBaseURL    = "https://pokeapi.co"
+	DefaultTimeout    = 10 * time.Second
+	DefaultMaxRetries = 3
+	DefaultBackoff    = 200 * time.Millisecond
+	DefaultCacheSize  = 1024
+	DefaultCacheTTL   = 24 * time.Hour
+
+	// noRetryAfter tells get to fall back to its own backoff
+	noRetryAfter time.Duration = -1
+)
+
+type Config struct {
+	// BaseURL is the scheme and host of the API, without the /api/v2 prefix
+	BaseURL string
+	// Timeout bounds every single request, retries get a fresh one
+	Timeout time.Duration
+	// MaxRetries is how many times a request is sent again after a 5xx, a 429
+	// or a network error
+	MaxRetries int
+	// Backoff is the wait before the first retry, it doubles on every retry
+	Backoff time.Duration
+	// CacheSize and CacheTTL size the cache NewCache builds for the client
+	CacheSize int
+	CacheTTL  time.Duration
+	// Cache keeps the documents the client downloaded, nil disables it
+	Cache *Cache
+}
+
+// ConfigFromEnv reads POKEAPI_BASE_URL, POKEAPI_TIMEOUT, POKEAPI_MAX_RETRIES,
+// POKEAPI_BACKOFF, POKEAPI_CACHE_SIZE and POKEAPI_CACHE_TTL, the variables
+// that aren't set keep their default
+func ConfigFromEnv() Config {
+	cfg := Config{
+		BaseURL:    DefaultBaseURL,
+		Timeout:    DefaultTimeout,
+		MaxRetries: DefaultMaxRetries,
+		Backoff:    DefaultBackoff,
+		CacheSize:  DefaultCacheSize,
+		CacheTTL:   DefaultCacheTTL,
+	}
+
+	if v := os.Getenv("POKEAPI_BASE_URL"); v != "" {
+		cfg.BaseURL = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("POKEAPI_TIMEOUT")); err == nil {
+		cfg.Timeout = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("POKEAPI_MAX_RETRIES")); err == nil {
+		cfg.MaxRetries = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("POKEAPI_BACKOFF")); err == nil {
+		cfg.Backoff = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("POKEAPI_CACHE_SIZE")); err == nil {
+		cfg.CacheSize = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("POKEAPI_CACHE_TTL")); err == nil {
+		cfg.CacheTTL = v
+	}
+	return cfg
+}
+
+type Client struct {
+	cfg  Config
+	http *http.Client
+}
+
+func NewClient(cfg Config) *Client {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultBaseURL
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+
+	return &Client{
+		cfg:  cfg,
+		http: &http.Client{},
+	}
+}
+
+// BaseURL is where the client sends its requests
+func (c *Client) BaseURL() string {
+	return c.cfg.BaseURL
+}
+
+// CacheStats counts how the cache served the documents, all zero without one
+func (c *Client) CacheStats() models.CacheStats {
+	if c.cfg.Cache == nil {
+		return models.CacheStats{}
+	}
+	return c.cfg.Cache.Stats()
+}
+
+func (c *Client) GetAllPokemons(ctx context.Context) (res models.AllPokemon, err error) {
+	err = c.get(ctx, "/api/v2/pokemon?limit=100000&offset=0", &res)
+	return res, err
+}
+
+func (c *Client) GetPokemon(ctx context.Context, name string) (res models.GetPokemon, err error) {
+	err = c.get(ctx, "/api/v2/pokemon/"+url.PathEscape(name), &res)
+	return res, err
+}
+
+func (c *Client) GetSpecies(ctx context.Context, name string) (res models.Species, err error) {
+	err = c.get(ctx, "/api/v2/pokemon-species/"+url.PathEscape(name), &res)
+	return res, err
+}
+
+func (c *Client) GetMove(ctx context.Context, name string) (res models.Move, err error) {
+	err = c.get(ctx, "/api/v2/move/"+url.PathEscape(name), &res)
+	return res, err
+}
+
+// get decodes the JSON document at path into out. A fresh copy in the cache
+// saves the request, a stale one is revalidated with its ETag.
+func (c *Client) get(ctx context.Context, path string, out interface{}) (err error) {
+	var (
+		endpoint = c.cfg.BaseURL + path
+		cached   Entry
+		found    bool
+		fresh    bool
+	)
+
+	if c.cfg.Cache != nil {
+		cached, found, fresh = c.cfg.Cache.lookup(ctx, endpoint)
+		if fresh {
+			return decode(endpoint, cached.Body, out)
+		}
+	}
+
+	etag := ""
+	if found {
+		etag = cached.ETag
+	}
+
+	res, err := c.fetch(ctx, endpoint, etag)
+	if err != nil {
+		return err
+	}
+
+	if c.cfg.Cache != nil {
+		entry := Entry{URL: endpoint, ETag: res.etag, Body: res.body}
+		if res.notModified {
+			entry.Body = cached.Body
+			if entry.ETag == "" {
+				entry.ETag = cached.ETag
+			}
+		}
+		c.cfg.Cache.save(ctx, entry, res.notModified)
+		res.body = entry.Body
+	}
+
+	return decode(endpoint, res.body, out)
+}
+
+func decode(endpoint string, body []byte, out interface{}) error {
+	if err := json.Unmarshal(body, out); err != nil {
+		return &UpstreamError{URL: endpoint, StatusCode: http.StatusOK, Err: err}
+	}
+	return nil
+}
+
+type fetched struct {
+	body []byte
+	etag string
+	// notModified is set when the ETag sent along still matches
+	notModified bool
+}
+
+// fetch downloads endpoint, retrying the failures that may go away with an
+// exponential backoff
+func (c *Client) fetch(ctx context.Context, endpoint, etag string) (res fetched, err error) {
+	for attempt := 0; ; attempt++ {
+		var (
+			retry bool
+			wait  time.Duration
+		)
+
+		res, retry, wait, err = c.do(ctx, endpoint, etag)
+		if err == nil || !retry || attempt >= c.cfg.MaxRetries {
+			return res, err
+		}
+
+		if wait == noRetryAfter {
+			wait = c.cfg.Backoff << attempt
+			// jitter keeps concurrent callers from retrying in lockstep
+			if wait > 0 {
+				wait += time.Duration(rand.Int63n(int64(wait)/4 + 1))
+			}
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return res, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// do sends one request. It tells whether the failure is worth a retry and,
+// for a 429 with a Retry-After header, how long to wait before it.
+func (c *Client) do(ctx context.Context, endpoint, etag string) (res fetched, retry bool, wait time.Duration, err error) {
+	reqCtx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return res, false, noRetryAfter, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	response, err := c.http.Do(req)
+	if err != nil {
+		// the caller gave up, there is nobody left to retry for
+		if ctx.Err() != nil {
+			return res, false, noRetryAfter, ctx.Err()
+		}
+		return res, true, noRetryAfter, &UpstreamError{URL: endpoint, Err: err}
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK:
+		res.etag = response.Header.Get("ETag")
+		res.body, err = io.ReadAll(response.Body)
+		if err != nil {
+			return res, true, noRetryAfter, &UpstreamError{URL: endpoint, StatusCode: response.StatusCode, Err: err}
+		}
+		return res, false, noRetryAfter, nil
+	case http.StatusNotModified:
+		res.etag = response.Header.Get("ETag")
+		res.notModified = true
+		return res, false, noRetryAfter, nil
+	}
+
+	// drain the error page so the connection can be reused
+	io.Copy(io.Discard, response.Body)
+
+	switch {
+	case response.StatusCode == http.StatusNotFound:
+		return res, false, noRetryAfter, &NotFoundError{URL: endpoint}
+	case response.StatusCode == http.StatusTooManyRequests:
+		wait = noRetryAfter
+		if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			wait = time.Duration(seconds) * time.Second
+		}
+		return res, true, wait, &UpstreamError{URL: endpoint, StatusCode: response.StatusCode}
+	case response.StatusCode >= http.StatusInternalServerError:
+		return res, true, noRetryAfter, &UpstreamError{URL: endpoint, StatusCode: response.StatusCode}
+	default:
+		return res, false, noRetryAfter, &UpstreamError{URL: endpoint, StatusCode: response.StatusCode}
+	}
+}
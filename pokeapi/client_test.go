@@ -0,0 +1,225 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"pokemon/models"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_GetPokemon(t *testing.T) {
+	type testCase struct {
+		name             string
+		handler          func(calls int32, w http.ResponseWriter, r *http.Request)
+		wantError        bool
+		expectedNotFound bool
+		expectedStatus   int
+		expectedCalls    int32
+		expectedResult   models.GetPokemon
+	}
+
+	var testTable []testCase
+
+	pikachu := func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"pikachu","stats":[{"base_stat":35,"effort":0,"stat":{"name":"hp","url":""}}],"types":[{"slot":1,"type":{"name":"electric","url":""}}]}`))
+	}
+
+	testTable = append(testTable, testCase{
+		name: "success",
+		handler: func(calls int32, w http.ResponseWriter, r *http.Request) {
+			pikachu(w)
+		},
+		wantError:     false,
+		expectedCalls: 1,
+		expectedResult: models.GetPokemon{
+			Name:  "pikachu",
+			Stats: []models.Stats{{BaseStat: 35, Stat: models.Stat{Name: "hp"}}},
+			Types: []models.Types{{Slot: 1, Type: models.Type{Name: "electric"}}},
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name: "failed not found is not retried",
+		handler: func(calls int32, w http.ResponseWriter, r *http.Request) {
+			http.NotFound(w, r)
+		},
+		wantError:        true,
+		expectedNotFound: true,
+		expectedStatus:   0,
+		expectedCalls:    1,
+	})
+
+	testTable = append(testTable, testCase{
+		name: "success after server errors",
+		handler: func(calls int32, w http.ResponseWriter, r *http.Request) {
+			if calls < 3 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			pikachu(w)
+		},
+		wantError:     false,
+		expectedCalls: 3,
+		expectedResult: models.GetPokemon{
+			Name:  "pikachu",
+			Stats: []models.Stats{{BaseStat: 35, Stat: models.Stat{Name: "hp"}}},
+			Types: []models.Types{{Slot: 1, Type: models.Type{Name: "electric"}}},
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name: "failed rate limited until retries run out",
+		handler: func(calls int32, w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTooManyRequests)
+		},
+		wantError:      true,
+		expectedStatus: http.StatusTooManyRequests,
+		expectedCalls:  3,
+	})
+
+	testTable = append(testTable, testCase{
+		name: "failed bad request is not retried",
+		handler: func(calls int32, w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		},
+		wantError:      true,
+		expectedStatus: http.StatusBadRequest,
+		expectedCalls:  1,
+	})
+
+	testTable = append(testTable, testCase{
+		name: "failed broken document",
+		handler: func(calls int32, w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"name":`))
+		},
+		wantError:      true,
+		expectedStatus: http.StatusOK,
+		expectedCalls:  1,
+	})
+
+	testTable = append(testTable, testCase{
+		name: "failed slow upstream times out on every attempt",
+		handler: func(calls int32, w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		},
+		wantError:     true,
+		expectedCalls: 3,
+	})
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			var calls int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/api/v2/pokemon/pikachu", r.URL.Path)
+				testCase.handler(atomic.AddInt32(&calls, 1), w, r)
+			}))
+			defer srv.Close()
+
+			client := NewClient(Config{
+				BaseURL:    srv.URL,
+				Timeout:    50 * time.Millisecond,
+				MaxRetries: 2,
+				Backoff:    time.Millisecond,
+			})
+
+			res, err := client.GetPokemon(context.Background(), "pikachu")
+
+			assert.Equal(t, testCase.expectedCalls, atomic.LoadInt32(&calls))
+			if !testCase.wantError {
+				assert.Nil(t, err)
+				assert.Equal(t, testCase.expectedResult, res)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Equal(t, testCase.expectedNotFound, errors.Is(err, ErrNotFound))
+
+			var upstream *UpstreamError
+			if !testCase.expectedNotFound {
+				require.True(t, errors.As(err, &upstream))
+				assert.Equal(t, testCase.expectedStatus, upstream.StatusCode)
+			}
+		})
+	}
+}
+
+func Test_Client_ContextCanceled(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	client := NewClient(Config{
+		BaseURL:    srv.URL,
+		MaxRetries: 5,
+		Backoff:    time.Hour,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := client.GetAllPokemons(ctx)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func Test_Client_RetryAfter(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"name":"thunderbolt","power":90,"accuracy":100,"pp":15,"damage_class":{"name":"special"},"type":{"name":"electric"}}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(Config{
+		BaseURL:    srv.URL,
+		MaxRetries: 1,
+		// the header asks for no wait at all
+		Backoff: time.Hour,
+	})
+
+	res, err := client.GetMove(context.Background(), "thunderbolt")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 90, res.Power)
+	assert.Equal(t, "special", res.DamageClass.Name)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func Test_ConfigFromEnv(t *testing.T) {
+	t.Setenv("POKEAPI_BASE_URL", "http://localhost:8081")
+	t.Setenv("POKEAPI_TIMEOUT", "2s")
+	t.Setenv("POKEAPI_MAX_RETRIES", "")
+	t.Setenv("POKEAPI_BACKOFF", "nonsense")
+	t.Setenv("POKEAPI_CACHE_SIZE", "64")
+	t.Setenv("POKEAPI_CACHE_TTL", "1h")
+
+	cfg := ConfigFromEnv()
+
+	assert.Equal(t, Config{
+		BaseURL:    "http://localhost:8081",
+		Timeout:    2 * time.Second,
+		MaxRetries: DefaultMaxRetries,
+		Backoff:    DefaultBackoff,
+		CacheSize:  64,
+		CacheTTL:   time.Hour,
+	}, cfg)
+}
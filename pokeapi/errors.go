@@ -0,0 +1,47 @@
+package pokeapi
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotFound matches every NotFoundError with errors.Is
+var ErrNotFound = errors.New("pokeapi: resource not found")
+
+// NotFoundError is returned when PokeAPI answers 404, e.g. for a pokemon or
+// a move it doesn't know
+type NotFoundError struct {
+	URL string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("pokeapi: %s not found", e.URL)
+}
+
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// UpstreamError is returned when PokeAPI can't be reached or answers with
+// anything but a document or a 404
+type UpstreamError struct {
+	URL string
+	// StatusCode is zero when no response came back
+	StatusCode int
+	Err        error
+}
+
+func (e *UpstreamError) Error() string {
+	switch {
+	case e.StatusCode != 0 && e.Err != nil:
+		return fmt.Sprintf("pokeapi: %s responded with %d: %v", e.URL, e.StatusCode, e.Err)
+	case e.StatusCode != 0:
+		return fmt.Sprintf("pokeapi: %s responded with %d", e.URL, e.StatusCode)
+	default:
+		return fmt.Sprintf("pokeapi: %s: %v", e.URL, e.Err)
+	}
+}
+
+func (e *UpstreamError) Unwrap() error {
+	return e.Err
+}
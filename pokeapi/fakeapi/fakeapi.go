@@ -0,0 +1,311 @@
+// Package fakeapi is a stand-in for PokeAPI serving fixture data, for tests
+// (through httptest.NewServer) and for local development (cmd/fake-pokeapi).
+// Besides answering normally it can fail every request, rate limit them or
+// answer slowly, to exercise the client's retries and timeouts.
+package fakeapi
+
+import (
+	"crypto/sha1"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+//go:embed fixtures
+var fixtures embed.FS
+
+const defaultLimit = 20
+
+type Mode string
+
+const (
+	// ModeNormal serves the fixtures
+	ModeNormal Mode = "normal"
+	// ModeError answers every request with a 500
+	ModeError Mode = "error"
+	// ModeRateLimit answers every request with a 429 and a Retry-After
+	ModeRateLimit Mode = "rate_limit"
+	// ModeSlow waits for the delay before serving the fixtures
+	ModeSlow Mode = "slow"
+)
+
+var ErrInvalidMode = errors.New("invalid mode")
+
+func ParseMode(s string) (Mode, error) {
+	switch mode := Mode(s); mode {
+	case ModeNormal, ModeError, ModeRateLimit, ModeSlow:
+		return mode, nil
+	}
+	return "", fmt.Errorf("%w %q", ErrInvalidMode, s)
+}
+
+type document struct {
+	body []byte
+	etag string
+}
+
+type Server struct {
+	mu       sync.Mutex
+	mode     Mode
+	delay    time.Duration
+	failures int
+
+	requests int64
+
+	// pokemon is keyed by name and by id, names keeps them in dex order
+	pokemon map[string]document
+	species map[string]document
+	moves   map[string]document
+	names   []string
+}
+
+// New serves the fixtures bundled with the package
+func New() *Server {
+	fixtureFS, err := fs.Sub(fixtures, "fixtures")
+	if err != nil {
+		panic(err)
+	}
+
+	s, err := NewFromFS(fixtureFS)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// NewFromFS serves the fixtures of fsys, a pokemon/, a pokemon-species/ and a
+// move/ directory holding one PokeAPI document each
+func NewFromFS(fsys fs.FS) (*Server, error) {
+	s := &Server{
+		mode:    ModeNormal,
+		pokemon: map[string]document{},
+		species: map[string]document{},
+		moves:   map[string]document{},
+	}
+
+	type species struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	var dex []species
+
+	err := load(fsys, "pokemon", func(body []byte) error {
+		var poke species
+		if err := json.Unmarshal(body, &poke); err != nil {
+			return err
+		}
+
+		doc := newDocument(body)
+		s.pokemon[poke.Name] = doc
+		s.pokemon[strconv.Itoa(poke.ID)] = doc
+		dex = append(dex, poke)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for dir, docs := range map[string]map[string]document{"pokemon-species": s.species, "move": s.moves} {
+		docs := docs
+		err = load(fsys, dir, func(body []byte) error {
+			var named species
+			if err := json.Unmarshal(body, &named); err != nil {
+				return err
+			}
+
+			doc := newDocument(body)
+			docs[named.Name] = doc
+			docs[strconv.Itoa(named.ID)] = doc
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(dex, func(i, j int) bool { return dex[i].ID < dex[j].ID })
+	for _, poke := range dex {
+		s.names = append(s.names, poke.Name)
+	}
+
+	return s, nil
+}
+
+func load(fsys fs.FS, dir string, add func(body []byte) error) error {
+	files, err := fs.Glob(fsys, dir+"/*.json")
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		body, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		if err := add(body); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+func newDocument(body []byte) document {
+	sum := sha1.Sum(body)
+	return document{body: body, etag: `"` + hex.EncodeToString(sum[:]) + `"`}
+}
+
+// SetMode switches how the following requests are answered, delay is only
+// used by ModeSlow
+func (s *Server) SetMode(mode Mode, delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.mode = mode
+	s.delay = delay
+}
+
+// FailNext answers the next n requests with a 503 whatever the mode
+func (s *Server) FailNext(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures = n
+}
+
+// Requests counts the requests received so far
+func (s *Server) Requests() int {
+	return int(atomic.LoadInt64(&s.requests))
+}
+
+// Pokemon lists the names of the fixtures in dex order
+func (s *Server) Pokemon() []string {
+	return append([]string(nil), s.names...)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt64(&s.requests, 1)
+
+	s.mu.Lock()
+	mode, delay, fail := s.mode, s.delay, s.failures > 0
+	if fail {
+		s.failures--
+	}
+	s.mu.Unlock()
+
+	switch {
+	case fail:
+		http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+		return
+	case mode == ModeError:
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	case mode == ModeRateLimit:
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+		return
+	case mode == ModeSlow:
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-r.Context().Done():
+			return
+		case <-timer.C:
+		}
+	}
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	dir, name := path.Split(strings.TrimSuffix(r.URL.Path, "/"))
+	switch {
+	case r.URL.Path == "/api/v2/pokemon" || r.URL.Path == "/api/v2/pokemon/":
+		s.list(w, r)
+	case dir == "/api/v2/pokemon/":
+		s.serve(w, r, s.pokemon, name)
+	case dir == "/api/v2/pokemon-species/":
+		s.serve(w, r, s.species, name)
+	case dir == "/api/v2/move/":
+		s.serve(w, r, s.moves, name)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request, docs map[string]document, name string) {
+	doc, ok := docs[strings.ToLower(name)]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("ETag", doc.etag)
+	if r.Header.Get("If-None-Match") == doc.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Write(doc.body)
+}
+
+// list pages through the pokemon like PokeAPI does with limit and offset
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = defaultLimit
+	}
+	offset, err := strconv.Atoi(r.URL.Query().Get("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	type named struct {
+		Name string `json:"name"`
+		Url  string `json:"url"`
+	}
+	var (
+		base = "http://" + r.Host + "/api/v2/pokemon/"
+		page = struct {
+			Count    int     `json:"count"`
+			Next     *string `json:"next"`
+			Previous *string `json:"previous"`
+			Results  []named `json:"results"`
+		}{Count: len(s.names), Results: []named{}}
+	)
+
+	for i := offset; i < offset+limit && i < len(s.names); i++ {
+		page.Results = append(page.Results, named{
+			Name: s.names[i],
+			Url:  base + s.names[i] + "/",
+		})
+	}
+
+	if offset+limit < len(s.names) {
+		next := fmt.Sprintf("%s?offset=%d&limit=%d", base, offset+limit, limit)
+		page.Next = &next
+	}
+	if offset > 0 {
+		start := offset - limit
+		if start < 0 {
+			start = 0
+		}
+		previous := fmt.Sprintf("%s?offset=%d&limit=%d", base, start, limit)
+		page.Previous = &previous
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(page)
+}
@@ -0,0 +1,93 @@
+package fakeapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_List(t *testing.T) {
+	srv := httptest.NewServer(New())
+	defer srv.Close()
+
+	type page struct {
+		Count    int     `json:"count"`
+		Next     *string `json:"next"`
+		Previous *string `json:"previous"`
+		Results  []struct {
+			Name string `json:"name"`
+		} `json:"results"`
+	}
+
+	response, err := http.Get(srv.URL + "/api/v2/pokemon?limit=3&offset=6")
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	var res page
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&res))
+
+	assert.Equal(t, 10, res.Count)
+	require.NotNil(t, res.Next)
+	assert.Equal(t, srv.URL+"/api/v2/pokemon/?offset=9&limit=3", *res.Next)
+	require.NotNil(t, res.Previous)
+	assert.Equal(t, srv.URL+"/api/v2/pokemon/?offset=3&limit=3", *res.Previous)
+	require.Len(t, res.Results, 3)
+	assert.Equal(t, "snorlax", res.Results[0].Name)
+	assert.Equal(t, "mewtwo", res.Results[1].Name)
+	assert.Equal(t, "mew", res.Results[2].Name)
+}
+
+func Test_Serve(t *testing.T) {
+	fake := New()
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	get := func(path, etag string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		require.NoError(t, err)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+
+		response, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		response.Body.Close()
+		return response
+	}
+
+	byName := get("/api/v2/pokemon/pikachu", "")
+	assert.Equal(t, http.StatusOK, byName.StatusCode)
+	assert.NotEmpty(t, byName.Header.Get("ETag"))
+
+	byID := get("/api/v2/pokemon/25/", "")
+	assert.Equal(t, byName.Header.Get("ETag"), byID.Header.Get("ETag"))
+
+	assert.Equal(t, http.StatusNotModified, get("/api/v2/pokemon/pikachu", byName.Header.Get("ETag")).StatusCode)
+	assert.Equal(t, http.StatusNotFound, get("/api/v2/pokemon/missingno", "").StatusCode)
+	assert.Equal(t, http.StatusOK, get("/api/v2/move/thunderbolt", "").StatusCode)
+	assert.Equal(t, http.StatusOK, get("/api/v2/pokemon-species/lucario", "").StatusCode)
+
+	fake.FailNext(1)
+	assert.Equal(t, http.StatusServiceUnavailable, get("/api/v2/pokemon/pikachu", "").StatusCode)
+	assert.Equal(t, http.StatusOK, get("/api/v2/pokemon/pikachu", "").StatusCode)
+
+	fake.SetMode(ModeRateLimit, 0)
+	rateLimited := get("/api/v2/pokemon/pikachu", "")
+	assert.Equal(t, http.StatusTooManyRequests, rateLimited.StatusCode)
+	assert.Equal(t, "1", rateLimited.Header.Get("Retry-After"))
+
+	assert.Equal(t, 9, fake.Requests())
+}
+
+func Test_ParseMode(t *testing.T) {
+	mode, err := ParseMode("slow")
+	assert.Nil(t, err)
+	assert.Equal(t, ModeSlow, mode)
+
+	_, err = ParseMode("broken")
+	assert.ErrorIs(t, err, ErrInvalidMode)
+}
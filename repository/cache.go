@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"pokemon/models"
+	"pokemon/pokeapi"
+	"pokemon/repository/query"
+)
+
+// PokeAPICacheStore keeps the PokeAPI documents in the pokeapi_cache table so
+// they survive a restart
+type PokeAPICacheStore struct {
+	db *sql.DB
+}
+
+func NewPokeAPICacheStore(db *sql.DB) *PokeAPICacheStore {
+	return &PokeAPICacheStore{db: db}
+}
+
+func (s *PokeAPICacheStore) GetEntry(ctx context.Context, url string) (res pokeapi.Entry, found bool, err error) {
+	err = s.db.QueryRowContext(
+		ctx,
+		query.GetPokeAPICache,
+		url,
+	).Scan(
+		&res.URL,
+		&res.ETag,
+		&res.Body,
+		&res.FetchedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return res, false, nil
+	}
+	if err != nil {
+		return res, false, err
+	}
+	return res, true, nil
+}
+
+func (s *PokeAPICacheStore) PutEntry(ctx context.Context, entry pokeapi.Entry) error {
+	_, err := s.db.ExecContext(
+		ctx,
+		query.UpsertPokeAPICache,
+		entry.URL,
+		entry.ETag,
+		entry.Body,
+		entry.FetchedAt,
+	)
+
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetCacheStats is all zero when the pokedex doesn't go through PokeAPI
+func (p *PokeRepo) GetCacheStats() (res models.CacheStats, err error) {
+	if cached, ok := p.pokedex.(interface{ CacheStats() models.CacheStats }); ok {
+		return cached.CacheStats(), nil
+	}
+	return res, nil
+}
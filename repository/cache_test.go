@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"pokemon/pokeapi"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Get_PokeAPICacheEntry(t *testing.T) {
+	type testCase struct {
+		name           string
+		wantError      bool
+		mockQuery      func(mock sqlmock.Sqlmock)
+		expectedError  error
+		expectedFound  bool
+		expectedResult pokeapi.Entry
+	}
+
+	var (
+		testTable     []testCase
+		now           = time.Now()
+		url           = "https://pokeapi.co/api/v2/pokemon/pikachu"
+		expectedQuery = `
+		SELECT
+			c.url,
+			c.etag,
+			c.body,
+			c.fetched_at
+		FROM pokeapi_cache c
+		WHERE c.url = $1
+	`
+	)
+
+	testTable = append(testTable, testCase{
+		name:      "failed unexpected error",
+		wantError: true,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+				WithArgs(url).
+				WillReturnError(errors.New("unexpected error"))
+		},
+		expectedError: errors.New("unexpected error"),
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "success not cached",
+		wantError: false,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+				WithArgs(url).
+				WillReturnRows(sqlmock.NewRows([]string{"url", "etag", "body", "fetched_at"}))
+		},
+		expectedFound: false,
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "success",
+		wantError: false,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+				WithArgs(url).
+				WillReturnRows(sqlmock.NewRows([]string{"url", "etag", "body", "fetched_at"}).
+					AddRow(url, `"v1"`, []byte(`{"name":"pikachu"}`), now))
+		},
+		expectedFound: true,
+		expectedResult: pokeapi.Entry{
+			URL:       url,
+			ETag:      `"v1"`,
+			Body:      []byte(`{"name":"pikachu"}`),
+			FetchedAt: now,
+		},
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+
+			if tc.mockQuery != nil {
+				tc.mockQuery(mock)
+			}
+			store := NewPokeAPICacheStore(db)
+
+			res, found, serr := store.GetEntry(context.Background(), url)
+			if tc.wantError {
+				assert.EqualError(t, serr, tc.expectedError.Error())
+			} else {
+				assert.Nil(t, serr)
+				assert.Equal(t, tc.expectedFound, found)
+				assert.Equal(t, tc.expectedResult, res)
+			}
+		})
+	}
+}
+
+func Test_Put_PokeAPICacheEntry(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	var (
+		now   = time.Now()
+		entry = pokeapi.Entry{
+			URL:       "https://pokeapi.co/api/v2/pokemon/pikachu",
+			ETag:      `"v1"`,
+			Body:      []byte(`{"name":"pikachu"}`),
+			FetchedAt: now,
+		}
+		expectedQuery = `
+		INSERT INTO
+			pokeapi_cache(
+				url,
+				etag,
+				body,
+				fetched_at
+			)
+		VALUES(
+			$1, $2, $3, $4
+		)
+		ON CONFLICT (url) DO UPDATE SET
+			etag = EXCLUDED.etag,
+			body = EXCLUDED.body,
+			fetched_at = EXCLUDED.fetched_at
+	`
+	)
+
+	mock.ExpectExec(regexp.QuoteMeta(expectedQuery)).
+		WithArgs(entry.URL, entry.ETag, entry.Body, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	serr := NewPokeAPICacheStore(db).PutEntry(context.Background(), entry)
+
+	assert.Nil(t, serr)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,530 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"net/http/httptest"
+	"os"
+	"pokemon/config/sqlite"
+	"pokemon/migrations"
+	"pokemon/models"
+	"pokemon/pokeapi"
+	"pokemon/pokeapi/fakeapi"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Every PokemonRepo has to pass the conformance suite. Postgres only joins in
+// when TEST_POSTGRES_DSN points at a database the suite may wipe.
+
+func Test_MemoryRepo_Conformance(t *testing.T) {
+	testConformance(t, func(t *testing.T, pokedex Pokedex) PokemonRepo {
+		return NewMemoryRepo(pokedex)
+	})
+}
+
+func Test_SQLiteRepo_Conformance(t *testing.T) {
+	testConformance(t, func(t *testing.T, pokedex Pokedex) PokemonRepo {
+		db, err := sqlite.Open(":memory:")
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+
+		return NewSQLiteRepo(db, pokedex)
+	})
+}
+
+func Test_PokeRepo_Conformance(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN isn't set")
+	}
+
+	testConformance(t, func(t *testing.T, pokedex Pokedex) PokemonRepo {
+		db, err := sql.Open("postgres", dsn)
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+
+		migrator, err := migrations.New(db)
+		require.NoError(t, err)
+		_, err = migrator.Up()
+		require.NoError(t, err)
+
+		_, err = db.Exec(`
+			TRUNCATE species, battles, battle_participants, battle_events,
+				tournament, tournament_match, league, league_fixture,
+				rating, rating_history
+			RESTART IDENTITY CASCADE
+		`)
+		require.NoError(t, err)
+
+		return NewPokeRepo(db, pokedex)
+	})
+}
+
+func testConformance(t *testing.T, newRepo func(t *testing.T, pokedex Pokedex) PokemonRepo) {
+	var (
+		start = time.Date(2022, 10, 12, 8, 0, 0, 0, time.UTC)
+		setup = func(t *testing.T) PokemonRepo {
+			srv := httptest.NewServer(fakeapi.New())
+			t.Cleanup(srv.Close)
+
+			return newRepo(t, NewPokeAPIPokedex(pokeapi.NewClient(pokeapi.Config{BaseURL: srv.URL})))
+		}
+		// saveBattle stores a battle whose participants finish in the given
+		// order, started day days after start
+		saveBattle = func(t *testing.T, repo PokemonRepo, day int, engine string, placements ...string) int {
+			var participants []models.Participant
+			for i, name := range placements {
+				participants = append(participants, models.Participant{
+					Name:      name,
+					Placement: i + 1,
+					Scores:    len(placements) - i,
+				})
+			}
+
+			id, err := repo.SaveBattle(models.BattleInput{
+				Winner:        placements[0],
+				Seed:          int64(day),
+				Roster:        placements,
+				MovePolicy:    "random",
+				Engine:        engine,
+				EngineVersion: 1,
+				StartTime:     start.AddDate(0, 0, day),
+				EndTime:       start.AddDate(0, 0, day).Add(time.Minute),
+			}, participants, nil, nil)
+			require.NoError(t, err)
+			return int(id)
+		}
+		ids = func(battles []models.BattleResponse) (res []int) {
+			for _, battle := range battles {
+				res = append(res, battle.BattleID)
+			}
+			return res
+		}
+	)
+
+	t.Run("pokedex", func(t *testing.T) {
+		repo := setup(t)
+
+		poke, err := repo.GetPokemonByName(context.Background(), "pikachu")
+		require.NoError(t, err)
+		assert.Equal(t, "pikachu", poke.Name)
+
+		_, err = repo.GetPokemonByName(context.Background(), "agumon")
+		assert.ErrorIs(t, err, ErrPokemonNotFound)
+	})
+
+	t.Run("save battle", func(t *testing.T) {
+		repo := setup(t)
+		events := []models.BattleEvent{
+			{Turn: 1, Actor: "pikachu", Target: "eevee", Move: "thunderbolt", Damage: 40, RemainingHP: 15},
+			{Turn: 2, Actor: "pikachu", Target: "eevee", Move: "thunderbolt", Damage: 40, RemainingHP: 0, Eliminated: true},
+		}
+
+		id, err := repo.SaveBattle(models.BattleInput{
+			Winner:        "pikachu",
+			Seed:          42,
+			Roster:        []string{"eevee", "pikachu"},
+			MovePolicy:    "best",
+			Engine:        "moves",
+			EngineVersion: 2,
+			StartTime:     start,
+			EndTime:       start.Add(time.Minute),
+		}, []models.Participant{
+			{Name: "pikachu", Placement: 1, Scores: 2},
+			{Name: "eevee", Placement: 2, Scores: 1},
+		}, events, nil)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), id)
+
+		battle, err := repo.GetBattleByID(1)
+		require.NoError(t, err)
+		assert.Equal(t, models.Battle{
+			BattleID:      1,
+			Winner:        "pikachu",
+			Seed:          42,
+			Roster:        []string{"eevee", "pikachu"},
+			MovePolicy:    "best",
+			Engine:        "moves",
+			EngineVersion: 2,
+			StartTime:     start,
+			EndTime:       start.Add(time.Minute),
+		}, battle)
+
+		players, err := repo.GetPlayer(1)
+		require.NoError(t, err)
+		assert.Equal(t, []models.DetailPlayers{
+			{Name: "pikachu", Scores: 2, Placement: 1},
+			{Name: "eevee", Scores: 1, Placement: 2},
+		}, players)
+
+		saved, err := repo.GetBattleEvents(1)
+		require.NoError(t, err)
+		for i := range events {
+			events[i].BattleID = 1
+		}
+		assert.Equal(t, events, saved)
+
+		_, err = repo.GetBattleByID(2)
+		assert.ErrorIs(t, err, ErrBattleNotFound)
+	})
+
+	t.Run("save battle keeps nothing when it fails", func(t *testing.T) {
+		repo := setup(t)
+
+		_, err := repo.SaveBattle(models.BattleInput{Winner: "pikachu", StartTime: start, EndTime: start}, []models.Participant{
+			{Name: "pikachu", Placement: 1, Scores: 2},
+			{Name: "pikachu", Placement: 2, Scores: 1},
+		}, nil, nil)
+		assert.Error(t, err)
+
+		battles, err := repo.GetBattleWithPlayers(models.BattleSearch{})
+		require.NoError(t, err)
+		assert.Empty(t, battles)
+
+		scores, err := repo.GetPokemonScore(models.ScoreRange{})
+		require.NoError(t, err)
+		assert.Empty(t, scores)
+	})
+
+	t.Run("search battles", func(t *testing.T) {
+		repo := setup(t)
+		saveBattle(t, repo, 0, "stats", "pikachu", "eevee")
+		saveBattle(t, repo, 1, "moves", "eevee", "pikachu", "snorlax")
+		saveBattle(t, repo, 2, "stats", "pikachu", "snorlax", "gengar")
+		saveBattle(t, repo, 3, "stats", "mew", "mewtwo")
+
+		for _, tc := range []struct {
+			name     string
+			search   models.BattleSearch
+			expected []int
+		}{
+			{name: "everything newest first", expected: []int{4, 3, 2, 1}},
+			{name: "oldest first", search: models.BattleSearch{Ascending: true}, expected: []int{1, 2, 3, 4}},
+			{name: "dates", search: models.BattleSearch{StartFrom: start.AddDate(0, 0, 1), StartBefore: start.AddDate(0, 0, 3)}, expected: []int{3, 2}},
+			{name: "winner", search: models.BattleSearch{Winner: "pikachu"}, expected: []int{3, 1}},
+			{name: "participant", search: models.BattleSearch{Participant: "snorlax"}, expected: []int{3, 2}},
+			{name: "min participants", search: models.BattleSearch{MinParticipants: 3}, expected: []int{3, 2}},
+			{name: "engine", search: models.BattleSearch{Engine: "moves"}, expected: []int{2}},
+			{name: "page after", search: models.BattleSearch{AfterID: 4, Limit: 2}, expected: []int{3, 2}},
+			{name: "page after ascending", search: models.BattleSearch{Ascending: true, AfterID: 2, Limit: 1}, expected: []int{3}},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				battles, err := repo.GetBattle(tc.search)
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, ids(battles))
+			})
+		}
+
+		battles, err := repo.GetBattleWithPlayers(models.BattleSearch{Participant: "snorlax"})
+		require.NoError(t, err)
+		assert.Equal(t, []models.BattleResponse{
+			{BattleID: 3, Winner: "pikachu", Seed: 2, Engine: "stats", EngineVersion: 1, Player: []models.DetailPlayers{
+				{Name: "pikachu", Scores: 3, Placement: 1},
+				{Name: "snorlax", Scores: 2, Placement: 2},
+				{Name: "gengar", Scores: 1, Placement: 3},
+			}},
+			{BattleID: 2, Winner: "eevee", Seed: 1, Engine: "moves", EngineVersion: 1, Player: []models.DetailPlayers{
+				{Name: "eevee", Scores: 3, Placement: 1},
+				{Name: "pikachu", Scores: 2, Placement: 2},
+				{Name: "snorlax", Scores: 1, Placement: 3},
+			}},
+		}, battles)
+	})
+
+	t.Run("annul pokemon", func(t *testing.T) {
+		repo := setup(t)
+		id := saveBattle(t, repo, 0, "stats", "mewtwo", "pikachu", "eevee")
+
+		require.NoError(t, repo.AnnulPokemon(id, "mewtwo"))
+
+		battle, err := repo.GetBattleByID(id)
+		require.NoError(t, err)
+		assert.Equal(t, "pikachu", battle.Winner)
+
+		players, err := repo.GetPlayer(id)
+		require.NoError(t, err)
+		assert.Equal(t, []models.DetailPlayers{
+			{Name: "pikachu", Scores: 3, Placement: 1},
+			{Name: "eevee", Scores: 2, Placement: 2},
+			{Name: "mewtwo", Scores: 3, Placement: 0, Annulled: true},
+		}, players)
+
+		battles, err := repo.GetBattle(models.BattleSearch{Winner: "pikachu"})
+		require.NoError(t, err)
+		assert.Equal(t, []int{id}, ids(battles))
+	})
+
+	t.Run("annul the same pokemon twice", func(t *testing.T) {
+		repo := setup(t)
+		id := saveBattle(t, repo, 0, "stats", "mewtwo", "pikachu", "eevee")
+
+		require.NoError(t, repo.AnnulPokemon(id, "mewtwo"))
+		assert.ErrorIs(t, repo.AnnulPokemon(id, "mewtwo"), ErrAlreadyAnnulled)
+		assert.ErrorIs(t, repo.AnnulPokemon(id, "snorlax"), ErrPokemonNotInBattle)
+
+		battle, err := repo.GetBattleByID(id)
+		require.NoError(t, err)
+		assert.Equal(t, "pikachu", battle.Winner)
+
+		players, err := repo.GetPlayer(id)
+		require.NoError(t, err)
+		assert.Equal(t, []models.DetailPlayers{
+			{Name: "pikachu", Scores: 3, Placement: 1},
+			{Name: "eevee", Scores: 2, Placement: 2},
+			{Name: "mewtwo", Scores: 3, Placement: 0, Annulled: true},
+		}, players)
+	})
+
+	t.Run("annul two pokemons", func(t *testing.T) {
+		repo := setup(t)
+		id := saveBattle(t, repo, 0, "stats", "mewtwo", "pikachu", "eevee", "snorlax")
+
+		require.NoError(t, repo.AnnulPokemon(id, "pikachu"))
+		require.NoError(t, repo.AnnulPokemon(id, "mewtwo"))
+
+		battle, err := repo.GetBattleByID(id)
+		require.NoError(t, err)
+		assert.Equal(t, "eevee", battle.Winner)
+
+		players, err := repo.GetPlayer(id)
+		require.NoError(t, err)
+		require.Len(t, players, 4)
+		assert.Equal(t, []models.DetailPlayers{
+			{Name: "eevee", Scores: 4, Placement: 1},
+			{Name: "snorlax", Scores: 3, Placement: 2},
+		}, players[:2])
+		assert.ElementsMatch(t, []models.DetailPlayers{
+			{Name: "mewtwo", Scores: 4, Placement: 0, Annulled: true},
+			{Name: "pikachu", Scores: 3, Placement: 0, Annulled: true},
+		}, players[2:])
+
+		battles, err := repo.GetBattleWithPlayers(models.BattleSearch{})
+		require.NoError(t, err)
+		require.Len(t, battles, 1)
+		assert.Equal(t, players[:2], battles[0].Player[:2])
+	})
+
+	t.Run("finish order survives the annulments", func(t *testing.T) {
+		repo := setup(t)
+		id := saveBattle(t, repo, 0, "stats", "mewtwo", "pikachu", "eevee", "snorlax")
+
+		require.NoError(t, repo.AnnulPokemon(id, "pikachu"))
+		require.NoError(t, repo.AnnulPokemon(id, "mewtwo"))
+
+		finish, err := repo.GetFinishOrder(id)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"mewtwo", "pikachu", "eevee", "snorlax"}, finish)
+
+		finish, err = repo.GetFinishOrder(id + 1)
+		require.NoError(t, err)
+		assert.Empty(t, finish)
+	})
+
+	t.Run("pokemon score", func(t *testing.T) {
+		repo := setup(t)
+		saveBattle(t, repo, 0, "stats", "pikachu", "eevee", "snorlax")
+		id := saveBattle(t, repo, 1, "stats", "mewtwo", "pikachu", "eevee")
+		require.NoError(t, repo.AnnulPokemon(id, "mewtwo"))
+
+		scores, err := repo.GetPokemonScore(models.ScoreRange{})
+		require.NoError(t, err)
+		assert.Equal(t, []models.DetailPlayers{
+			{Name: "pikachu", Scores: 6},
+			{Name: "eevee", Scores: 4},
+			{Name: "snorlax", Scores: 1},
+		}, scores)
+
+		scores, err = repo.GetPokemonScore(models.ScoreRange{From: start.AddDate(0, 0, 1), Before: start.AddDate(0, 0, 2)})
+		require.NoError(t, err)
+		assert.Equal(t, []models.DetailPlayers{
+			{Name: "pikachu", Scores: 3},
+			{Name: "eevee", Scores: 2},
+		}, scores)
+	})
+
+	t.Run("tournament", func(t *testing.T) {
+		repo := setup(t)
+
+		id, err := repo.PostTournament(models.Tournament{Name: "cup", Size: 4, Seed: 7, Round: 1, CreatedAt: start}, []models.TournamentMatch{
+			{Round: 1, Slot: 1, PokemonA: "eevee", PokemonB: "snorlax"},
+			{Round: 1, Slot: 0, PokemonA: "mewtwo", PokemonB: "pikachu"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), id)
+		fight := func(winner, loser string) (models.BattleInput, []models.Participant) {
+			return models.BattleInput{Winner: winner, Roster: []string{winner, loser}, StartTime: start, EndTime: start}, []models.Participant{
+				{Name: winner, Placement: 1, Scores: 2},
+				{Name: loser, Placement: 2, Scores: 1},
+			}
+		}
+
+		input, participants := fight("mewtwo", "pikachu")
+		battleID, err := repo.SaveTournamentBattle(2, input, participants, nil, nil)
+		require.NoError(t, err)
+
+		// a second advance fighting the same match keeps nothing of its battle
+		_, err = repo.SaveTournamentBattle(2, input, participants, nil, nil)
+		assert.ErrorIs(t, err, ErrMatchPlayed)
+		battles, err := repo.GetBattle(models.BattleSearch{})
+		require.NoError(t, err)
+		assert.Equal(t, []int{int(battleID)}, ids(battles))
+
+		input, participants = fight("eevee", "snorlax")
+		otherID, err := repo.SaveTournamentBattle(1, input, participants, nil, nil)
+		require.NoError(t, err)
+
+		final := models.TournamentMatch{TournamentID: 1, Round: 2, Slot: 0, PokemonA: "mewtwo", PokemonB: "eevee"}
+
+		// a round that can't be drawn leaves the tournament on its round
+		assert.Error(t, repo.AdvanceTournament(1, 1, "", []models.TournamentMatch{final, final}))
+		tournament, err := repo.GetTournament(1)
+		require.NoError(t, err)
+		assert.Equal(t, 1, tournament.Round)
+		matches, err := repo.GetTournamentMatches(1)
+		require.NoError(t, err)
+		assert.Len(t, matches, 2)
+
+		require.NoError(t, repo.AdvanceTournament(1, 1, "", []models.TournamentMatch{final}))
+		assert.ErrorIs(t, repo.AdvanceTournament(1, 1, "", []models.TournamentMatch{final}), ErrTournamentAdvanced)
+
+		tournament, err = repo.GetTournament(1)
+		require.NoError(t, err)
+		assert.Equal(t, models.Tournament{TournamentID: 1, Name: "cup", Size: 4, Seed: 7, Round: 2, CreatedAt: start}, tournament)
+
+		matches, err = repo.GetTournamentMatches(1)
+		require.NoError(t, err)
+		require.Len(t, matches, 3)
+		assert.Equal(t, []models.TournamentMatch{
+			{MatchID: 2, TournamentID: 1, Round: 1, Slot: 0, PokemonA: "mewtwo", PokemonB: "pikachu", Winner: "mewtwo", BattleID: int(battleID)},
+			{MatchID: 1, TournamentID: 1, Round: 1, Slot: 1, PokemonA: "eevee", PokemonB: "snorlax", Winner: "eevee", BattleID: int(otherID)},
+		}, matches[:2])
+		// Postgres doesn't give back the ids of the rolled back inserts
+		matches[2].MatchID = 0
+		assert.Equal(t, final, matches[2])
+
+		assert.Error(t, repo.PostTournamentMatch(final))
+
+		require.NoError(t, repo.AdvanceTournament(1, 2, "mewtwo", nil))
+		tournament, err = repo.GetTournament(1)
+		require.NoError(t, err)
+		assert.Equal(t, 2, tournament.Round)
+		assert.Equal(t, "mewtwo", tournament.Winner)
+
+		_, err = repo.GetTournament(2)
+		assert.ErrorIs(t, err, ErrTournamentNotFound)
+	})
+
+	t.Run("tournament with a match drawn twice keeps nothing", func(t *testing.T) {
+		repo := setup(t)
+
+		_, err := repo.PostTournament(models.Tournament{Name: "cup", Size: 4, Seed: 7, Round: 1, CreatedAt: start}, []models.TournamentMatch{
+			{Round: 1, Slot: 0, PokemonA: "mewtwo", PokemonB: "pikachu"},
+			{Round: 1, Slot: 0, PokemonA: "eevee", PokemonB: "snorlax"},
+		})
+		assert.Error(t, err)
+
+		_, err = repo.GetTournament(1)
+		assert.ErrorIs(t, err, ErrTournamentNotFound)
+		matches, err := repo.GetTournamentMatches(1)
+		require.NoError(t, err)
+		assert.Empty(t, matches)
+	})
+
+	t.Run("league", func(t *testing.T) {
+		repo := setup(t)
+
+		id, err := repo.PostLeague(models.League{
+			Name: "weekly", Seed: 7, Round: 1, Rounds: 3, PointsWin: 3, PointsDraw: 1,
+			TieBreakers: []string{"head_to_head", "name"}, CreatedAt: start,
+		}, []models.LeagueFixture{
+			{Round: 2, PokemonA: "eevee", PokemonB: "pikachu"},
+			{Round: 1, PokemonA: "pikachu", PokemonB: "snorlax"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), id)
+
+		battle := models.BattleInput{Winner: "pikachu", Roster: []string{"pikachu", "snorlax"}, StartTime: start, EndTime: start}
+		participants := []models.Participant{
+			{Name: "pikachu", Placement: 1, Scores: 2},
+			{Name: "snorlax", Placement: 2, Scores: 1},
+		}
+		battleID, err := repo.SaveLeagueBattle(2, true, battle, participants, nil, nil)
+		require.NoError(t, err)
+		_, err = repo.SaveLeagueBattle(2, false, battle, participants, nil, nil)
+		assert.ErrorIs(t, err, ErrFixturePlayed)
+		_, err = repo.GetBattleByID(int(battleID) + 1)
+		assert.ErrorIs(t, err, ErrBattleNotFound)
+
+		require.NoError(t, repo.AdvanceLeague(1, 1))
+		assert.ErrorIs(t, repo.AdvanceLeague(1, 1), ErrLeagueAdvanced)
+
+		league, err := repo.GetLeague(1)
+		require.NoError(t, err)
+		assert.Equal(t, models.League{
+			LeagueID: 1, Name: "weekly", Seed: 7, Round: 2, Rounds: 3, PointsWin: 3, PointsDraw: 1,
+			TieBreakers: []string{"head_to_head", "name"}, CreatedAt: start,
+		}, league)
+
+		fixtures, err := repo.GetLeagueFixtures(1)
+		require.NoError(t, err)
+		assert.Equal(t, []models.LeagueFixture{
+			{FixtureID: 2, LeagueID: 1, Round: 1, PokemonA: "pikachu", PokemonB: "snorlax", Played: true, Draw: true, BattleID: int(battleID)},
+			{FixtureID: 1, LeagueID: 1, Round: 2, PokemonA: "eevee", PokemonB: "pikachu"},
+		}, fixtures)
+
+		_, err = repo.GetLeague(2)
+		assert.ErrorIs(t, err, ErrLeagueNotFound)
+	})
+
+	t.Run("ratings", func(t *testing.T) {
+		repo := setup(t)
+		var (
+			fresh   = models.Rating{Rating: 1500, RD: 350, Volatility: 0.06}
+			pikachu = models.Rating{Name: "pikachu", Rating: 1662.5, RD: 290.25, Volatility: 0.06, Games: 1, UpdatedAt: start}
+			eevee   = models.Rating{Name: "eevee", Rating: 1337.5, RD: 290.25, Volatility: 0.06, Games: 1, UpdatedAt: start}
+			seen    [][]models.Rating
+			battle  = models.BattleInput{Winner: "pikachu", StartTime: start, EndTime: start}
+		)
+		rate := func(after ...models.Rating) Rater {
+			return func(current []models.Rating) (before, res []models.Rating) {
+				seen = append(seen, current)
+				for range after {
+					before = append(before, fresh)
+				}
+				return before, after
+			}
+		}
+
+		_, err := repo.SaveBattle(battle, []models.Participant{
+			{Name: "pikachu", Placement: 1, Scores: 2},
+			{Name: "eevee", Placement: 2, Scores: 1},
+		}, nil, rate(pikachu, eevee))
+		require.NoError(t, err)
+
+		mewtwo := models.Rating{Name: "mewtwo", Rating: 1400, RD: 300, Volatility: 0.06, Games: 1, UpdatedAt: start}
+		pikachu.Rating, pikachu.Games = 1700, 2
+		_, err = repo.SaveBattle(battle, []models.Participant{
+			{Name: "pikachu", Placement: 1, Scores: 2},
+			{Name: "mewtwo", Placement: 2, Scores: 1},
+		}, nil, rate(pikachu, mewtwo))
+		require.NoError(t, err)
+
+		require.Len(t, seen, 2)
+		assert.Empty(t, seen[0])
+		assert.Equal(t, []models.Rating{{Name: "pikachu", Rating: 1662.5, RD: 290.25, Volatility: 0.06, Games: 1, UpdatedAt: start}}, seen[1])
+
+		_, err = repo.SaveBattle(battle, []models.Participant{
+			{Name: "pikachu", Placement: 1, Scores: 2},
+			{Name: "pikachu", Placement: 2, Scores: 1},
+		}, nil, rate(models.Rating{Name: "pikachu", Rating: 2000, RD: 100, Volatility: 0.06, Games: 3, UpdatedAt: start}))
+		assert.Error(t, err)
+
+		ratings, err := repo.GetRatings()
+		require.NoError(t, err)
+		assert.Equal(t, []models.Rating{pikachu, mewtwo, eevee}, ratings)
+	})
+}
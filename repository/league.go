@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"pokemon/models"
+	"pokemon/repository/query"
+	"strings"
+)
+
+var (
+	ErrLeagueNotFound = errors.New("league not found")
+	ErrLeagueAdvanced = errors.New("league already left that round")
+	ErrFixturePlayed  = errors.New("league fixture was already played")
+)
+
+// PostLeague stores the league and the fixtures of every round in one
+// transaction, the fixtures get the id of the new league
+func (p *PokeRepo) PostLeague(input models.League, fixtures []models.LeagueFixture) (Id int64, err error) {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return Id, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	err = tx.QueryRow(
+		p.rebind(query.PostLeague),
+		input.Name,
+		input.Seed,
+		input.Round,
+		input.Rounds,
+		input.PointsWin,
+		input.PointsDraw,
+		input.PointsLoss,
+		strings.Join(input.TieBreakers, ","),
+		input.CreatedAt,
+	).Scan(&Id)
+	if err != nil {
+		return Id, err
+	}
+
+	for _, fixture := range fixtures {
+		fixture.LeagueID = int(Id)
+		if err = p.postLeagueFixture(tx, fixture); err != nil {
+			return Id, err
+		}
+	}
+
+	return Id, tx.Commit()
+}
+
+// AdvanceLeague moves the league on from round to the next one. When the
+// league already left round nothing changes and ErrLeagueAdvanced is
+// returned.
+func (p *PokeRepo) AdvanceLeague(LeagueID, round int) error {
+	result, err := p.db.Exec(
+		p.rebind(query.AdvanceLeague),
+		LeagueID,
+		round,
+	)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(result, ErrLeagueAdvanced)
+}
+
+func (p *PokeRepo) GetLeague(LeagueID int) (res models.League, err error) {
+	var tieBreakers string
+
+	err = p.db.QueryRow(
+		p.rebind(query.GetLeague),
+		LeagueID,
+	).Scan(
+		&res.LeagueID,
+		&res.Name,
+		&res.Seed,
+		&res.Round,
+		&res.Rounds,
+		&res.PointsWin,
+		&res.PointsDraw,
+		&res.PointsLoss,
+		&tieBreakers,
+		&res.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return res, ErrLeagueNotFound
+	}
+	if err != nil {
+		return res, err
+	}
+
+	if tieBreakers != "" {
+		res.TieBreakers = strings.Split(tieBreakers, ",")
+	}
+	return res, nil
+}
+
+func (p *PokeRepo) PostLeagueFixture(input models.LeagueFixture) error {
+	return p.postLeagueFixture(p.db, input)
+}
+
+func (p *PokeRepo) postLeagueFixture(db execer, input models.LeagueFixture) error {
+	_, err := db.Exec(
+		p.rebind(query.PostLeagueFixture),
+		input.LeagueID,
+		input.Round,
+		input.PokemonA,
+		input.PokemonB,
+		input.Played,
+		input.Winner,
+		input.Draw,
+		input.BattleID,
+	)
+
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SaveLeagueBattle stores and rates the battle of a league fixture like
+// SaveBattle and marks the fixture played in the same transaction, the
+// fixture is won by the winner of the battle unless it's a draw. A fixture
+// that was already played keeps its result, nothing of the battle is kept
+// and ErrFixturePlayed is returned.
+func (p *PokeRepo) SaveLeagueBattle(FixtureID int, draw bool, input models.BattleInput, participants []models.Participant, events []models.BattleEvent, rate Rater) (Id int64, err error) {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return Id, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if Id, err = p.insertBattle(tx, input, participants, events, rate); err != nil {
+		return 0, err
+	}
+
+	winner := input.Winner
+	if draw {
+		winner = ""
+	}
+	result, err := tx.Exec(
+		p.rebind(query.UpdateLeagueFixture),
+		FixtureID,
+		winner,
+		draw,
+		Id,
+	)
+	if err != nil {
+		return 0, err
+	}
+	if err = expectOneRow(result, ErrFixturePlayed); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return Id, nil
+}
+
+func (p *PokeRepo) GetLeagueFixtures(LeagueID int) (res []models.LeagueFixture, err error) {
+	row, err := p.db.Query(
+		p.rebind(query.GetLeagueFixtures),
+		LeagueID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	for row.Next() {
+		temp := models.LeagueFixture{}
+		err = row.Scan(
+			&temp.FixtureID,
+			&temp.LeagueID,
+			&temp.Round,
+			&temp.PokemonA,
+			&temp.PokemonB,
+			&temp.Played,
+			&temp.Winner,
+			&temp.Draw,
+			&temp.BattleID,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, temp)
+	}
+	if err = row.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
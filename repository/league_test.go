@@ -0,0 +1,457 @@
+package repository
+
+import (
+	"errors"
+	"pokemon/models"
+	"pokemon/repository/query"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Post_League(t *testing.T) {
+	type testCase struct {
+		name          string
+		wantError     bool
+		mockQuery     func(mock sqlmock.Sqlmock)
+		expectedError error
+		expectedID    int64
+	}
+
+	var (
+		testTable     []testCase
+		expectedQuery = `
+		INSERT INTO
+			league(
+				name,
+				seed,
+				round,
+				rounds,
+				points_win,
+				points_draw,
+				points_loss,
+				tie_breakers,
+				created_at
+			)
+		VALUES(
+			$1, $2, $3, $4, $5, $6, $7, $8, $9
+		)
+		RETURNING league_id;
+	`
+		arg = models.League{
+			Name:        "weekly",
+			Seed:        42,
+			Round:       1,
+			Rounds:      3,
+			PointsWin:   3,
+			PointsDraw:  1,
+			TieBreakers: []string{"head_to_head", "name"},
+			CreatedAt:   time.Now(),
+		}
+		fixtures = []models.LeagueFixture{
+			{Round: 1, PokemonA: "mewtwo", PokemonB: "pichu"},
+			{Round: 2, PokemonA: "pichu", PokemonB: "mewtwo"},
+		}
+	)
+
+	testTable = append(testTable, testCase{
+		name:      "failed unexpected error",
+		wantError: true,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+				WillReturnError(errors.New("unexpected error"))
+			mock.ExpectRollback()
+		},
+		expectedError: errors.New("unexpected error"),
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "failed fixture rolls back the league",
+		wantError: true,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+				WithArgs("weekly", 42, 1, 3, 3, 1, 0, "head_to_head,name", sqlmock.AnyArg()).
+				WillReturnRows(sqlmock.NewRows([]string{"league_id"}).AddRow(2))
+			mock.ExpectExec(regexp.QuoteMeta(query.PostLeagueFixture)).
+				WithArgs(2, 1, "mewtwo", "pichu", false, "", false, 0).
+				WillReturnError(errors.New("unexpected error"))
+			mock.ExpectRollback()
+		},
+		expectedError: errors.New("unexpected error"),
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "success",
+		wantError: false,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+				WithArgs("weekly", 42, 1, 3, 3, 1, 0, "head_to_head,name", sqlmock.AnyArg()).
+				WillReturnRows(sqlmock.NewRows([]string{"league_id"}).AddRow(2))
+			mock.ExpectExec(regexp.QuoteMeta(query.PostLeagueFixture)).
+				WithArgs(2, 1, "mewtwo", "pichu", false, "", false, 0).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta(query.PostLeagueFixture)).
+				WithArgs(2, 2, "pichu", "mewtwo", false, "", false, 0).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+		},
+		expectedID: 2,
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+
+			if tc.mockQuery != nil {
+				tc.mockQuery(mock)
+			}
+			repo := PokeRepo{
+				db: db,
+			}
+
+			id, serr := repo.PostLeague(arg, fixtures)
+			if tc.wantError {
+				assert.EqualError(t, serr, tc.expectedError.Error())
+			} else {
+				assert.Nil(t, serr)
+				assert.Equal(t, tc.expectedID, id)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_Get_League(t *testing.T) {
+	type testCase struct {
+		name           string
+		wantError      bool
+		mockQuery      func(mock sqlmock.Sqlmock)
+		expectedError  error
+		expectedResult models.League
+	}
+
+	var (
+		testTable     []testCase
+		now           = time.Now()
+		columns       = []string{"league_id", "name", "seed", "round", "rounds", "points_win", "points_draw", "points_loss", "tie_breakers", "created_at"}
+		expectedQuery = `
+		SELECT
+			l.league_id,
+			l.name,
+			l.seed,
+			l.round,
+			l.rounds,
+			l.points_win,
+			l.points_draw,
+			l.points_loss,
+			l.tie_breakers,
+			l.created_at
+		FROM league l
+		WHERE l.league_id = $1
+	`
+	)
+
+	testTable = append(testTable, testCase{
+		name:      "failed not found",
+		wantError: true,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+				WithArgs(1).
+				WillReturnRows(sqlmock.NewRows(columns))
+		},
+		expectedError: ErrLeagueNotFound,
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "success",
+		wantError: false,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+				WithArgs(1).
+				WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "weekly", 42, 2, 3, 3, 1, 0, "wins,name", now))
+		},
+		expectedResult: models.League{
+			LeagueID:    1,
+			Name:        "weekly",
+			Seed:        42,
+			Round:       2,
+			Rounds:      3,
+			PointsWin:   3,
+			PointsDraw:  1,
+			TieBreakers: []string{"wins", "name"},
+			CreatedAt:   now,
+		},
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+
+			if tc.mockQuery != nil {
+				tc.mockQuery(mock)
+			}
+			repo := PokeRepo{
+				db: db,
+			}
+
+			res, serr := repo.GetLeague(1)
+			if tc.wantError {
+				assert.EqualError(t, serr, tc.expectedError.Error())
+			} else {
+				assert.Nil(t, serr)
+				assert.Equal(t, tc.expectedResult, res)
+			}
+		})
+	}
+}
+
+func Test_Advance_League(t *testing.T) {
+	type testCase struct {
+		name          string
+		wantError     bool
+		mockQuery     func(mock sqlmock.Sqlmock)
+		expectedError error
+	}
+
+	var (
+		testTable     []testCase
+		expectedQuery = `
+		UPDATE league
+		SET round = round + 1
+		WHERE league_id = $1 AND round = $2
+	`
+	)
+
+	testTable = append(testTable, testCase{
+		name:          "failed already advanced",
+		wantError:     true,
+		expectedError: ErrLeagueAdvanced,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectExec(regexp.QuoteMeta(expectedQuery)).
+				WithArgs(1, 2).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name: "success",
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectExec(regexp.QuoteMeta(expectedQuery)).
+				WithArgs(1, 2).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+		},
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+
+			if tc.mockQuery != nil {
+				tc.mockQuery(mock)
+			}
+			repo := PokeRepo{
+				db: db,
+			}
+
+			serr := repo.AdvanceLeague(1, 2)
+			if tc.wantError {
+				assert.EqualError(t, serr, tc.expectedError.Error())
+			} else {
+				assert.Nil(t, serr)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_Save_LeagueBattle(t *testing.T) {
+	type testCase struct {
+		name          string
+		draw          bool
+		wantError     bool
+		mockQuery     func(mock sqlmock.Sqlmock)
+		expectedError error
+		expectedID    int64
+	}
+
+	var (
+		testTable    []testCase
+		fixtureQuery = `
+		UPDATE league_fixture
+		SET played = true, winner = $2, draw = $3, battle_id = $4
+		WHERE fixture_id = $1 AND played = false
+	`
+		input = models.BattleInput{
+			Winner:    "mewtwo",
+			Roster:    []string{"mewtwo", "pichu"},
+			StartTime: time.Now(),
+			EndTime:   time.Now(),
+		}
+		participants = []models.Participant{
+			{Name: "mewtwo", Placement: 1, Scores: 2},
+			{Name: "pichu", Placement: 2, Scores: 1},
+		}
+		expectBattle = func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(query.PostBattle)).
+				WillReturnRows(sqlmock.NewRows([]string{"battle_id"}).AddRow(7))
+			mock.ExpectExec(regexp.QuoteMeta(query.PostParticipant)).
+				WithArgs("mewtwo", int64(7), 1, 2).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta(query.PostParticipant)).
+				WithArgs("pichu", int64(7), 2, 1).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+	)
+
+	testTable = append(testTable, testCase{
+		name:          "failed fixture already played rolls back the battle",
+		wantError:     true,
+		expectedError: ErrFixturePlayed,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			expectBattle(mock)
+			mock.ExpectExec(regexp.QuoteMeta(fixtureQuery)).
+				WithArgs(5, "mewtwo", false, int64(7)).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectRollback()
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:       "success",
+		expectedID: 7,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			expectBattle(mock)
+			mock.ExpectExec(regexp.QuoteMeta(fixtureQuery)).
+				WithArgs(5, "mewtwo", false, int64(7)).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:       "success draw",
+		draw:       true,
+		expectedID: 7,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			expectBattle(mock)
+			mock.ExpectExec(regexp.QuoteMeta(fixtureQuery)).
+				WithArgs(5, "", true, int64(7)).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+		},
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+
+			if tc.mockQuery != nil {
+				tc.mockQuery(mock)
+			}
+			repo := PokeRepo{
+				db: db,
+			}
+
+			id, serr := repo.SaveLeagueBattle(5, tc.draw, input, participants, nil, nil)
+			if tc.wantError {
+				assert.EqualError(t, serr, tc.expectedError.Error())
+			} else {
+				assert.Nil(t, serr)
+			}
+			assert.Equal(t, tc.expectedID, id)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_Get_LeagueFixtures(t *testing.T) {
+	type testCase struct {
+		name           string
+		wantError      bool
+		mockQuery      func(mock sqlmock.Sqlmock)
+		expectedError  error
+		expectedResult []models.LeagueFixture
+	}
+
+	var (
+		testTable     []testCase
+		columns       = []string{"fixture_id", "league_id", "round", "pokemon_a", "pokemon_b", "played", "winner", "draw", "battle_id"}
+		expectedQuery = `
+		SELECT
+			f.fixture_id,
+			f.league_id,
+			f.round,
+			f.pokemon_a,
+			f.pokemon_b,
+			f.played,
+			f.winner,
+			f.draw,
+			COALESCE(f.battle_id, 0)
+		FROM league_fixture f
+		WHERE f.league_id = $1
+		ORDER BY f.round, f.fixture_id
+	`
+	)
+
+	testTable = append(testTable, testCase{
+		name:      "failed unexpected error",
+		wantError: true,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+				WillReturnError(errors.New("unexpected error"))
+		},
+		expectedError: errors.New("unexpected error"),
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "success",
+		wantError: false,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+				WithArgs(1).
+				WillReturnRows(sqlmock.NewRows(columns).
+					AddRow(1, 1, 1, "mewtwo", "pichu", true, "mewtwo", false, 7).
+					AddRow(2, 1, 2, "pichu", "mewtwo", false, "", false, 0))
+		},
+		expectedResult: []models.LeagueFixture{
+			{FixtureID: 1, LeagueID: 1, Round: 1, PokemonA: "mewtwo", PokemonB: "pichu", Played: true, Winner: "mewtwo", BattleID: 7},
+			{FixtureID: 2, LeagueID: 1, Round: 2, PokemonA: "pichu", PokemonB: "mewtwo"},
+		},
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+
+			if tc.mockQuery != nil {
+				tc.mockQuery(mock)
+			}
+			repo := PokeRepo{
+				db: db,
+			}
+
+			res, serr := repo.GetLeagueFixtures(1)
+			if tc.wantError {
+				assert.EqualError(t, serr, tc.expectedError.Error())
+			} else {
+				assert.Nil(t, serr)
+				assert.Equal(t, tc.expectedResult, res)
+			}
+		})
+	}
+}
@@ -0,0 +1,603 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pokemon/models"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	errNoActiveParticipant = errors.New("battle has no participant left to win")
+	errDuplicateMatch      = errors.New("tournament match is drawn twice")
+)
+
+// MemoryRepo keeps everything in the process, it's gone once the process
+// exits. It answers like PokeRepo does on Postgres.
+type MemoryRepo struct {
+	pokedex Pokedex
+
+	mu          sync.RWMutex
+	battles     []memoryBattle
+	tournaments []models.Tournament
+	matches     []models.TournamentMatch
+	leagues     []models.League
+	fixtures    []models.LeagueFixture
+	ratings     map[string]models.Rating
+	history     []models.RatingHistory
+}
+
+type memoryBattle struct {
+	models.BattleInput
+	BattleID     int
+	Participants []models.Participant
+	Events       []models.BattleEvent
+	// Finish is the order the engine finished the participants in
+	Finish []string
+}
+
+func NewMemoryRepo(pokedex Pokedex) *MemoryRepo {
+	return &MemoryRepo{pokedex: pokedex, ratings: map[string]models.Rating{}}
+}
+
+func (m *MemoryRepo) GetAllPokemons(ctx context.Context) (res models.AllPokemon, err error) {
+	return m.pokedex.GetAllPokemons(ctx)
+}
+
+func (m *MemoryRepo) GetPokemonByName(ctx context.Context, name string) (res models.GetPokemon, err error) {
+	return m.pokedex.GetPokemonByName(ctx, name)
+}
+
+func (m *MemoryRepo) GetMove(ctx context.Context, name string) (res models.Move, err error) {
+	return m.pokedex.GetMove(ctx, name)
+}
+
+func (m *MemoryRepo) GetSpecies(ctx context.Context, name string) (res models.Species, err error) {
+	return m.pokedex.GetSpecies(ctx, name)
+}
+
+func (m *MemoryRepo) GetCacheStats() (res models.CacheStats, err error) {
+	if cached, ok := m.pokedex.(interface{ CacheStats() models.CacheStats }); ok {
+		return cached.CacheStats(), nil
+	}
+	return res, nil
+}
+
+// SaveBattle keeps nothing of the battle when a pokemon takes part twice, the
+// same as the unique key of battle_participants
+func (m *MemoryRepo) SaveBattle(input models.BattleInput, participants []models.Participant, events []models.BattleEvent, rate Rater) (Id int64, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.insertBattle(input, participants, events, rate)
+}
+
+func (m *MemoryRepo) insertBattle(input models.BattleInput, participants []models.Participant, events []models.BattleEvent, rate Rater) (Id int64, err error) {
+	seen := make(map[string]bool, len(participants))
+	for _, participant := range participants {
+		if seen[participant.Name] {
+			return 0, fmt.Errorf("%s takes part twice in the battle", participant.Name)
+		}
+		seen[participant.Name] = true
+	}
+
+	battle := memoryBattle{
+		BattleInput: input,
+		BattleID:    len(m.battles) + 1,
+	}
+	battle.Roster = append([]string(nil), input.Roster...)
+
+	for _, participant := range participants {
+		participant.BattleID = battle.BattleID
+		participant.Annulled = false
+		battle.Participants = append(battle.Participants, participant)
+	}
+	sort.SliceStable(battle.Participants, func(i, j int) bool {
+		return battle.Participants[i].Placement < battle.Participants[j].Placement
+	})
+	for _, participant := range battle.Participants {
+		battle.Finish = append(battle.Finish, participant.Name)
+	}
+
+	for _, event := range events {
+		event.BattleID = battle.BattleID
+		battle.Events = append(battle.Events, event)
+	}
+
+	m.battles = append(m.battles, battle)
+	if rate != nil {
+		m.rateBattle(int64(battle.BattleID), participants, rate)
+	}
+	return int64(battle.BattleID), nil
+}
+
+func (m *MemoryRepo) GetBattleEvents(BattleID int) (res []models.BattleEvent, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if battle, ok := m.battle(BattleID); ok {
+		res = append(res, battle.Events...)
+	}
+	return res, nil
+}
+
+func (m *MemoryRepo) GetBattle(search models.BattleSearch) (res []models.BattleResponse, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, battle := range m.search(search) {
+		res = append(res, battle.response())
+	}
+	return res, nil
+}
+
+func (m *MemoryRepo) GetBattleWithPlayers(search models.BattleSearch) (res []models.BattleResponse, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, battle := range m.search(search) {
+		temp := battle.response()
+		temp.Player = battle.players()
+		res = append(res, temp)
+	}
+	return res, nil
+}
+
+// search walks the battles like battleSearchQuery filters them
+func (m *MemoryRepo) search(search models.BattleSearch) (res []*memoryBattle) {
+	for i := range m.battles {
+		battle := &m.battles[len(m.battles)-1-i]
+		if search.Ascending {
+			battle = &m.battles[i]
+		}
+
+		switch {
+		case !search.StartFrom.IsZero() && battle.StartTime.Before(search.StartFrom):
+			continue
+		case !search.StartBefore.IsZero() && !battle.StartTime.Before(search.StartBefore):
+			continue
+		case search.Winner != "" && battle.Winner != search.Winner:
+			continue
+		case search.Participant != "" && !battle.hasParticipant(search.Participant):
+			continue
+		case search.MinParticipants > 0 && len(battle.Participants) < search.MinParticipants:
+			continue
+		case search.Engine != "" && battle.Engine != search.Engine:
+			continue
+		case search.AfterID > 0 && search.Ascending && battle.BattleID <= search.AfterID:
+			continue
+		case search.AfterID > 0 && !search.Ascending && battle.BattleID >= search.AfterID:
+			continue
+		}
+
+		res = append(res, battle)
+		if search.Limit > 0 && len(res) == search.Limit {
+			break
+		}
+	}
+	return res
+}
+
+func (m *MemoryRepo) GetBattleByID(BattleID int) (res models.Battle, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	battle, ok := m.battle(BattleID)
+	if !ok {
+		return res, ErrBattleNotFound
+	}
+
+	// the roster goes through a comma separated string like the roster
+	// column does
+	res = models.Battle{
+		BattleID:      battle.BattleID,
+		Winner:        battle.Winner,
+		Seed:          battle.Seed,
+		Roster:        strings.Split(strings.Join(battle.Roster, ","), ","),
+		MovePolicy:    battle.MovePolicy,
+		Engine:        battle.Engine,
+		EngineVersion: battle.EngineVersion,
+		StartTime:     battle.StartTime,
+		EndTime:       battle.EndTime,
+	}
+	return res, nil
+}
+
+func (m *MemoryRepo) GetPlayer(BattleID int) (res []models.DetailPlayers, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if battle, ok := m.battle(BattleID); ok {
+		res = battle.players()
+	}
+	return res, nil
+}
+
+func (m *MemoryRepo) GetFinishOrder(BattleID int) (res []string, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if battle, ok := m.battle(BattleID); ok {
+		res = append(res, battle.Finish...)
+	}
+	return res, nil
+}
+
+// AnnulPokemon marks the participant as annulled and puts it last with
+// placement 0, moves everyone who finished below it up one place and elects
+// the new winner
+func (m *MemoryRepo) AnnulPokemon(BattleID int, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	battle, ok := m.battle(BattleID)
+	if !ok {
+		return ErrPokemonNotInBattle
+	}
+
+	placement := -1
+	for _, participant := range battle.Participants {
+		if participant.Name != name {
+			continue
+		}
+		if participant.Annulled {
+			return ErrAlreadyAnnulled
+		}
+		placement = participant.Placement
+	}
+	if placement < 0 {
+		return ErrPokemonNotInBattle
+	}
+
+	var ranked, annulled []models.Participant
+	for _, participant := range battle.Participants {
+		switch {
+		case participant.Name == name:
+			participant.Annulled = true
+			participant.Placement = 0
+		case participant.Placement > placement && !participant.Annulled:
+			participant.Scores++
+			participant.Placement--
+		}
+
+		if participant.Annulled {
+			annulled = append(annulled, participant)
+		} else {
+			ranked = append(ranked, participant)
+		}
+	}
+	if len(ranked) == 0 {
+		return errNoActiveParticipant
+	}
+
+	battle.Participants = append(ranked, annulled...)
+	battle.Winner = ranked[0].Name
+	return nil
+}
+
+func (m *MemoryRepo) GetPokemonScore(r models.ScoreRange) (res []models.DetailPlayers, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	scores := make(map[string]int)
+	for _, battle := range m.battles {
+		if !r.From.IsZero() && battle.StartTime.Before(r.From) {
+			continue
+		}
+		if !r.Before.IsZero() && !battle.StartTime.Before(r.Before) {
+			continue
+		}
+
+		for _, participant := range battle.Participants {
+			if !participant.Annulled {
+				scores[participant.Name] += participant.Scores
+			}
+		}
+	}
+
+	for name, score := range scores {
+		res = append(res, models.DetailPlayers{Name: name, Scores: score})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Scores != res[j].Scores {
+			return res[i].Scores > res[j].Scores
+		}
+		return res[i].Name < res[j].Name
+	})
+	return res, nil
+}
+
+// battle is the battle of BattleID, ids are handed out in order from 1
+func (m *MemoryRepo) battle(BattleID int) (*memoryBattle, bool) {
+	if BattleID < 1 || BattleID > len(m.battles) {
+		return nil, false
+	}
+	return &m.battles[BattleID-1], true
+}
+
+func (b *memoryBattle) response() models.BattleResponse {
+	return models.BattleResponse{
+		BattleID:      b.BattleID,
+		Winner:        b.Winner,
+		Seed:          b.Seed,
+		Engine:        b.Engine,
+		EngineVersion: b.EngineVersion,
+	}
+}
+
+func (b *memoryBattle) players() (res []models.DetailPlayers) {
+	for _, participant := range b.Participants {
+		res = append(res, models.DetailPlayers{
+			Name:      participant.Name,
+			Scores:    participant.Scores,
+			Placement: participant.Placement,
+			Annulled:  participant.Annulled,
+		})
+	}
+	return res
+}
+
+func (b *memoryBattle) hasParticipant(name string) bool {
+	for _, participant := range b.Participants {
+		if participant.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// PostTournament checks the matches against each other before keeping
+// anything, the same as the unique key of tournament_match
+func (m *MemoryRepo) PostTournament(input models.Tournament, matches []models.TournamentMatch) (Id int64, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	input.TournamentID = len(m.tournaments) + 1
+	drawn := make([]models.TournamentMatch, 0, len(matches))
+	for _, match := range matches {
+		match.TournamentID = input.TournamentID
+		if containsMatch(drawn, match) {
+			return 0, errDuplicateMatch
+		}
+		drawn = append(drawn, match)
+	}
+
+	m.tournaments = append(m.tournaments, input)
+	for _, match := range drawn {
+		match.MatchID = len(m.matches) + 1
+		m.matches = append(m.matches, match)
+	}
+	return int64(input.TournamentID), nil
+}
+
+// AdvanceTournament checks every next match against the tournament's
+// matches before changing anything, the same as the unique key of
+// tournament_match
+func (m *MemoryRepo) AdvanceTournament(TournamentID, round int, winner string, next []models.TournamentMatch) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if TournamentID < 1 || TournamentID > len(m.tournaments) {
+		return ErrTournamentAdvanced
+	}
+	tournament := &m.tournaments[TournamentID-1]
+	if tournament.Round != round || tournament.Winner != "" {
+		return ErrTournamentAdvanced
+	}
+
+	for i, match := range next {
+		if containsMatch(m.matches, match) || containsMatch(next[:i], match) {
+			return errDuplicateMatch
+		}
+	}
+
+	for _, match := range next {
+		match.MatchID = len(m.matches) + 1
+		m.matches = append(m.matches, match)
+	}
+	if winner == "" {
+		tournament.Round = round + 1
+	}
+	tournament.Winner = winner
+	return nil
+}
+
+func (m *MemoryRepo) GetTournament(TournamentID int) (res models.Tournament, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if TournamentID < 1 || TournamentID > len(m.tournaments) {
+		return res, ErrTournamentNotFound
+	}
+	return m.tournaments[TournamentID-1], nil
+}
+
+func (m *MemoryRepo) PostTournamentMatch(input models.TournamentMatch) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if containsMatch(m.matches, input) {
+		return errDuplicateMatch
+	}
+
+	input.MatchID = len(m.matches) + 1
+	m.matches = append(m.matches, input)
+	return nil
+}
+
+// containsMatch tells whether the round and slot of match are already drawn
+func containsMatch(matches []models.TournamentMatch, match models.TournamentMatch) bool {
+	for _, other := range matches {
+		if other.TournamentID == match.TournamentID && other.Round == match.Round && other.Slot == match.Slot {
+			return true
+		}
+	}
+	return false
+}
+
+// SaveTournamentBattle keeps nothing of the battle when the match already
+// has a winner
+func (m *MemoryRepo) SaveTournamentBattle(MatchID int, input models.BattleInput, participants []models.Participant, events []models.BattleEvent, rate Rater) (Id int64, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if MatchID < 1 || MatchID > len(m.matches) || m.matches[MatchID-1].Winner != "" {
+		return 0, ErrMatchPlayed
+	}
+
+	if Id, err = m.insertBattle(input, participants, events, rate); err != nil {
+		return 0, err
+	}
+
+	m.matches[MatchID-1].Winner = input.Winner
+	m.matches[MatchID-1].BattleID = int(Id)
+	return Id, nil
+}
+
+func (m *MemoryRepo) GetTournamentMatches(TournamentID int) (res []models.TournamentMatch, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, match := range m.matches {
+		if match.TournamentID == TournamentID {
+			res = append(res, match)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Round != res[j].Round {
+			return res[i].Round < res[j].Round
+		}
+		return res[i].Slot < res[j].Slot
+	})
+	return res, nil
+}
+
+func (m *MemoryRepo) PostLeague(input models.League, fixtures []models.LeagueFixture) (Id int64, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	input.LeagueID = len(m.leagues) + 1
+	input.TieBreakers = append([]string(nil), input.TieBreakers...)
+	m.leagues = append(m.leagues, input)
+	for _, fixture := range fixtures {
+		fixture.LeagueID = input.LeagueID
+		fixture.FixtureID = len(m.fixtures) + 1
+		m.fixtures = append(m.fixtures, fixture)
+	}
+	return int64(input.LeagueID), nil
+}
+
+func (m *MemoryRepo) AdvanceLeague(LeagueID, round int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if LeagueID < 1 || LeagueID > len(m.leagues) || m.leagues[LeagueID-1].Round != round {
+		return ErrLeagueAdvanced
+	}
+	m.leagues[LeagueID-1].Round = round + 1
+	return nil
+}
+
+func (m *MemoryRepo) GetLeague(LeagueID int) (res models.League, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if LeagueID < 1 || LeagueID > len(m.leagues) {
+		return res, ErrLeagueNotFound
+	}
+
+	res = m.leagues[LeagueID-1]
+	res.TieBreakers = append([]string(nil), res.TieBreakers...)
+	return res, nil
+}
+
+func (m *MemoryRepo) PostLeagueFixture(input models.LeagueFixture) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	input.FixtureID = len(m.fixtures) + 1
+	m.fixtures = append(m.fixtures, input)
+	return nil
+}
+
+// SaveLeagueBattle keeps nothing of the battle when the fixture was already
+// played
+func (m *MemoryRepo) SaveLeagueBattle(FixtureID int, draw bool, input models.BattleInput, participants []models.Participant, events []models.BattleEvent, rate Rater) (Id int64, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if FixtureID < 1 || FixtureID > len(m.fixtures) || m.fixtures[FixtureID-1].Played {
+		return 0, ErrFixturePlayed
+	}
+
+	if Id, err = m.insertBattle(input, participants, events, rate); err != nil {
+		return 0, err
+	}
+
+	fixture := &m.fixtures[FixtureID-1]
+	fixture.Played = true
+	fixture.Draw = draw
+	if !draw {
+		fixture.Winner = input.Winner
+	}
+	fixture.BattleID = int(Id)
+	return Id, nil
+}
+
+func (m *MemoryRepo) GetLeagueFixtures(LeagueID int) (res []models.LeagueFixture, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, fixture := range m.fixtures {
+		if fixture.LeagueID == LeagueID {
+			res = append(res, fixture)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Round < res[j].Round
+	})
+	return res, nil
+}
+
+func (m *MemoryRepo) GetRatings() (res []models.Rating, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, rating := range m.ratings {
+		res = append(res, rating)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Rating != res[j].Rating {
+			return res[i].Rating > res[j].Rating
+		}
+		return res[i].Name < res[j].Name
+	})
+	return res, nil
+}
+
+// rateBattle rates the pokemons of a battle, the caller holds the lock
+func (m *MemoryRepo) rateBattle(BattleID int64, participants []models.Participant, rate Rater) {
+	var current []models.Rating
+	for _, participant := range participants {
+		if rating, ok := m.ratings[participant.Name]; ok {
+			current = append(current, rating)
+		}
+	}
+
+	before, after := rate(current)
+	for i, rating := range after {
+		m.ratings[rating.Name] = rating
+		m.history = append(m.history, models.RatingHistory{
+			BattleID:     int(BattleID),
+			Name:         rating.Name,
+			RatingBefore: before[i].Rating,
+			RatingAfter:  rating.Rating,
+			RDBefore:     before[i].RD,
+			RDAfter:      rating.RD,
+			CreatedAt:    rating.UpdatedAt,
+		})
+	}
+}
@@ -5,8 +5,10 @@
 package mocks
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
 	models "pokemon/models"
+	repository "pokemon/repository"
 	reflect "reflect"
 )
 
@@ -33,34 +35,196 @@ func (m *MockPokemonRepo) EXPECT() *MockPokemonRepoMockRecorder {
 	return m.recorder
 }
 
+// AdvanceLeague mocks base method
+func (m *MockPokemonRepo) AdvanceLeague(arg0, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdvanceLeague", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AdvanceLeague indicates an expected call of AdvanceLeague
+func (mr *MockPokemonRepoMockRecorder) AdvanceLeague(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdvanceLeague", reflect.TypeOf((*MockPokemonRepo)(nil).AdvanceLeague), arg0, arg1)
+}
+
+// AdvanceTournament mocks base method
+func (m *MockPokemonRepo) AdvanceTournament(arg0, arg1 int, arg2 string, arg3 []models.TournamentMatch) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdvanceTournament", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AdvanceTournament indicates an expected call of AdvanceTournament
+func (mr *MockPokemonRepoMockRecorder) AdvanceTournament(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdvanceTournament", reflect.TypeOf((*MockPokemonRepo)(nil).AdvanceTournament), arg0, arg1, arg2, arg3)
+}
+
+// AnnulPokemon mocks base method
+func (m *MockPokemonRepo) AnnulPokemon(arg0 int, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnnulPokemon", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AnnulPokemon indicates an expected call of AnnulPokemon
+func (mr *MockPokemonRepoMockRecorder) AnnulPokemon(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnnulPokemon", reflect.TypeOf((*MockPokemonRepo)(nil).AnnulPokemon), arg0, arg1)
+}
+
 // GetAllPokemons mocks base method
-func (m *MockPokemonRepo) GetAllPokemons() (models.AllPokemon, error) {
+func (m *MockPokemonRepo) GetAllPokemons(arg0 context.Context) (models.AllPokemon, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllPokemons")
+	ret := m.ctrl.Call(m, "GetAllPokemons", arg0)
 	ret0, _ := ret[0].(models.AllPokemon)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAllPokemons indicates an expected call of GetAllPokemons
-func (mr *MockPokemonRepoMockRecorder) GetAllPokemons() *gomock.Call {
+func (mr *MockPokemonRepoMockRecorder) GetAllPokemons(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllPokemons", reflect.TypeOf((*MockPokemonRepo)(nil).GetAllPokemons))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllPokemons", reflect.TypeOf((*MockPokemonRepo)(nil).GetAllPokemons), arg0)
 }
 
 // GetBattle mocks base method
-func (m *MockPokemonRepo) GetBattle(arg0, arg1 string) ([]models.BattleResponse, error) {
+func (m *MockPokemonRepo) GetBattle(arg0 models.BattleSearch) ([]models.BattleResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetBattle", arg0, arg1)
+	ret := m.ctrl.Call(m, "GetBattle", arg0)
 	ret0, _ := ret[0].([]models.BattleResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetBattle indicates an expected call of GetBattle
-func (mr *MockPokemonRepoMockRecorder) GetBattle(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockPokemonRepoMockRecorder) GetBattle(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBattle", reflect.TypeOf((*MockPokemonRepo)(nil).GetBattle), arg0)
+}
+
+// GetBattleByID mocks base method
+func (m *MockPokemonRepo) GetBattleByID(arg0 int) (models.Battle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBattleByID", arg0)
+	ret0, _ := ret[0].(models.Battle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBattleByID indicates an expected call of GetBattleByID
+func (mr *MockPokemonRepoMockRecorder) GetBattleByID(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBattle", reflect.TypeOf((*MockPokemonRepo)(nil).GetBattle), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBattleByID", reflect.TypeOf((*MockPokemonRepo)(nil).GetBattleByID), arg0)
+}
+
+// GetBattleEvents mocks base method
+func (m *MockPokemonRepo) GetBattleEvents(arg0 int) ([]models.BattleEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBattleEvents", arg0)
+	ret0, _ := ret[0].([]models.BattleEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBattleEvents indicates an expected call of GetBattleEvents
+func (mr *MockPokemonRepoMockRecorder) GetBattleEvents(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBattleEvents", reflect.TypeOf((*MockPokemonRepo)(nil).GetBattleEvents), arg0)
+}
+
+// GetBattleWithPlayers mocks base method
+func (m *MockPokemonRepo) GetBattleWithPlayers(arg0 models.BattleSearch) ([]models.BattleResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBattleWithPlayers", arg0)
+	ret0, _ := ret[0].([]models.BattleResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBattleWithPlayers indicates an expected call of GetBattleWithPlayers
+func (mr *MockPokemonRepoMockRecorder) GetBattleWithPlayers(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBattleWithPlayers", reflect.TypeOf((*MockPokemonRepo)(nil).GetBattleWithPlayers), arg0)
+}
+
+// GetCacheStats mocks base method
+func (m *MockPokemonRepo) GetCacheStats() (models.CacheStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCacheStats")
+	ret0, _ := ret[0].(models.CacheStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCacheStats indicates an expected call of GetCacheStats
+func (mr *MockPokemonRepoMockRecorder) GetCacheStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCacheStats", reflect.TypeOf((*MockPokemonRepo)(nil).GetCacheStats))
+}
+
+// GetFinishOrder mocks base method
+func (m *MockPokemonRepo) GetFinishOrder(arg0 int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFinishOrder", arg0)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFinishOrder indicates an expected call of GetFinishOrder
+func (mr *MockPokemonRepoMockRecorder) GetFinishOrder(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFinishOrder", reflect.TypeOf((*MockPokemonRepo)(nil).GetFinishOrder), arg0)
+}
+
+// GetLeague mocks base method
+func (m *MockPokemonRepo) GetLeague(arg0 int) (models.League, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLeague", arg0)
+	ret0, _ := ret[0].(models.League)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLeague indicates an expected call of GetLeague
+func (mr *MockPokemonRepoMockRecorder) GetLeague(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLeague", reflect.TypeOf((*MockPokemonRepo)(nil).GetLeague), arg0)
+}
+
+// GetLeagueFixtures mocks base method
+func (m *MockPokemonRepo) GetLeagueFixtures(arg0 int) ([]models.LeagueFixture, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLeagueFixtures", arg0)
+	ret0, _ := ret[0].([]models.LeagueFixture)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLeagueFixtures indicates an expected call of GetLeagueFixtures
+func (mr *MockPokemonRepoMockRecorder) GetLeagueFixtures(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLeagueFixtures", reflect.TypeOf((*MockPokemonRepo)(nil).GetLeagueFixtures), arg0)
+}
+
+// GetMove mocks base method
+func (m *MockPokemonRepo) GetMove(arg0 context.Context, arg1 string) (models.Move, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMove", arg0, arg1)
+	ret0, _ := ret[0].(models.Move)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMove indicates an expected call of GetMove
+func (mr *MockPokemonRepoMockRecorder) GetMove(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMove", reflect.TypeOf((*MockPokemonRepo)(nil).GetMove), arg0, arg1)
 }
 
 // GetPlayer mocks base method
@@ -79,60 +243,194 @@ func (mr *MockPokemonRepoMockRecorder) GetPlayer(arg0 interface{}) *gomock.Call
 }
 
 // GetPokemonByName mocks base method
-func (m *MockPokemonRepo) GetPokemonByName(arg0 string) (models.GetPokemon, error) {
+func (m *MockPokemonRepo) GetPokemonByName(arg0 context.Context, arg1 string) (models.GetPokemon, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPokemonByName", arg0)
+	ret := m.ctrl.Call(m, "GetPokemonByName", arg0, arg1)
 	ret0, _ := ret[0].(models.GetPokemon)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetPokemonByName indicates an expected call of GetPokemonByName
-func (mr *MockPokemonRepoMockRecorder) GetPokemonByName(arg0 interface{}) *gomock.Call {
+func (mr *MockPokemonRepoMockRecorder) GetPokemonByName(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPokemonByName", reflect.TypeOf((*MockPokemonRepo)(nil).GetPokemonByName), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPokemonByName", reflect.TypeOf((*MockPokemonRepo)(nil).GetPokemonByName), arg0, arg1)
 }
 
 // GetPokemonScore mocks base method
-func (m *MockPokemonRepo) GetPokemonScore() ([]models.DetailPlayers, error) {
+func (m *MockPokemonRepo) GetPokemonScore(arg0 models.ScoreRange) ([]models.DetailPlayers, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPokemonScore")
+	ret := m.ctrl.Call(m, "GetPokemonScore", arg0)
 	ret0, _ := ret[0].([]models.DetailPlayers)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetPokemonScore indicates an expected call of GetPokemonScore
-func (mr *MockPokemonRepoMockRecorder) GetPokemonScore() *gomock.Call {
+func (mr *MockPokemonRepoMockRecorder) GetPokemonScore(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPokemonScore", reflect.TypeOf((*MockPokemonRepo)(nil).GetPokemonScore), arg0)
+}
+
+// GetRatings mocks base method
+func (m *MockPokemonRepo) GetRatings() ([]models.Rating, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRatings")
+	ret0, _ := ret[0].([]models.Rating)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRatings indicates an expected call of GetRatings
+func (mr *MockPokemonRepoMockRecorder) GetRatings() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRatings", reflect.TypeOf((*MockPokemonRepo)(nil).GetRatings))
+}
+
+// GetSpecies mocks base method
+func (m *MockPokemonRepo) GetSpecies(arg0 context.Context, arg1 string) (models.Species, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSpecies", arg0, arg1)
+	ret0, _ := ret[0].(models.Species)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSpecies indicates an expected call of GetSpecies
+func (mr *MockPokemonRepoMockRecorder) GetSpecies(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSpecies", reflect.TypeOf((*MockPokemonRepo)(nil).GetSpecies), arg0, arg1)
+}
+
+// GetTournament mocks base method
+func (m *MockPokemonRepo) GetTournament(arg0 int) (models.Tournament, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTournament", arg0)
+	ret0, _ := ret[0].(models.Tournament)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTournament indicates an expected call of GetTournament
+func (mr *MockPokemonRepoMockRecorder) GetTournament(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTournament", reflect.TypeOf((*MockPokemonRepo)(nil).GetTournament), arg0)
+}
+
+// GetTournamentMatches mocks base method
+func (m *MockPokemonRepo) GetTournamentMatches(arg0 int) ([]models.TournamentMatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTournamentMatches", arg0)
+	ret0, _ := ret[0].([]models.TournamentMatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTournamentMatches indicates an expected call of GetTournamentMatches
+func (mr *MockPokemonRepoMockRecorder) GetTournamentMatches(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTournamentMatches", reflect.TypeOf((*MockPokemonRepo)(nil).GetTournamentMatches), arg0)
+}
+
+// PostLeague mocks base method
+func (m *MockPokemonRepo) PostLeague(arg0 models.League, arg1 []models.LeagueFixture) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostLeague", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostLeague indicates an expected call of PostLeague
+func (mr *MockPokemonRepoMockRecorder) PostLeague(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPokemonScore", reflect.TypeOf((*MockPokemonRepo)(nil).GetPokemonScore))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostLeague", reflect.TypeOf((*MockPokemonRepo)(nil).PostLeague), arg0, arg1)
 }
 
-// PostBattlePokemon mocks base method
-func (m *MockPokemonRepo) PostBattlePokemon(arg0 models.BattleInput) (int64, error) {
+// PostLeagueFixture mocks base method
+func (m *MockPokemonRepo) PostLeagueFixture(arg0 models.LeagueFixture) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PostBattlePokemon", arg0)
+	ret := m.ctrl.Call(m, "PostLeagueFixture", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PostLeagueFixture indicates an expected call of PostLeagueFixture
+func (mr *MockPokemonRepoMockRecorder) PostLeagueFixture(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostLeagueFixture", reflect.TypeOf((*MockPokemonRepo)(nil).PostLeagueFixture), arg0)
+}
+
+// PostTournament mocks base method
+func (m *MockPokemonRepo) PostTournament(arg0 models.Tournament, arg1 []models.TournamentMatch) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostTournament", arg0, arg1)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// PostBattlePokemon indicates an expected call of PostBattlePokemon
-func (mr *MockPokemonRepoMockRecorder) PostBattlePokemon(arg0 interface{}) *gomock.Call {
+// PostTournament indicates an expected call of PostTournament
+func (mr *MockPokemonRepoMockRecorder) PostTournament(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostBattlePokemon", reflect.TypeOf((*MockPokemonRepo)(nil).PostBattlePokemon), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostTournament", reflect.TypeOf((*MockPokemonRepo)(nil).PostTournament), arg0, arg1)
 }
 
-// PostPokemonData mocks base method
-func (m *MockPokemonRepo) PostPokemonData(arg0 models.Pokemon) error {
+// PostTournamentMatch mocks base method
+func (m *MockPokemonRepo) PostTournamentMatch(arg0 models.TournamentMatch) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PostPokemonData", arg0)
+	ret := m.ctrl.Call(m, "PostTournamentMatch", arg0)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// PostPokemonData indicates an expected call of PostPokemonData
-func (mr *MockPokemonRepoMockRecorder) PostPokemonData(arg0 interface{}) *gomock.Call {
+// PostTournamentMatch indicates an expected call of PostTournamentMatch
+func (mr *MockPokemonRepoMockRecorder) PostTournamentMatch(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostTournamentMatch", reflect.TypeOf((*MockPokemonRepo)(nil).PostTournamentMatch), arg0)
+}
+
+// SaveBattle mocks base method
+func (m *MockPokemonRepo) SaveBattle(arg0 models.BattleInput, arg1 []models.Participant, arg2 []models.BattleEvent, arg3 repository.Rater) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveBattle", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveBattle indicates an expected call of SaveBattle
+func (mr *MockPokemonRepoMockRecorder) SaveBattle(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBattle", reflect.TypeOf((*MockPokemonRepo)(nil).SaveBattle), arg0, arg1, arg2, arg3)
+}
+
+// SaveLeagueBattle mocks base method
+func (m *MockPokemonRepo) SaveLeagueBattle(arg0 int, arg1 bool, arg2 models.BattleInput, arg3 []models.Participant, arg4 []models.BattleEvent, arg5 repository.Rater) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveLeagueBattle", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveLeagueBattle indicates an expected call of SaveLeagueBattle
+func (mr *MockPokemonRepoMockRecorder) SaveLeagueBattle(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveLeagueBattle", reflect.TypeOf((*MockPokemonRepo)(nil).SaveLeagueBattle), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// SaveTournamentBattle mocks base method
+func (m *MockPokemonRepo) SaveTournamentBattle(arg0 int, arg1 models.BattleInput, arg2 []models.Participant, arg3 []models.BattleEvent, arg4 repository.Rater) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTournamentBattle", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveTournamentBattle indicates an expected call of SaveTournamentBattle
+func (mr *MockPokemonRepoMockRecorder) SaveTournamentBattle(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostPokemonData", reflect.TypeOf((*MockPokemonRepo)(nil).PostPokemonData), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTournamentBattle", reflect.TypeOf((*MockPokemonRepo)(nil).SaveTournamentBattle), arg0, arg1, arg2, arg3, arg4)
 }
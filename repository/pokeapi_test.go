@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"pokemon/models"
+	"pokemon/pokeapi"
+	"pokemon/pokeapi/fakeapi"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakePokeRepo points a PokeRepo at a fake PokeAPI, without retries so the
+// error modes surface right away
+func newFakePokeRepo(t *testing.T, mode fakeapi.Mode, delay time.Duration) *PokeRepo {
+	fake := fakeapi.New()
+	fake.SetMode(mode, delay)
+
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	client := pokeapi.NewClient(pokeapi.Config{
+		BaseURL: srv.URL,
+		Timeout: 100 * time.Millisecond,
+	})
+	return NewPokeRepo(nil, NewPokeAPIPokedex(client))
+}
+
+func Test_Get_AllPokemons(t *testing.T) {
+	type testCase struct {
+		name          string
+		mode          fakeapi.Mode
+		wantError     bool
+		expectedError error
+	}
+
+	var testTable []testCase
+
+	testTable = append(testTable, testCase{
+		name:          "failed upstream error",
+		mode:          fakeapi.ModeError,
+		wantError:     true,
+		expectedError: &pokeapi.UpstreamError{StatusCode: http.StatusInternalServerError},
+	})
+
+	testTable = append(testTable, testCase{
+		name: "success",
+		mode: fakeapi.ModeNormal,
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := newFakePokeRepo(t, tc.mode, 0)
+
+			res, err := repo.GetAllPokemons(context.Background())
+			if tc.wantError {
+				var upstream *pokeapi.UpstreamError
+				require.True(t, errors.As(err, &upstream), err)
+				assert.Equal(t, tc.expectedError.(*pokeapi.UpstreamError).StatusCode, upstream.StatusCode)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, 10, res.Count)
+				require.Len(t, res.Results, 10)
+				assert.Equal(t, "bulbasaur", res.Results[0].Name)
+				assert.Equal(t, "lucario", res.Results[9].Name)
+			}
+		})
+	}
+}
+
+func Test_Get_PokemonByName(t *testing.T) {
+	type testCase struct {
+		name           string
+		input          string
+		mode           fakeapi.Mode
+		delay          time.Duration
+		wantError      bool
+		expectedError  error
+		expectedStatus int
+		expectedResult models.GetPokemon
+	}
+
+	var testTable []testCase
+
+	testTable = append(testTable, testCase{
+		name:          "failed not found",
+		input:         "missingno",
+		mode:          fakeapi.ModeNormal,
+		wantError:     true,
+		expectedError: ErrPokemonNotFound,
+	})
+
+	testTable = append(testTable, testCase{
+		name:           "failed rate limited",
+		input:          "pikachu",
+		mode:           fakeapi.ModeRateLimit,
+		wantError:      true,
+		expectedStatus: http.StatusTooManyRequests,
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "failed timeout",
+		input:     "pikachu",
+		mode:      fakeapi.ModeSlow,
+		delay:     time.Second,
+		wantError: true,
+	})
+
+	testTable = append(testTable, testCase{
+		name:  "success",
+		input: "pikachu",
+		mode:  fakeapi.ModeNormal,
+		expectedResult: models.GetPokemon{
+			ID:   25,
+			Name: "pikachu",
+			Stats: []models.Stats{
+				{BaseStat: 35, Stat: models.Stat{Name: "hp", Url: "https://pokeapi.co/api/v2/stat/1/"}},
+				{BaseStat: 55, Stat: models.Stat{Name: "attack", Url: "https://pokeapi.co/api/v2/stat/2/"}},
+				{BaseStat: 40, Stat: models.Stat{Name: "defense", Url: "https://pokeapi.co/api/v2/stat/3/"}},
+				{BaseStat: 50, Stat: models.Stat{Name: "special-attack", Url: "https://pokeapi.co/api/v2/stat/4/"}},
+				{BaseStat: 50, Stat: models.Stat{Name: "special-defense", Url: "https://pokeapi.co/api/v2/stat/5/"}},
+				{BaseStat: 90, Effort: 2, Stat: models.Stat{Name: "speed", Url: "https://pokeapi.co/api/v2/stat/6/"}},
+			},
+			Types: []models.Types{
+				{Slot: 1, Type: models.Type{Name: "electric", Url: "https://pokeapi.co/api/v2/type/13/"}},
+			},
+			Moves: []models.Moves{
+				{Move: models.NamedMove{Name: "thunder-shock", Url: "https://pokeapi.co/api/v2/move/84/"}},
+				{Move: models.NamedMove{Name: "quick-attack", Url: "https://pokeapi.co/api/v2/move/98/"}},
+				{Move: models.NamedMove{Name: "thunderbolt", Url: "https://pokeapi.co/api/v2/move/85/"}},
+				{Move: models.NamedMove{Name: "growl", Url: "https://pokeapi.co/api/v2/move/45/"}},
+			},
+			Species: models.NamedSpecies{Name: "pikachu", Url: "https://pokeapi.co/api/v2/pokemon-species/25/"},
+		},
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := newFakePokeRepo(t, tc.mode, tc.delay)
+
+			res, err := repo.GetPokemonByName(context.Background(), tc.input)
+			switch {
+			case tc.expectedError != nil:
+				assert.EqualError(t, err, tc.expectedError.Error())
+			case tc.wantError:
+				var upstream *pokeapi.UpstreamError
+				require.True(t, errors.As(err, &upstream), err)
+				assert.Equal(t, tc.expectedStatus, upstream.StatusCode)
+			default:
+				assert.Nil(t, err)
+				assert.Equal(t, tc.expectedResult, res)
+			}
+		})
+	}
+}
+
+func Test_Get_Move(t *testing.T) {
+	repo := newFakePokeRepo(t, fakeapi.ModeNormal, 0)
+
+	res, err := repo.GetMove(context.Background(), "swift")
+	assert.Nil(t, err)
+	assert.Equal(t, "swift", res.Name)
+	assert.Equal(t, 60, res.Power)
+	assert.Equal(t, 0, res.Accuracy)
+	assert.Equal(t, "special", res.DamageClass.Name)
+	assert.Equal(t, "normal", res.Type.Name)
+
+	_, err = repo.GetMove(context.Background(), "splash")
+	assert.Equal(t, ErrMoveNotFound, err)
+}
+
+func Test_Get_Species(t *testing.T) {
+	repo := newFakePokeRepo(t, fakeapi.ModeNormal, 0)
+
+	res, err := repo.GetSpecies(context.Background(), "lucario")
+	assert.Nil(t, err)
+	assert.Equal(t, models.Species{
+		Name:       "lucario",
+		Generation: models.Generation{Name: "generation-iv", Url: "https://pokeapi.co/api/v2/generation/4/"},
+	}, res)
+
+	res, err = repo.GetSpecies(context.Background(), "mew")
+	assert.Nil(t, err)
+	assert.True(t, res.IsMythical)
+
+	_, err = repo.GetSpecies(context.Background(), "agumon")
+	assert.Equal(t, ErrSpeciesNotFound, err)
+}
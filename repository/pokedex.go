@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"pokemon/models"
+	"pokemon/pokeapi"
+	"pokemon/repository/query"
+	"strconv"
+)
+
+const (
+	PokedexPokeAPI = "pokeapi"
+	PokedexOffline = "offline"
+)
+
+// Pokedex is where the pokemon and their moves are looked up
+type Pokedex interface {
+	GetAllPokemons(ctx context.Context) (res models.AllPokemon, err error)
+	GetPokemonByName(ctx context.Context, name string) (res models.GetPokemon, err error)
+	GetMove(ctx context.Context, name string) (res models.Move, err error)
+	GetSpecies(ctx context.Context, name string) (res models.Species, err error)
+}
+
+// PokeAPIPokedex looks everything up on PokeAPI
+type PokeAPIPokedex struct {
+	api *pokeapi.Client
+}
+
+func NewPokeAPIPokedex(api *pokeapi.Client) *PokeAPIPokedex {
+	return &PokeAPIPokedex{api: api}
+}
+
+func (d *PokeAPIPokedex) GetAllPokemons(ctx context.Context) (res models.AllPokemon, err error) {
+	res, err = d.api.GetAllPokemons(ctx)
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+func (d *PokeAPIPokedex) GetPokemonByName(ctx context.Context, name string) (res models.GetPokemon, err error) {
+	res, err = d.api.GetPokemon(ctx, name)
+	if errors.Is(err, pokeapi.ErrNotFound) {
+		return res, ErrPokemonNotFound
+	}
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+func (d *PokeAPIPokedex) GetMove(ctx context.Context, name string) (res models.Move, err error) {
+	res, err = d.api.GetMove(ctx, name)
+	if errors.Is(err, pokeapi.ErrNotFound) {
+		return res, ErrMoveNotFound
+	}
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+func (d *PokeAPIPokedex) GetSpecies(ctx context.Context, name string) (res models.Species, err error) {
+	res, err = d.api.GetSpecies(ctx, name)
+	if errors.Is(err, pokeapi.ErrNotFound) {
+		return res, ErrSpeciesNotFound
+	}
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+func (d *PokeAPIPokedex) CacheStats() models.CacheStats {
+	return d.api.CacheStats()
+}
+
+// OfflinePokedex only reads the pokedex_* tables filled by the snapshot
+// import, it never reaches PokeAPI. The snapshot carries no moves nor species
+// so every fighter uses the generic attack and the generation, legendary and
+// mythical filters are refused.
+type OfflinePokedex struct {
+	db *sql.DB
+}
+
+func NewOfflinePokedex(db *sql.DB) *OfflinePokedex {
+	return &OfflinePokedex{db: db}
+}
+
+func (d *OfflinePokedex) GetAllPokemons(ctx context.Context) (res models.AllPokemon, err error) {
+	row, err := d.db.QueryContext(
+		ctx,
+		query.GetPokedexSpecies,
+	)
+	if err != nil {
+		return res, err
+	}
+	defer row.Close()
+
+	for row.Next() {
+		var name string
+		if err = row.Scan(&name); err != nil {
+			return res, err
+		}
+
+		res.Results = append(res.Results, struct {
+			Name string `json:"name"`
+			Url  string `json:"url"`
+		}{Name: name})
+	}
+	if err = row.Err(); err != nil {
+		return res, err
+	}
+
+	res.Count = len(res.Results)
+	return res, nil
+}
+
+// GetPokemonByName also takes the national dex number, like PokeAPI does
+func (d *OfflinePokedex) GetPokemonByName(ctx context.Context, name string) (res models.GetPokemon, err error) {
+	qry := query.GetPokedexSpeciesByName
+	if _, err := strconv.Atoi(name); err == nil {
+		qry = query.GetPokedexSpeciesByID
+	}
+
+	err = d.db.QueryRowContext(
+		ctx,
+		qry,
+		name,
+	).Scan(
+		&res.ID,
+		&res.Name,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return res, ErrPokemonNotFound
+	}
+	if err != nil {
+		return res, err
+	}
+
+	row, err := d.db.QueryContext(
+		ctx,
+		query.GetPokedexStats,
+		res.Name,
+	)
+	if err != nil {
+		return res, err
+	}
+	defer row.Close()
+
+	for row.Next() {
+		temp := models.Stats{}
+		err = row.Scan(
+			&temp.Stat.Name,
+			&temp.BaseStat,
+			&temp.Effort,
+		)
+		if err != nil {
+			return res, err
+		}
+
+		res.Stats = append(res.Stats, temp)
+	}
+	if err = row.Err(); err != nil {
+		return res, err
+	}
+
+	row, err = d.db.QueryContext(
+		ctx,
+		query.GetPokedexTypes,
+		res.Name,
+	)
+	if err != nil {
+		return res, err
+	}
+	defer row.Close()
+
+	for row.Next() {
+		temp := models.Types{}
+		err = row.Scan(
+			&temp.Slot,
+			&temp.Type.Name,
+		)
+		if err != nil {
+			return res, err
+		}
+
+		res.Types = append(res.Types, temp)
+	}
+	if err = row.Err(); err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (d *OfflinePokedex) GetMove(ctx context.Context, name string) (res models.Move, err error) {
+	return res, ErrMoveNotFound
+}
+
+func (d *OfflinePokedex) GetSpecies(ctx context.Context, name string) (res models.Species, err error) {
+	return res, ErrSpeciesUnavailable
+}
+
+// Import replaces the whole pokedex with the snapshot in one transaction,
+// the pokemon keep the order of the snapshot
+func (d *OfflinePokedex) Import(pokemons []models.GetPokemon) (err error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.Exec(query.ClearPokedex); err != nil {
+		return err
+	}
+
+	for i, poke := range pokemons {
+		if _, err = tx.Exec(query.PostPokedexSpecies, poke.Name, poke.ID, i+1); err != nil {
+			return err
+		}
+
+		for _, stat := range poke.Stats {
+			_, err = tx.Exec(query.PostPokedexStat, poke.Name, stat.Stat.Name, stat.BaseStat, stat.Effort)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, t := range poke.Types {
+			_, err = tx.Exec(query.PostPokedexType, poke.Name, t.Slot, t.Type.Name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,290 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"pokemon/models"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_OfflinePokedex_GetPokemonByName(t *testing.T) {
+	type testCase struct {
+		name           string
+		input          string
+		wantError      bool
+		mockQuery      func(mock sqlmock.Sqlmock)
+		expectedError  error
+		expectedResult models.GetPokemon
+	}
+
+	var (
+		testTable   []testCase
+		byNameQuery = `
+		SELECT
+			s.id,
+			s.name
+		FROM pokedex_species s
+		WHERE s.name = $1
+	`
+		byIDQuery = `
+		SELECT
+			s.id,
+			s.name
+		FROM pokedex_species s
+		WHERE s.id = $1::int
+	`
+		statsQuery = `
+		SELECT
+			st.stat,
+			st.base_stat,
+			st.effort
+		FROM pokedex_stats st
+		WHERE st.name = $1
+		ORDER BY st.stat_id
+	`
+		typesQuery = `
+		SELECT
+			t.slot,
+			t.type
+		FROM pokedex_types t
+		WHERE t.name = $1
+		ORDER BY t.slot
+	`
+		pikachu = models.GetPokemon{
+			ID:    25,
+			Name:  "pikachu",
+			Stats: []models.Stats{{BaseStat: 35, Stat: models.Stat{Name: "hp"}}, {BaseStat: 90, Effort: 2, Stat: models.Stat{Name: "speed"}}},
+			Types: []models.Types{{Slot: 1, Type: models.Type{Name: "electric"}}},
+		}
+		details = func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(statsQuery)).
+				WithArgs("pikachu").
+				WillReturnRows(sqlmock.NewRows([]string{"stat", "base_stat", "effort"}).
+					AddRow("hp", 35, 0).
+					AddRow("speed", 90, 2))
+			mock.ExpectQuery(regexp.QuoteMeta(typesQuery)).
+				WithArgs("pikachu").
+				WillReturnRows(sqlmock.NewRows([]string{"slot", "type"}).AddRow(1, "electric"))
+		}
+	)
+
+	testTable = append(testTable, testCase{
+		name:      "failed not found",
+		input:     "missingno",
+		wantError: true,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(byNameQuery)).
+				WithArgs("missingno").
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+		},
+		expectedError: ErrPokemonNotFound,
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "failed unexpected error",
+		input:     "pikachu",
+		wantError: true,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(byNameQuery)).
+				WillReturnError(errors.New("unexpected error"))
+		},
+		expectedError: errors.New("unexpected error"),
+	})
+
+	testTable = append(testTable, testCase{
+		name:  "success by name",
+		input: "pikachu",
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(byNameQuery)).
+				WithArgs("pikachu").
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(25, "pikachu"))
+			details(mock)
+		},
+		expectedResult: pikachu,
+	})
+
+	testTable = append(testTable, testCase{
+		name:  "success by id",
+		input: "25",
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(byIDQuery)).
+				WithArgs("25").
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(25, "pikachu"))
+			details(mock)
+		},
+		expectedResult: pikachu,
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+
+			if tc.mockQuery != nil {
+				tc.mockQuery(mock)
+			}
+			pokedex := OfflinePokedex{
+				db: db,
+			}
+
+			res, serr := pokedex.GetPokemonByName(context.Background(), tc.input)
+			if tc.wantError {
+				assert.EqualError(t, serr, tc.expectedError.Error())
+			} else {
+				assert.Nil(t, serr)
+				assert.Equal(t, tc.expectedResult, res)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_OfflinePokedex_GetSpecies(t *testing.T) {
+	pokedex := OfflinePokedex{}
+
+	_, err := pokedex.GetSpecies(context.Background(), "mew")
+	assert.ErrorIs(t, err, ErrSpeciesUnavailable)
+}
+
+func Test_OfflinePokedex_GetAllPokemons(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`
+		SELECT
+			s.name
+		FROM pokedex_species s
+		ORDER BY s.position
+	`)).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("pikachu").AddRow("eevee"))
+
+	pokedex := OfflinePokedex{
+		db: db,
+	}
+
+	res, err := pokedex.GetAllPokemons(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 2, res.Count)
+	assert.Equal(t, "pikachu", res.Results[0].Name)
+	assert.Equal(t, "eevee", res.Results[1].Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func Test_OfflinePokedex_Import(t *testing.T) {
+	type testCase struct {
+		name      string
+		wantError bool
+		mockQuery func(mock sqlmock.Sqlmock)
+	}
+
+	var (
+		testTable  []testCase
+		clearQuery = `
+		TRUNCATE pokedex_species, pokedex_stats, pokedex_types RESTART IDENTITY
+	`
+		speciesQuery = `
+		INSERT INTO
+			pokedex_species(
+				name,
+				id,
+				position
+			)
+		VALUES(
+			$1, $2, $3
+		)
+	`
+		statQuery = `
+		INSERT INTO
+			pokedex_stats(
+				name,
+				stat,
+				base_stat,
+				effort
+			)
+		VALUES(
+			$1, $2, $3, $4
+		)
+	`
+		typeQuery = `
+		INSERT INTO
+			pokedex_types(
+				name,
+				slot,
+				type
+			)
+		VALUES(
+			$1, $2, $3
+		)
+	`
+		input = []models.GetPokemon{
+			{
+				ID:    25,
+				Name:  "pikachu",
+				Stats: []models.Stats{{BaseStat: 35, Stat: models.Stat{Name: "hp"}}},
+				Types: []models.Types{{Slot: 1, Type: models.Type{Name: "electric"}}},
+			},
+			{ID: 133, Name: "eevee"},
+		}
+	)
+
+	testTable = append(testTable, testCase{
+		name:      "failed rolls back",
+		wantError: true,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(clearQuery)).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta(speciesQuery)).
+				WithArgs("pikachu", 25, 1).
+				WillReturnError(errors.New("unexpected error"))
+			mock.ExpectRollback()
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name: "success",
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(clearQuery)).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta(speciesQuery)).
+				WithArgs("pikachu", 25, 1).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta(statQuery)).
+				WithArgs("pikachu", "hp", 35, 0).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta(typeQuery)).
+				WithArgs("pikachu", 1, "electric").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta(speciesQuery)).
+				WithArgs("eevee", 133, 2).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+		},
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+
+			tc.mockQuery(mock)
+			pokedex := OfflinePokedex{
+				db: db,
+			}
+
+			serr := pokedex.Import(input)
+			if tc.wantError {
+				assert.NotNil(t, serr)
+			} else {
+				assert.Nil(t, serr)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
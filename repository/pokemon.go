@@ -1,110 +1,226 @@
 package repository
 
 import (
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
-	"net/http"
+	"context"
+	"database/sql"
+	"errors"
 	"pokemon/models"
 	"pokemon/repository/query"
+	"strings"
+)
+
+var (
+	ErrPokemonNotFound = errors.New("pokemon not found")
+	ErrBattleNotFound  = errors.New("battle not found")
+	ErrMoveNotFound    = errors.New("move not found")
+	ErrSpeciesNotFound = errors.New("species not found")
+	// ErrSpeciesUnavailable is returned by a pokedex that has no species at all
+	ErrSpeciesUnavailable = errors.New("the offline pokedex has no species, generation, legendary and mythical filters need POKEDEX_SOURCE=pokeapi")
+
+	ErrPokemonNotInBattle = errors.New("pokemon didn't take part in the battle")
+	ErrAlreadyAnnulled    = errors.New("pokemon is already annulled")
 )
 
 type PokemonRepo interface {
-	GetAllPokemons() (res models.AllPokemon, err error)
-	GetPokemonByName(name string) (res models.GetPokemon, err error)
-	GetBattle(start_time, end_time string) (res []models.BattleResponse, err error)
+	GetAllPokemons(ctx context.Context) (res models.AllPokemon, err error)
+	GetPokemonByName(ctx context.Context, name string) (res models.GetPokemon, err error)
+	GetMove(ctx context.Context, name string) (res models.Move, err error)
+	GetSpecies(ctx context.Context, name string) (res models.Species, err error)
+	GetCacheStats() (res models.CacheStats, err error)
+	GetBattle(search models.BattleSearch) (res []models.BattleResponse, err error)
+	GetBattleWithPlayers(search models.BattleSearch) (res []models.BattleResponse, err error)
+	GetBattleByID(BattleID int) (res models.Battle, err error)
 	GetPlayer(BattleID int) (res []models.DetailPlayers, err error)
-	GetPokemonScore() (res []models.DetailPlayers, err error)
-	PostPokemonData(input models.Pokemon) error
-	PostBattlePokemon(input models.BattleInput) (Id int64, err error)
+	GetFinishOrder(BattleID int) (res []string, err error)
+	GetPokemonScore(r models.ScoreRange) (res []models.DetailPlayers, err error)
+	AnnulPokemon(BattleID int, name string) error
+	PostTournament(input models.Tournament, matches []models.TournamentMatch) (Id int64, err error)
+	AdvanceTournament(TournamentID, round int, winner string, next []models.TournamentMatch) error
+	GetTournament(TournamentID int) (res models.Tournament, err error)
+	PostTournamentMatch(input models.TournamentMatch) error
+	SaveTournamentBattle(MatchID int, input models.BattleInput, participants []models.Participant, events []models.BattleEvent, rate Rater) (Id int64, err error)
+	GetTournamentMatches(TournamentID int) (res []models.TournamentMatch, err error)
+	PostLeague(input models.League, fixtures []models.LeagueFixture) (Id int64, err error)
+	AdvanceLeague(LeagueID, round int) error
+	GetLeague(LeagueID int) (res models.League, err error)
+	PostLeagueFixture(input models.LeagueFixture) error
+	SaveLeagueBattle(FixtureID int, draw bool, input models.BattleInput, participants []models.Participant, events []models.BattleEvent, rate Rater) (Id int64, err error)
+	GetLeagueFixtures(LeagueID int) (res []models.LeagueFixture, err error)
+	GetRatings() (res []models.Rating, err error)
+	SaveBattle(input models.BattleInput, participants []models.Participant, events []models.BattleEvent, rate Rater) (Id int64, err error)
+	GetBattleEvents(BattleID int) (res []models.BattleEvent, err error)
 }
 
-func (p *PokeRepo) GetAllPokemons() (res models.AllPokemon, err error) {
-	response, err := http.Get("http://pokeapi.co/api/v2/pokemon?limit=100000&offset=0")
-	if err != nil {
-		return res, err
-	}
+func (p *PokeRepo) GetAllPokemons(ctx context.Context) (res models.AllPokemon, err error) {
+	return p.pokedex.GetAllPokemons(ctx)
+}
 
-	responseData, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		return res, err
+func (p *PokeRepo) GetPokemonByName(ctx context.Context, name string) (res models.GetPokemon, err error) {
+	return p.pokedex.GetPokemonByName(ctx, name)
+}
 
-	}
+func (p *PokeRepo) GetMove(ctx context.Context, name string) (res models.Move, err error) {
+	return p.pokedex.GetMove(ctx, name)
+}
 
-	err = json.Unmarshal(responseData, &res)
-	if err != nil {
-		return res, err
-	}
-	return res, nil
+func (p *PokeRepo) GetSpecies(ctx context.Context, name string) (res models.Species, err error) {
+	return p.pokedex.GetSpecies(ctx, name)
 }
 
-func (p *PokeRepo) GetPokemonByName(name string) (res models.GetPokemon, err error) {
-	response, err := http.Get("http://pokeapi.co/api/v2/pokemon/" + name)
+// SaveBattle stores the battle with its participants and its event log in
+// one transaction and returns the id of the battle, when rate is set the
+// participants are rated in the same transaction. When any insert fails
+// nothing of the battle is kept.
+func (p *PokeRepo) SaveBattle(input models.BattleInput, participants []models.Participant, events []models.BattleEvent, rate Rater) (Id int64, err error) {
+	tx, err := p.db.Begin()
 	if err != nil {
-		return res, err
+		return Id, err
 	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
 
-	responseData, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		return res, err
-
+	if Id, err = p.insertBattle(tx, input, participants, events, rate); err != nil {
+		return 0, err
 	}
 
-	err = json.Unmarshal(responseData, &res)
-	if err != nil {
-		return res, err
+	if err = tx.Commit(); err != nil {
+		return 0, err
 	}
-	return res, nil
+	return Id, nil
 }
 
-func (p *PokeRepo) PostBattlePokemon(input models.BattleInput) (Id int64, err error) {
-	err = p.db.QueryRow(
-		query.PostBattle,
+// insertBattle writes the battle, its participants and its event log in tx
+// and rates the participants with rate when it's set
+func (p *PokeRepo) insertBattle(tx *sql.Tx, input models.BattleInput, participants []models.Participant, events []models.BattleEvent, rate Rater) (Id int64, err error) {
+	queries := p.battleQueries()
+
+	if queries.species != "" {
+		names := []string{input.Winner}
+		for _, participant := range participants {
+			names = append(names, participant.Name)
+		}
+		for _, name := range names {
+			if _, err = tx.Exec(p.rebind(queries.species), name); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	err = tx.QueryRow(
+		p.rebind(queries.battle),
 		input.Winner,
+		input.Seed,
+		strings.Join(input.Roster, ","),
+		input.MovePolicy,
+		input.Engine,
+		input.EngineVersion,
 		input.StartTime,
 		input.EndTime,
-	).Err()
-
+	).Scan(&Id)
 	if err != nil {
-		return Id, err
+		return 0, err
+	}
+
+	for _, participant := range participants {
+		_, err = tx.Exec(
+			p.rebind(queries.participant),
+			participant.Name,
+			Id,
+			participant.Placement,
+			participant.Scores,
+		)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	for _, event := range events {
+		_, err = tx.Exec(
+			p.rebind(query.PostBattleEvent),
+			Id,
+			event.Turn,
+			event.Actor,
+			event.Target,
+			event.Move,
+			event.Damage,
+			event.RemainingHP,
+			event.Eliminated,
+		)
+		if err != nil {
+			return 0, err
+		}
 	}
+
+	if rate != nil {
+		names := make([]string, 0, len(participants))
+		for _, participant := range participants {
+			names = append(names, participant.Name)
+		}
+		if err = p.rateBattle(tx, Id, names, rate); err != nil {
+			return 0, err
+		}
+	}
+
 	return Id, nil
 }
 
-func (p *PokeRepo) PostPokemonData(input models.Pokemon) error {
-	_, err := p.db.Exec(
-		query.PostPokemon,
-		input.Name,
-		input.BattleID,
-		input.Scores,
+func (p *PokeRepo) GetBattleEvents(BattleID int) (res []models.BattleEvent, err error) {
+	row, err := p.db.Query(
+		p.rebind(query.GetBattleEvents),
+		BattleID,
 	)
-
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
-}
-
-func (p *PokeRepo) GetBattle(start_time, end_time string) (res []models.BattleResponse, err error) {
-	var qry = query.GetBattle
+	defer row.Close()
 
-	if start_time != "" && end_time != "" {
-		qry += fmt.Sprintf(" WHERE start_time::date Between '%v' AND '%v' ", start_time, end_time)
+	for row.Next() {
+		temp := models.BattleEvent{}
+		err = row.Scan(
+			&temp.BattleID,
+			&temp.Turn,
+			&temp.Actor,
+			&temp.Target,
+			&temp.Move,
+			&temp.Damage,
+			&temp.RemainingHP,
+			&temp.Eliminated,
+		)
+		if err != nil {
+			return nil, err
+		}
 
+		res = append(res, temp)
+	}
+	if err = row.Err(); err != nil {
+		return nil, err
 	}
+	return res, nil
+}
+
+func (p *PokeRepo) GetBattle(search models.BattleSearch) (res []models.BattleResponse, err error) {
+	qry, args := battleSearchQuery(search)
 
 	row, err := p.db.Query(
-		qry,
+		p.rebind(qry),
+		args...,
 	)
 	if err != nil {
 		return nil, err
 	}
+	defer row.Close()
 
 	for row.Next() {
 		temp := models.BattleResponse{}
 		err = row.Scan(
 			&temp.BattleID,
 			&temp.Winner,
+			&temp.Seed,
+			&temp.Engine,
+			&temp.EngineVersion,
 		)
 		if err != nil {
 			return nil, err
@@ -112,24 +228,109 @@ func (p *PokeRepo) GetBattle(start_time, end_time string) (res []models.BattleRe
 
 		res = append(res, temp)
 	}
+	if err = row.Err(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetBattleWithPlayers is GetBattle with the participants of every battle,
+// read with one more query for all the battles rather than one per battle
+func (p *PokeRepo) GetBattleWithPlayers(search models.BattleSearch) (res []models.BattleResponse, err error) {
+	res, err = p.GetBattle(search)
+	if err != nil || len(res) == 0 {
+		return res, err
+	}
+
+	ids := make([]int64, len(res))
+	for i, battle := range res {
+		ids[i] = int64(battle.BattleID)
+	}
+
+	row, err := p.db.Query(
+		p.rebind(query.GetPlayersByBattles),
+		p.array(ids),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
 
+	players := make(map[int][]models.DetailPlayers, len(res))
+	for row.Next() {
+		var (
+			battleID int
+			temp     models.DetailPlayers
+		)
+		err = row.Scan(
+			&battleID,
+			&temp.Name,
+			&temp.Scores,
+			&temp.Placement,
+			&temp.Annulled,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		players[battleID] = append(players[battleID], temp)
+	}
+	if err = row.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range res {
+		res[i].Player = players[res[i].BattleID]
+	}
+	return res, nil
+}
+
+func (p *PokeRepo) GetBattleByID(BattleID int) (res models.Battle, err error) {
+	var roster string
+
+	err = p.db.QueryRow(
+		p.rebind(query.GetBattleByID),
+		BattleID,
+	).Scan(
+		&res.BattleID,
+		&res.Winner,
+		&res.Seed,
+		&roster,
+		&res.MovePolicy,
+		&res.Engine,
+		&res.EngineVersion,
+		&res.StartTime,
+		&res.EndTime,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return res, ErrBattleNotFound
+	}
+	if err != nil {
+		return res, err
+	}
+
+	res.Roster = strings.Split(roster, ",")
 	return res, nil
 }
 
 func (p *PokeRepo) GetPlayer(BattleID int) (res []models.DetailPlayers, err error) {
 	row, err := p.db.Query(
-		query.GetPlayers,
+		p.rebind(query.GetPlayers),
 		BattleID,
 	)
 	if err != nil {
 		return nil, err
 	}
+	defer row.Close()
 
 	for row.Next() {
 		temp := models.DetailPlayers{}
 		err = row.Scan(
 			&temp.Name,
 			&temp.Scores,
+			&temp.Placement,
+			&temp.Annulled,
 		)
 		if err != nil {
 			return nil, err
@@ -137,16 +338,105 @@ func (p *PokeRepo) GetPlayer(BattleID int) (res []models.DetailPlayers, err erro
 
 		res = append(res, temp)
 	}
+	if err = row.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetFinishOrder lists the participants in the order the engine finished
+// them, whatever was annulled afterwards
+func (p *PokeRepo) GetFinishOrder(BattleID int) (res []string, err error) {
+	row, err := p.db.Query(
+		p.rebind(query.GetFinishOrder),
+		BattleID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	for row.Next() {
+		var name string
+		if err = row.Scan(&name); err != nil {
+			return nil, err
+		}
+
+		res = append(res, name)
+	}
+	if err = row.Err(); err != nil {
+		return nil, err
+	}
 	return res, nil
 }
 
-func (p *PokeRepo) GetPokemonScore() (res []models.DetailPlayers, err error) {
+// AnnulPokemon marks the participant as annulled and takes it out of the
+// ranking with placement 0, moves everyone who finished below it up one place
+// with one more point and elects the new winner. The participant is locked
+// first, an annulled one gives ErrAlreadyAnnulled and changes nothing.
+func (p *PokeRepo) AnnulPokemon(BattleID int, name string) (err error) {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var (
+		placement int
+		annulled  bool
+	)
+	err = tx.QueryRow(
+		p.rebind(query.GetParticipantForUpdate),
+		BattleID,
+		name,
+	).Scan(&placement, &annulled)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrPokemonNotInBattle
+	}
+	if err != nil {
+		return err
+	}
+	if annulled {
+		err = ErrAlreadyAnnulled
+		return err
+	}
+
+	if _, err = tx.Exec(p.rebind(query.PromotePokemons), BattleID, placement); err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(p.rebind(query.AnnulPokemon), BattleID, name)
+	if err != nil {
+		return err
+	}
+	if err = expectOneRow(result, ErrAlreadyAnnulled); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(p.rebind(query.UpdateBattleWinner), BattleID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetPokemonScore sums the scores of every pokemon over the battles started
+// in r, highest first
+func (p *PokeRepo) GetPokemonScore(r models.ScoreRange) (res []models.DetailPlayers, err error) {
+	qry, args := pokemonScoreQuery(r)
+
 	row, err := p.db.Query(
-		query.GetPokemonScore,
+		p.rebind(qry),
+		args...,
 	)
 	if err != nil {
 		return nil, err
 	}
+	defer row.Close()
 
 	for row.Next() {
 		temp := models.DetailPlayers{}
@@ -159,6 +449,9 @@ func (p *PokeRepo) GetPokemonScore() (res []models.DetailPlayers, err error) {
 		}
 		res = append(res, temp)
 	}
+	if err = row.Err(); err != nil {
+		return nil, err
+	}
 
 	return res, nil
 }
@@ -1,67 +1,160 @@
 package repository
 
 import (
+	"database/sql"
 	"errors"
 	"log"
 	"pokemon/models"
+	"pokemon/repository/query"
 	"regexp"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-func Test_Post_PokemonBattle(t *testing.T) {
+func Test_Save_Battle(t *testing.T) {
 	type testCase struct {
 		name          string
 		wantError     bool
 		mockQuery     func(mock sqlmock.Sqlmock)
 		expectedError error
-		arg           models.BattleInput
+		expectedID    int64
 	}
 
 	var (
-		testTable     []testCase
-		expectedQuery = `
+		testTable   []testCase
+		battleQuery = `
+		WITH winner AS (
+			INSERT INTO species(name)
+			VALUES($1)
+			ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+			RETURNING species_id
+		)
 		INSERT INTO
-			battle(
-				winner,
+			battles(
+				winner_id,
+				seed,
+				roster,
+				move_policy,
+				engine,
+				engine_version,
 				start_time,
 				end_time
 			)
+		SELECT
+			winner.species_id, $2, $3, $4, $5, $6, $7, $8
+		FROM winner
+		RETURNING battle_id;
+	`
+		participantQuery = `
+		WITH participant AS (
+			INSERT INTO species(name)
+			VALUES($1)
+			ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+			RETURNING species_id
+		)
+		INSERT INTO
+			battle_participants(
+				species_id,
+				battle_id,
+				placement,
+				finish,
+				score
+			)
+		SELECT
+			participant.species_id, $2, $3, $3, $4
+		FROM participant
+	`
+		eventQuery = `
+		INSERT INTO
+			battle_events(
+				battle_id,
+				turn,
+				actor,
+				target,
+				move,
+				damage,
+				remaining_hp,
+				eliminated
+			)
 		VALUES(
-			$1, $2, $3
+			$1, $2, $3, $4, $5, $6, $7, $8
 		)
-		RETURNING battle_id;
-		`
+	`
+		input = models.BattleInput{
+			Winner:        "pikachu",
+			Seed:          42,
+			Roster:        []string{"pichu", "pikachu"},
+			MovePolicy:    "random",
+			Engine:        "stats",
+			EngineVersion: 1,
+			StartTime:     time.Now(),
+			EndTime:       time.Now(),
+		}
+		participants = []models.Participant{
+			{Name: "pikachu", Placement: 1, Scores: 2},
+			{Name: "pichu", Placement: 2, Scores: 1},
+		}
+		events = []models.BattleEvent{
+			{Turn: 1, Actor: "pikachu", Target: "pichu", Move: "thunderbolt", Damage: 20, RemainingHP: 0, Eliminated: true},
+		}
 	)
 
 	testTable = append(testTable, testCase{
-		name:      "failed unexpected error",
+		name:      "failed battle rolls back",
 		wantError: true,
 		mockQuery: func(mock sqlmock.Sqlmock) {
-			mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
-				WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(battleQuery)).
 				WillReturnError(errors.New("unexpected error"))
+			mock.ExpectRollback()
 		},
 		expectedError: errors.New("unexpected error"),
 	})
 
+	testTable = append(testTable, testCase{
+		name:      "failed participant rolls back the battle",
+		wantError: true,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(battleQuery)).
+				WithArgs("pikachu", 42, "pichu,pikachu", "random", "stats", 1, sqlmock.AnyArg(), sqlmock.AnyArg()).
+				WillReturnRows(sqlmock.NewRows([]string{"battle_id"}).AddRow(12))
+			mock.ExpectExec(regexp.QuoteMeta(participantQuery)).
+				WithArgs("pikachu", 12, 1, 2).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec(regexp.QuoteMeta(participantQuery)).
+				WithArgs("pichu", 12, 2, 1).
+				WillReturnError(errors.New("duplicate key value violates unique constraint"))
+			mock.ExpectRollback()
+		},
+		expectedError: errors.New("duplicate key value violates unique constraint"),
+	})
+
 	testTable = append(testTable, testCase{
 		name:      "success",
 		wantError: false,
-		arg: models.BattleInput{
-			Winner:    "ordinal",
-			StartTime: time.Now(),
-			EndTime:   time.Now(),
-		},
 		mockQuery: func(mock sqlmock.Sqlmock) {
-			mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
-				WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
-				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(battleQuery)).
+				WithArgs("pikachu", 42, "pichu,pikachu", "random", "stats", 1, sqlmock.AnyArg(), sqlmock.AnyArg()).
+				WillReturnRows(sqlmock.NewRows([]string{"battle_id"}).AddRow(12))
+			mock.ExpectExec(regexp.QuoteMeta(participantQuery)).
+				WithArgs("pikachu", 12, 1, 2).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec(regexp.QuoteMeta(participantQuery)).
+				WithArgs("pichu", 12, 2, 1).
+				WillReturnResult(sqlmock.NewResult(2, 1))
+			mock.ExpectExec(regexp.QuoteMeta(eventQuery)).
+				WithArgs(12, 1, "pikachu", "pichu", "thunderbolt", 20, 0, true).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectCommit()
 		},
+		expectedID: 12,
 	})
 
 	for _, tc := range testTable {
@@ -76,47 +169,51 @@ func Test_Post_PokemonBattle(t *testing.T) {
 				db: db,
 			}
 
-			_, serr := repo.PostBattlePokemon(tc.arg)
+			id, serr := repo.SaveBattle(input, participants, events, nil)
 			if tc.wantError {
-				log.Print(tc.name)
 				assert.EqualError(t, serr, tc.expectedError.Error())
+				assert.Equal(t, int64(0), id)
 			} else {
-				log.Print(tc.name)
 				assert.Nil(t, serr)
+				assert.Equal(t, tc.expectedID, id)
 			}
+			assert.NoError(t, mock.ExpectationsWereMet())
 		})
 	}
 }
 
-func Test_Post_PokemonData(t *testing.T) {
+func Test_Get_PokemonScore(t *testing.T) {
 	type testCase struct {
-		name          string
-		wantError     bool
-		mockQuery     func(mock sqlmock.Sqlmock)
-		expectedError error
-		arg           models.Pokemon
+		name           string
+		wantError      bool
+		input          models.ScoreRange
+		mockQuery      func(mock sqlmock.Sqlmock)
+		expectedError  error
+		expectedResult []models.DetailPlayers
 	}
 
 	var (
-		testTable     []testCase
-		expectedQuery = `			
-		INSERT INTO
-			pokemon(
-				name,
-				battle_id,
-				scores
-			)
-		VALUES(
-			$1, $2, $3
-		)	`
+		testTable []testCase
+		baseQuery = `
+		SELECT
+			s.name,
+			SUM(bp.score) AS scores
+		FROM battle_participants bp
+		JOIN species s ON s.species_id = bp.species_id
+		JOIN battles b ON b.battle_id = bp.battle_id
+	`
+		order = `
+GROUP BY s.name
+ORDER BY scores DESC, s.name`
+		from   = time.Date(2022, 10, 10, 0, 0, 0, 0, time.UTC)
+		before = time.Date(2022, 10, 17, 0, 0, 0, 0, time.UTC)
 	)
 
 	testTable = append(testTable, testCase{
 		name:      "failed unexpected error",
 		wantError: true,
 		mockQuery: func(mock sqlmock.Sqlmock) {
-			mock.ExpectExec(regexp.QuoteMeta(expectedQuery)).
-				WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			mock.ExpectQuery(regexp.QuoteMeta(baseQuery + `WHERE bp.annulled = false` + order)).
 				WillReturnError(errors.New("unexpected error"))
 		},
 		expectedError: errors.New("unexpected error"),
@@ -125,16 +222,36 @@ func Test_Post_PokemonData(t *testing.T) {
 	testTable = append(testTable, testCase{
 		name:      "success",
 		wantError: false,
-		arg: models.Pokemon{
-			PokemonID: 1,
-			Name:      "ahmad",
-			BattleID:  2,
-			Scores:    5,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(baseQuery + `WHERE bp.annulled = false` + order)).
+				WillReturnRows(sqlmock.NewRows([]string{"name", "scores"}).AddRow("pika", 2000).AddRow("chu", 1000))
 		},
+		expectedResult: []models.DetailPlayers{
+			{
+				Name:   "pika",
+				Scores: 2000,
+			},
+			{
+				Name:   "chu",
+				Scores: 1000,
+			},
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "success window",
+		wantError: false,
+		input:     models.ScoreRange{From: from, Before: before},
 		mockQuery: func(mock sqlmock.Sqlmock) {
-			mock.ExpectExec(regexp.QuoteMeta(expectedQuery)).
-				WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
-				WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectQuery(regexp.QuoteMeta(baseQuery+`WHERE bp.annulled = false AND b.start_time >= $1 AND b.start_time < $2`+order)).
+				WithArgs(from, before).
+				WillReturnRows(sqlmock.NewRows([]string{"name", "scores"}).AddRow("pika", 5))
+		},
+		expectedResult: []models.DetailPlayers{
+			{
+				Name:   "pika",
+				Scores: 5,
+			},
 		},
 	})
 
@@ -150,19 +267,19 @@ func Test_Post_PokemonData(t *testing.T) {
 				db: db,
 			}
 
-			serr := repo.PostPokemonData(tc.arg)
+			res, serr := repo.GetPokemonScore(tc.input)
 			if tc.wantError {
-				log.Print(tc.name)
 				assert.EqualError(t, serr, tc.expectedError.Error())
 			} else {
-				log.Print(tc.name)
 				assert.Nil(t, serr)
+				assert.Equal(t, tc.expectedResult, res)
 			}
+			assert.NoError(t, mock.ExpectationsWereMet())
 		})
 	}
 }
 
-func Test_Get_PokemonScore(t *testing.T) {
+func Test_Get_Player(t *testing.T) {
 	type testCase struct {
 		name           string
 		wantError      bool
@@ -175,13 +292,14 @@ func Test_Get_PokemonScore(t *testing.T) {
 		testTable     []testCase
 		expectedQuery = `
 		SELECT
- 			name,
-		SUM (scores) AS scores
-		FROM
-			pokemon
-		GROUP BY
-			name
-		ORDER BY scores desc;	
+			s.name,
+			bp.score,
+			bp.placement,
+			bp.annulled
+		FROM battle_participants bp
+		JOIN species s ON s.species_id = bp.species_id
+		WHERE bp.battle_id = $1
+		ORDER BY bp.annulled, bp.placement
 	`
 	)
 
@@ -200,16 +318,19 @@ func Test_Get_PokemonScore(t *testing.T) {
 		wantError: false,
 		mockQuery: func(mock sqlmock.Sqlmock) {
 			mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
-				WillReturnRows(sqlmock.NewRows([]string{"name", "scores"}).AddRow("pika", 2000).AddRow("chu", 1000))
+				WillReturnRows(sqlmock.NewRows([]string{"name", "scores", "placement", "annulled"}).AddRow("Guntur", 200, 1, false).AddRow("Kurniawan", 100, 2, true))
 		},
 		expectedResult: []models.DetailPlayers{
 			{
-				Name:   "pika",
-				Scores: 2000,
+				Name:      "Guntur",
+				Scores:    200,
+				Placement: 1,
 			},
 			{
-				Name:   "chu",
-				Scores: 1000,
+				Name:      "Kurniawan",
+				Scores:    100,
+				Placement: 2,
+				Annulled:  true,
 			},
 		},
 	})
@@ -226,10 +347,12 @@ func Test_Get_PokemonScore(t *testing.T) {
 				db: db,
 			}
 
-			res, serr := repo.GetPokemonScore()
+			res, serr := repo.GetPlayer(1)
 			if tc.wantError {
+				log.Print(tc.name)
 				assert.EqualError(t, serr, tc.expectedError.Error())
 			} else {
+				log.Print(tc.name)
 				assert.Nil(t, serr)
 				assert.Equal(t, tc.expectedResult, res)
 			}
@@ -237,23 +360,324 @@ func Test_Get_PokemonScore(t *testing.T) {
 	}
 }
 
-func Test_Get_Player(t *testing.T) {
+func Test_Get_Battle(t *testing.T) {
 	type testCase struct {
 		name           string
+		search         models.BattleSearch
 		wantError      bool
 		mockQuery      func(mock sqlmock.Sqlmock)
 		expectedError  error
-		expectedResult []models.DetailPlayers
+		expectedResult []models.BattleResponse
+	}
+
+	var (
+		testTable []testCase
+		columns   = []string{"battle_id", "winner", "seed", "engine", "engine_version"}
+		from      = time.Date(2022, 10, 12, 0, 0, 0, 0, time.UTC)
+		before    = time.Date(2022, 10, 13, 0, 0, 0, 0, time.UTC)
+		baseQuery = `
+		SELECT
+			b.battle_id,
+			s.name,
+			b.seed,
+			b.engine,
+			b.engine_version
+		FROM battles b
+		JOIN species s ON s.species_id = b.winner_id
+	`
+	)
+
+	testTable = append(testTable, testCase{
+		name:      "failed unexpected error",
+		wantError: true,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(baseQuery + `ORDER BY b.battle_id DESC`)).
+				WillReturnError(errors.New("unexpected error"))
+		},
+		expectedError: errors.New("unexpected error"),
+	})
+
+	testTable = append(testTable, testCase{
+		name: "success every filter",
+		search: models.BattleSearch{
+			StartFrom:       from,
+			StartBefore:     before,
+			Winner:          "pikachu",
+			Participant:     "pichu",
+			MinParticipants: 3,
+			Engine:          "stats",
+			AfterID:         40,
+			Limit:           11,
+		},
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(baseQuery+`WHERE b.start_time >= $1 AND b.start_time < $2 AND s.name = $3 AND EXISTS (
+			SELECT 1
+			FROM battle_participants bp
+			JOIN species ps ON ps.species_id = bp.species_id
+			WHERE bp.battle_id = b.battle_id AND ps.name = $4
+		) AND (
+			SELECT COUNT(*)
+			FROM battle_participants bp
+			WHERE bp.battle_id = b.battle_id
+		) >= $5 AND b.engine = $6 AND b.battle_id < $7
+ORDER BY b.battle_id DESC
+LIMIT $8`)).
+				WithArgs(from, before, "pikachu", "pichu", 3, "stats", 40, 11).
+				WillReturnRows(sqlmock.NewRows(columns).AddRow(39, "pikachu", 7, "stats", 1))
+		},
+		expectedResult: []models.BattleResponse{
+			{BattleID: 39, Winner: "pikachu", Seed: 7, Engine: "stats", EngineVersion: 1},
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:   "success open ended ascending",
+		search: models.BattleSearch{StartFrom: from, Ascending: true, AfterID: 1, Limit: 3},
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(baseQuery+`WHERE b.start_time >= $1 AND b.battle_id > $2
+ORDER BY b.battle_id ASC
+LIMIT $3`)).
+				WithArgs(from, 1, 3).
+				WillReturnRows(sqlmock.NewRows(columns).AddRow(2, "pikachu", 7, "stats", 1).AddRow(3, "pichu", 8, "legacy", 1))
+		},
+		expectedResult: []models.BattleResponse{
+			{BattleID: 2, Winner: "pikachu", Seed: 7, Engine: "stats", EngineVersion: 1},
+			{BattleID: 3, Winner: "pichu", Seed: 8, Engine: "legacy", EngineVersion: 1},
+		},
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+
+			if tc.mockQuery != nil {
+				tc.mockQuery(mock)
+			}
+			repo := PokeRepo{
+				db: db,
+			}
+
+			res, serr := repo.GetBattle(tc.search)
+			if tc.wantError {
+				assert.EqualError(t, serr, tc.expectedError.Error())
+			} else {
+				assert.Nil(t, serr)
+				assert.Equal(t, tc.expectedResult, res)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_Get_BattleWithPlayers(t *testing.T) {
+	type testCase struct {
+		name           string
+		wantError      bool
+		mockQuery      func(mock sqlmock.Sqlmock)
+		expectedError  error
+		expectedResult []models.BattleResponse
+	}
+
+	var (
+		testTable   []testCase
+		battleQuery = `
+		SELECT
+			b.battle_id,
+			s.name,
+			b.seed,
+			b.engine,
+			b.engine_version
+		FROM battles b
+		JOIN species s ON s.species_id = b.winner_id
+	ORDER BY b.battle_id DESC`
+		playersQuery = `
+		SELECT
+			bp.battle_id,
+			s.name,
+			bp.score,
+			bp.placement,
+			bp.annulled
+		FROM battle_participants bp
+		JOIN species s ON s.species_id = bp.species_id
+		WHERE bp.battle_id = ANY($1)
+		ORDER BY bp.battle_id, bp.annulled, bp.placement
+	`
+		battleColumns = []string{"battle_id", "winner", "seed", "engine", "engine_version"}
+		playerColumns = []string{"battle_id", "name", "score", "placement", "annulled"}
+	)
+
+	testTable = append(testTable, testCase{
+		name:      "failed unexpected error",
+		wantError: true,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(battleQuery)).
+				WillReturnRows(sqlmock.NewRows(battleColumns).AddRow(2, "pikachu", 7, "stats", 1))
+			mock.ExpectQuery(regexp.QuoteMeta(playersQuery)).
+				WillReturnError(errors.New("unexpected error"))
+		},
+		expectedError: errors.New("unexpected error"),
+	})
+
+	testTable = append(testTable, testCase{
+		name: "success without battles",
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(battleQuery)).
+				WillReturnRows(sqlmock.NewRows(battleColumns))
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name: "success",
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(battleQuery)).
+				WillReturnRows(sqlmock.NewRows(battleColumns).AddRow(3, "pichu", 8, "legacy", 1).AddRow(2, "pikachu", 7, "stats", 1))
+			mock.ExpectQuery(regexp.QuoteMeta(playersQuery)).
+				WithArgs(pq.Array([]int64{3, 2})).
+				WillReturnRows(sqlmock.NewRows(playerColumns).
+					AddRow(2, "pikachu", 2, 1, false).
+					AddRow(2, "eevee", 1, 2, false).
+					AddRow(3, "pichu", 2, 1, false).
+					AddRow(3, "ditto", 1, 2, true))
+		},
+		expectedResult: []models.BattleResponse{
+			{BattleID: 3, Winner: "pichu", Seed: 8, Engine: "legacy", EngineVersion: 1, Player: []models.DetailPlayers{
+				{Name: "pichu", Scores: 2, Placement: 1},
+				{Name: "ditto", Scores: 1, Placement: 2, Annulled: true},
+			}},
+			{BattleID: 2, Winner: "pikachu", Seed: 7, Engine: "stats", EngineVersion: 1, Player: []models.DetailPlayers{
+				{Name: "pikachu", Scores: 2, Placement: 1},
+				{Name: "eevee", Scores: 1, Placement: 2},
+			}},
+		},
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+
+			if tc.mockQuery != nil {
+				tc.mockQuery(mock)
+			}
+			repo := PokeRepo{
+				db: db,
+			}
+
+			res, serr := repo.GetBattleWithPlayers(models.BattleSearch{})
+			if tc.wantError {
+				assert.EqualError(t, serr, tc.expectedError.Error())
+			} else {
+				assert.Nil(t, serr)
+				assert.Equal(t, tc.expectedResult, res)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// Benchmark_ListBattles lists a page of 100 battles of two participants each,
+// every query answering after 1ms like a database across the network would,
+// once with a query per battle for its participants and once with them all
+// read together
+func Benchmark_ListBattles(b *testing.B) {
+	const (
+		battles = 100
+		latency = time.Millisecond
+	)
+
+	battleRows := func() *sqlmock.Rows {
+		rows := sqlmock.NewRows([]string{"battle_id", "winner", "seed", "engine", "engine_version"})
+		for id := battles; id > 0; id-- {
+			rows.AddRow(id, "pikachu", id, "stats", 1)
+		}
+		return rows
+	}
+
+	run := func(b *testing.B, expect func(mock sqlmock.Sqlmock), list func(repo *PokeRepo) ([]models.BattleResponse, error)) {
+		db, mock, err := sqlmock.New()
+		require.NoError(b, err)
+		defer db.Close()
+
+		repo := &PokeRepo{db: db}
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			expect(mock)
+			b.StartTimer()
+
+			res, err := list(repo)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if len(res) != battles || len(res[0].Player) != 2 {
+				b.Fatalf("unexpected battles %+v", res)
+			}
+		}
+	}
+
+	b.Run("query per battle", func(b *testing.B) {
+		run(b, func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(query.SearchBattles)).WillDelayFor(latency).WillReturnRows(battleRows())
+			for id := battles; id > 0; id-- {
+				mock.ExpectQuery(regexp.QuoteMeta(query.GetPlayers)).WithArgs(id).WillDelayFor(latency).
+					WillReturnRows(sqlmock.NewRows([]string{"name", "score", "placement", "annulled"}).
+						AddRow("pikachu", 2, 1, false).
+						AddRow("eevee", 1, 2, false))
+			}
+		}, func(repo *PokeRepo) ([]models.BattleResponse, error) {
+			res, err := repo.GetBattle(models.BattleSearch{})
+			if err != nil {
+				return nil, err
+			}
+			for i := range res {
+				if res[i].Player, err = repo.GetPlayer(res[i].BattleID); err != nil {
+					return nil, err
+				}
+			}
+			return res, nil
+		})
+	})
+
+	b.Run("batched", func(b *testing.B) {
+		run(b, func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(query.SearchBattles)).WillDelayFor(latency).WillReturnRows(battleRows())
+			rows := sqlmock.NewRows([]string{"battle_id", "name", "score", "placement", "annulled"})
+			for id := 1; id <= battles; id++ {
+				rows.AddRow(id, "pikachu", 2, 1, false).AddRow(id, "eevee", 1, 2, false)
+			}
+			mock.ExpectQuery(regexp.QuoteMeta(query.GetPlayersByBattles)).WillDelayFor(latency).WillReturnRows(rows)
+		}, func(repo *PokeRepo) ([]models.BattleResponse, error) {
+			return repo.GetBattleWithPlayers(models.BattleSearch{})
+		})
+	})
+}
+
+func Test_Get_BattleByID(t *testing.T) {
+	type testCase struct {
+		name           string
+		wantError      bool
+		mockQuery      func(mock sqlmock.Sqlmock)
+		expectedError  error
+		expectedResult models.Battle
 	}
 
 	var (
 		testTable     []testCase
+		now           = time.Now()
 		expectedQuery = `
-		SELECT 
-			p.name,
-			p.scores
-		FROM pokemon p 
-		WHERE p.battle_id = $1
+		SELECT
+			b.battle_id,
+			s.name,
+			b.seed,
+			b.roster,
+			b.move_policy,
+			b.engine,
+			b.engine_version,
+			b.start_time,
+			b.end_time
+		FROM battles b
+		JOIN species s ON s.species_id = b.winner_id
+		WHERE b.battle_id = $1
 	`
 	)
 
@@ -262,27 +686,42 @@ func Test_Get_Player(t *testing.T) {
 		wantError: true,
 		mockQuery: func(mock sqlmock.Sqlmock) {
 			mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+				WithArgs(1).
 				WillReturnError(errors.New("unexpected error"))
 		},
 		expectedError: errors.New("unexpected error"),
 	})
 
+	testTable = append(testTable, testCase{
+		name:      "failed not found",
+		wantError: true,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+				WithArgs(1).
+				WillReturnRows(sqlmock.NewRows([]string{"battle_id", "winner", "seed", "roster", "move_policy", "engine", "engine_version", "start_time", "end_time"}))
+		},
+		expectedError: ErrBattleNotFound,
+	})
+
 	testTable = append(testTable, testCase{
 		name:      "success",
 		wantError: false,
 		mockQuery: func(mock sqlmock.Sqlmock) {
 			mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
-				WillReturnRows(sqlmock.NewRows([]string{"name", "scores"}).AddRow("Guntur", 200).AddRow("Kurniawan	", 100))
+				WithArgs(1).
+				WillReturnRows(sqlmock.NewRows([]string{"battle_id", "winner", "seed", "roster", "move_policy", "engine", "engine_version", "start_time", "end_time"}).
+					AddRow(1, "pikachu", 42, "pikachu,pichu", "highest_damage", "type_aware", 1, now, now))
 		},
-		expectedResult: []models.DetailPlayers{
-			{
-				Name:   "Guntur",
-				Scores: 200,
-			},
-			{
-				Name:   "Kurniawan",
-				Scores: 100,
-			},
+		expectedResult: models.Battle{
+			BattleID:      1,
+			Winner:        "pikachu",
+			Seed:          42,
+			Roster:        []string{"pikachu", "pichu"},
+			MovePolicy:    "highest_damage",
+			Engine:        "type_aware",
+			EngineVersion: 1,
+			StartTime:     now,
+			EndTime:       now,
 		},
 	})
 
@@ -298,12 +737,10 @@ func Test_Get_Player(t *testing.T) {
 				db: db,
 			}
 
-			res, serr := repo.GetPlayer(1)
+			res, serr := repo.GetBattleByID(1)
 			if tc.wantError {
-				log.Print(tc.name)
 				assert.EqualError(t, serr, tc.expectedError.Error())
 			} else {
-				log.Print(tc.name)
 				assert.Nil(t, serr)
 				assert.Equal(t, tc.expectedResult, res)
 			}
@@ -311,22 +748,30 @@ func Test_Get_Player(t *testing.T) {
 	}
 }
 
-func Test_Get_Battle(t *testing.T) {
+func Test_Get_BattleEvents(t *testing.T) {
 	type testCase struct {
 		name           string
 		wantError      bool
 		mockQuery      func(mock sqlmock.Sqlmock)
 		expectedError  error
-		expectedResult []models.BattleResponse
+		expectedResult []models.BattleEvent
 	}
 
 	var (
 		testTable     []testCase
 		expectedQuery = `
-		SELECT 
-			b.battle_id, 
-			b.winner
-		FROM battle b
+		SELECT
+			e.battle_id,
+			e.turn,
+			e.actor,
+			e.target,
+			e.move,
+			e.damage,
+			e.remaining_hp,
+			e.eliminated
+		FROM battle_events e
+		WHERE e.battle_id = $1
+		ORDER BY e.event_id
 	`
 	)
 
@@ -340,22 +785,46 @@ func Test_Get_Battle(t *testing.T) {
 		expectedError: errors.New("unexpected error"),
 	})
 
+	testTable = append(testTable, testCase{
+		name:      "failed scan closes the rows",
+		wantError: true,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+				WithArgs(1).
+				WillReturnRows(sqlmock.NewRows([]string{"battle_id", "turn", "actor", "target", "move", "damage", "remaining_hp", "eliminated"}).
+					AddRow(1, "first", "pikachu", "pichu", "thunderbolt", 20, 10, false).
+					AddRow(1, 2, "pikachu", "pichu", "thunderbolt", 20, 0, true)).
+				RowsWillBeClosed()
+		},
+		expectedError: errors.New(`sql: Scan error on column index 1, name "turn": converting driver.Value type string ("first") to a int: invalid syntax`),
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "failed row error",
+		wantError: true,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+				WithArgs(1).
+				WillReturnRows(sqlmock.NewRows([]string{"battle_id", "turn", "actor", "target", "move", "damage", "remaining_hp", "eliminated"}).
+					AddRow(1, 1, "pikachu", "pichu", "thunderbolt", 20, 10, false).
+					RowError(0, errors.New("connection reset")))
+		},
+		expectedError: errors.New("connection reset"),
+	})
+
 	testTable = append(testTable, testCase{
 		name:      "success",
 		wantError: false,
 		mockQuery: func(mock sqlmock.Sqlmock) {
 			mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
-				WillReturnRows(sqlmock.NewRows([]string{"battle_id", "winner"}).AddRow(1, "pikachu").AddRow(2, "pichu"))
+				WithArgs(1).
+				WillReturnRows(sqlmock.NewRows([]string{"battle_id", "turn", "actor", "target", "move", "damage", "remaining_hp", "eliminated"}).
+					AddRow(1, 1, "pikachu", "pichu", "thunderbolt", 20, 10, false).
+					AddRow(1, 2, "pikachu", "pichu", "thunderbolt", 20, 0, true))
 		},
-		expectedResult: []models.BattleResponse{
-			{
-				BattleID: 1,
-				Winner:   "pikachu",
-			},
-			{
-				BattleID: 2,
-				Winner:   "pichu",
-			},
+		expectedResult: []models.BattleEvent{
+			{BattleID: 1, Turn: 1, Actor: "pikachu", Target: "pichu", Move: "thunderbolt", Damage: 20, RemainingHP: 10},
+			{BattleID: 1, Turn: 2, Actor: "pikachu", Target: "pichu", Move: "thunderbolt", Damage: 20, RemainingHP: 0, Eliminated: true},
 		},
 	})
 
@@ -371,15 +840,148 @@ func Test_Get_Battle(t *testing.T) {
 				db: db,
 			}
 
-			res, serr := repo.GetBattle("2022-10-12", "2022-10-12")
+			res, serr := repo.GetBattleEvents(1)
 			if tc.wantError {
-				log.Print(tc.name)
 				assert.EqualError(t, serr, tc.expectedError.Error())
 			} else {
-				log.Print(tc.name)
 				assert.Nil(t, serr)
 				assert.Equal(t, tc.expectedResult, res)
 			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_Annul_Pokemon(t *testing.T) {
+	type testCase struct {
+		name          string
+		wantError     bool
+		mockQuery     func(mock sqlmock.Sqlmock)
+		expectedError error
+	}
+
+	var (
+		testTable []testCase
+		lockQuery = `
+		SELECT
+			bp.placement,
+			bp.annulled
+		FROM battle_participants bp
+		WHERE bp.battle_id = $1 AND bp.species_id = (
+			SELECT species_id FROM species WHERE name = $2
+		)
+		FOR UPDATE
+	`
+		promoteQuery = `
+		UPDATE battle_participants
+		SET score = score + 1, placement = placement - 1
+		WHERE battle_id = $1 AND annulled = false AND placement > $2
+	`
+		annulQuery = `
+		UPDATE battle_participants
+		SET annulled = true, placement = 0
+		WHERE battle_id = $1 AND annulled = false AND species_id = (
+			SELECT species_id FROM species WHERE name = $2
+		)
+	`
+		winnerQuery = `
+		UPDATE battles
+		SET winner_id = (
+			SELECT bp.species_id
+			FROM battle_participants bp
+			WHERE bp.battle_id = $1 AND bp.annulled = false
+			ORDER BY bp.placement
+			LIMIT 1
+		)
+		WHERE battle_id = $1
+	`
+	)
+
+	testTable = append(testTable, testCase{
+		name:      "failed not in battle",
+		wantError: true,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(lockQuery)).
+				WithArgs(1, "pikachu").
+				WillReturnError(sql.ErrNoRows)
+			mock.ExpectRollback()
+		},
+		expectedError: ErrPokemonNotInBattle,
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "failed already annulled",
+		wantError: true,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(lockQuery)).
+				WithArgs(1, "pikachu").
+				WillReturnRows(sqlmock.NewRows([]string{"placement", "annulled"}).AddRow(0, true))
+			mock.ExpectRollback()
+		},
+		expectedError: ErrAlreadyAnnulled,
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "failed unexpected error rolls back",
+		wantError: true,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(lockQuery)).
+				WithArgs(1, "pikachu").
+				WillReturnRows(sqlmock.NewRows([]string{"placement", "annulled"}).AddRow(2, false))
+			mock.ExpectExec(regexp.QuoteMeta(promoteQuery)).
+				WithArgs(1, 2).
+				WillReturnResult(sqlmock.NewResult(0, 3))
+			mock.ExpectExec(regexp.QuoteMeta(annulQuery)).
+				WithArgs(1, "pikachu").
+				WillReturnError(errors.New("unexpected error"))
+			mock.ExpectRollback()
+		},
+		expectedError: errors.New("unexpected error"),
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "success",
+		wantError: false,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(lockQuery)).
+				WithArgs(1, "pikachu").
+				WillReturnRows(sqlmock.NewRows([]string{"placement", "annulled"}).AddRow(2, false))
+			mock.ExpectExec(regexp.QuoteMeta(promoteQuery)).
+				WithArgs(1, 2).
+				WillReturnResult(sqlmock.NewResult(0, 3))
+			mock.ExpectExec(regexp.QuoteMeta(annulQuery)).
+				WithArgs(1, "pikachu").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta(winnerQuery)).
+				WithArgs(1).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+		},
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+
+			if tc.mockQuery != nil {
+				tc.mockQuery(mock)
+			}
+			repo := PokeRepo{
+				db: db,
+			}
+
+			serr := repo.AnnulPokemon(1, "pikachu")
+			if tc.wantError {
+				assert.EqualError(t, serr, tc.expectedError.Error())
+			} else {
+				assert.Nil(t, serr)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
 		})
 	}
 }
@@ -0,0 +1,30 @@
+package query
+
+const (
+	GetPokeAPICache = `
+		SELECT
+			c.url,
+			c.etag,
+			c.body,
+			c.fetched_at
+		FROM pokeapi_cache c
+		WHERE c.url = $1
+	`
+
+	UpsertPokeAPICache = `
+		INSERT INTO
+			pokeapi_cache(
+				url,
+				etag,
+				body,
+				fetched_at
+			)
+		VALUES(
+			$1, $2, $3, $4
+		)
+		ON CONFLICT (url) DO UPDATE SET
+			etag = EXCLUDED.etag,
+			body = EXCLUDED.body,
+			fetched_at = EXCLUDED.fetched_at
+	`
+)
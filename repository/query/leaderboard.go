@@ -0,0 +1,18 @@
+package query
+
+// PokemonScore is bounded by the start_time conditions of SearchBattles
+const (
+	PokemonScore = `
+		SELECT
+			s.name,
+			SUM(bp.score) AS scores
+		FROM battle_participants bp
+		JOIN species s ON s.species_id = bp.species_id
+		JOIN battles b ON b.battle_id = bp.battle_id
+	`
+
+	ScoreNotAnnulled = `bp.annulled = false`
+
+	ScoreOrder = `GROUP BY s.name
+ORDER BY scores DESC, s.name`
+)
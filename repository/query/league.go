@@ -0,0 +1,83 @@
+package query
+
+const (
+	PostLeague = `
+		INSERT INTO
+			league(
+				name,
+				seed,
+				round,
+				rounds,
+				points_win,
+				points_draw,
+				points_loss,
+				tie_breakers,
+				created_at
+			)
+		VALUES(
+			$1, $2, $3, $4, $5, $6, $7, $8, $9
+		)
+		RETURNING league_id;
+	`
+
+	AdvanceLeague = `
+		UPDATE league
+		SET round = round + 1
+		WHERE league_id = $1 AND round = $2
+	`
+
+	GetLeague = `
+		SELECT
+			l.league_id,
+			l.name,
+			l.seed,
+			l.round,
+			l.rounds,
+			l.points_win,
+			l.points_draw,
+			l.points_loss,
+			l.tie_breakers,
+			l.created_at
+		FROM league l
+		WHERE l.league_id = $1
+	`
+
+	PostLeagueFixture = `
+		INSERT INTO
+			league_fixture(
+				league_id,
+				round,
+				pokemon_a,
+				pokemon_b,
+				played,
+				winner,
+				draw,
+				battle_id
+			)
+		VALUES(
+			$1, $2, $3, $4, $5, $6, $7, NULLIF($8, 0)
+		)
+	`
+
+	UpdateLeagueFixture = `
+		UPDATE league_fixture
+		SET played = true, winner = $2, draw = $3, battle_id = $4
+		WHERE fixture_id = $1 AND played = false
+	`
+
+	GetLeagueFixtures = `
+		SELECT
+			f.fixture_id,
+			f.league_id,
+			f.round,
+			f.pokemon_a,
+			f.pokemon_b,
+			f.played,
+			f.winner,
+			f.draw,
+			COALESCE(f.battle_id, 0)
+		FROM league_fixture f
+		WHERE f.league_id = $1
+		ORDER BY f.round, f.fixture_id
+	`
+)
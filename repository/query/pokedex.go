@@ -0,0 +1,86 @@
+package query
+
+const (
+	GetPokedexSpecies = `
+		SELECT
+			s.name
+		FROM pokedex_species s
+		ORDER BY s.position
+	`
+
+	GetPokedexSpeciesByName = `
+		SELECT
+			s.id,
+			s.name
+		FROM pokedex_species s
+		WHERE s.name = $1
+	`
+
+	GetPokedexSpeciesByID = `
+		SELECT
+			s.id,
+			s.name
+		FROM pokedex_species s
+		WHERE s.id = $1::int
+	`
+
+	GetPokedexStats = `
+		SELECT
+			st.stat,
+			st.base_stat,
+			st.effort
+		FROM pokedex_stats st
+		WHERE st.name = $1
+		ORDER BY st.stat_id
+	`
+
+	GetPokedexTypes = `
+		SELECT
+			t.slot,
+			t.type
+		FROM pokedex_types t
+		WHERE t.name = $1
+		ORDER BY t.slot
+	`
+
+	ClearPokedex = `
+		TRUNCATE pokedex_species, pokedex_stats, pokedex_types RESTART IDENTITY
+	`
+
+	PostPokedexSpecies = `
+		INSERT INTO
+			pokedex_species(
+				name,
+				id,
+				position
+			)
+		VALUES(
+			$1, $2, $3
+		)
+	`
+
+	PostPokedexStat = `
+		INSERT INTO
+			pokedex_stats(
+				name,
+				stat,
+				base_stat,
+				effort
+			)
+		VALUES(
+			$1, $2, $3, $4
+		)
+	`
+
+	PostPokedexType = `
+		INSERT INTO
+			pokedex_types(
+				name,
+				slot,
+				type
+			)
+		VALUES(
+			$1, $2, $3
+		)
+	`
+)
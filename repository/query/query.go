@@ -1,54 +1,166 @@
 package query
 
 const (
-	PostBattle = `			
+	PostBattle = `
+		WITH winner AS (
+			INSERT INTO species(name)
+			VALUES($1)
+			ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+			RETURNING species_id
+		)
 		INSERT INTO
-			battle(
-				winner,
+			battles(
+				winner_id,
+				seed,
+				roster,
+				move_policy,
+				engine,
+				engine_version,
 				start_time,
 				end_time
 			)
-		VALUES(
-			$1, $2, $3
-		)
+		SELECT
+			winner.species_id, $2, $3, $4, $5, $6, $7, $8
+		FROM winner
 		RETURNING battle_id;
 	`
 
-	PostPokemon = `			
+	PostParticipant = `
+		WITH participant AS (
+			INSERT INTO species(name)
+			VALUES($1)
+			ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+			RETURNING species_id
+		)
+		INSERT INTO
+			battle_participants(
+				species_id,
+				battle_id,
+				placement,
+				finish,
+				score
+			)
+		SELECT
+			participant.species_id, $2, $3, $3, $4
+		FROM participant
+	`
+
+	GetBattleByID = `
+		SELECT
+			b.battle_id,
+			s.name,
+			b.seed,
+			b.roster,
+			b.move_policy,
+			b.engine,
+			b.engine_version,
+			b.start_time,
+			b.end_time
+		FROM battles b
+		JOIN species s ON s.species_id = b.winner_id
+		WHERE b.battle_id = $1
+	`
+
+	GetPlayers = `
+		SELECT
+			s.name,
+			bp.score,
+			bp.placement,
+			bp.annulled
+		FROM battle_participants bp
+		JOIN species s ON s.species_id = bp.species_id
+		WHERE bp.battle_id = $1
+		ORDER BY bp.annulled, bp.placement
+	`
+
+	GetFinishOrder = `
+		SELECT
+			s.name
+		FROM battle_participants bp
+		JOIN species s ON s.species_id = bp.species_id
+		WHERE bp.battle_id = $1
+		ORDER BY bp.finish
+	`
+
+	GetPlayersByBattles = `
+		SELECT
+			bp.battle_id,
+			s.name,
+			bp.score,
+			bp.placement,
+			bp.annulled
+		FROM battle_participants bp
+		JOIN species s ON s.species_id = bp.species_id
+		WHERE bp.battle_id = ANY($1)
+		ORDER BY bp.battle_id, bp.annulled, bp.placement
+	`
+
+	PostBattleEvent = `
 		INSERT INTO
-			pokemon(
-				name,
+			battle_events(
 				battle_id,
-				scores
+				turn,
+				actor,
+				target,
+				move,
+				damage,
+				remaining_hp,
+				eliminated
 			)
 		VALUES(
-			$1, $2, $3
+			$1, $2, $3, $4, $5, $6, $7, $8
 		)
 	`
 
-	GetBattle = `
-		SELECT 
-			b.battle_id, 
-			b.winner
-		FROM battle b 
+	GetBattleEvents = `
+		SELECT
+			e.battle_id,
+			e.turn,
+			e.actor,
+			e.target,
+			e.move,
+			e.damage,
+			e.remaining_hp,
+			e.eliminated
+		FROM battle_events e
+		WHERE e.battle_id = $1
+		ORDER BY e.event_id
 	`
 
-	GetPlayers = `
-		SELECT 
-			p.name,
-			p.scores
-		FROM pokemon p 
-		WHERE p.battle_id = $1
-	`
-
-	GetPokemonScore = `
-		SELECT
- 			name,
-		SUM (scores) AS scores
-		FROM
-			pokemon
-		GROUP BY
-			name
-		ORDER BY scores desc;	
+	GetParticipantForUpdate = `
+		SELECT
+			bp.placement,
+			bp.annulled
+		FROM battle_participants bp
+		WHERE bp.battle_id = $1 AND bp.species_id = (
+			SELECT species_id FROM species WHERE name = $2
+		)
+		FOR UPDATE
+	`
+
+	PromotePokemons = `
+		UPDATE battle_participants
+		SET score = score + 1, placement = placement - 1
+		WHERE battle_id = $1 AND annulled = false AND placement > $2
+	`
+
+	AnnulPokemon = `
+		UPDATE battle_participants
+		SET annulled = true, placement = 0
+		WHERE battle_id = $1 AND annulled = false AND species_id = (
+			SELECT species_id FROM species WHERE name = $2
+		)
+	`
+
+	UpdateBattleWinner = `
+		UPDATE battles
+		SET winner_id = (
+			SELECT bp.species_id
+			FROM battle_participants bp
+			WHERE bp.battle_id = $1 AND bp.annulled = false
+			ORDER BY bp.placement
+			LIMIT 1
+		)
+		WHERE battle_id = $1
 	`
 )
@@ -0,0 +1,82 @@
+package query
+
+const (
+	GetRatings = `
+		SELECT
+			r.name,
+			r.rating,
+			r.rd,
+			r.volatility,
+			r.games,
+			r.updated_at
+		FROM rating r
+		ORDER BY r.rating DESC, r.name
+	`
+
+	PostUnratedRating = `
+		INSERT INTO
+			rating(
+				name,
+				rating,
+				rd,
+				volatility,
+				games,
+				updated_at
+			)
+		VALUES(
+			$1, 0, 0, 0, 0, $2
+		)
+		ON CONFLICT (name) DO NOTHING
+	`
+
+	GetRatingsForUpdate = `
+		SELECT
+			r.name,
+			r.rating,
+			r.rd,
+			r.volatility,
+			r.games,
+			r.updated_at
+		FROM rating r
+		WHERE r.name = ANY($1) AND r.games > 0
+		ORDER BY r.name
+		FOR UPDATE
+	`
+
+	UpsertRating = `
+		INSERT INTO
+			rating(
+				name,
+				rating,
+				rd,
+				volatility,
+				games,
+				updated_at
+			)
+		VALUES(
+			$1, $2, $3, $4, $5, $6
+		)
+		ON CONFLICT (name) DO UPDATE SET
+			rating = EXCLUDED.rating,
+			rd = EXCLUDED.rd,
+			volatility = EXCLUDED.volatility,
+			games = EXCLUDED.games,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	PostRatingHistory = `
+		INSERT INTO
+			rating_history(
+				battle_id,
+				name,
+				rating_before,
+				rating_after,
+				rd_before,
+				rd_after,
+				created_at
+			)
+		VALUES(
+			$1, $2, $3, $4, $5, $6, $7
+		)
+	`
+)
@@ -0,0 +1,47 @@
+package query
+
+// The conditions of SearchBattles take their argument as ?, the search
+// builder numbers them
+const (
+	SearchBattles = `
+		SELECT
+			b.battle_id,
+			s.name,
+			b.seed,
+			b.engine,
+			b.engine_version
+		FROM battles b
+		JOIN species s ON s.species_id = b.winner_id
+	`
+
+	BattleStartFrom = `b.start_time >= ?`
+
+	BattleStartBefore = `b.start_time < ?`
+
+	BattleWinner = `s.name = ?`
+
+	BattleParticipant = `EXISTS (
+			SELECT 1
+			FROM battle_participants bp
+			JOIN species ps ON ps.species_id = bp.species_id
+			WHERE bp.battle_id = b.battle_id AND ps.name = ?
+		)`
+
+	BattleMinParticipants = `(
+			SELECT COUNT(*)
+			FROM battle_participants bp
+			WHERE bp.battle_id = b.battle_id
+		) >= ?`
+
+	BattleEngine = `b.engine = ?`
+
+	BattleAfterAsc = `b.battle_id > ?`
+
+	BattleAfterDesc = `b.battle_id < ?`
+
+	BattleOrderAsc = `ORDER BY b.battle_id ASC`
+
+	BattleOrderDesc = `ORDER BY b.battle_id DESC`
+
+	BattleLimit = `LIMIT ?`
+)
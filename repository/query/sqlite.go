@@ -0,0 +1,50 @@
+package query
+
+// SQLite can't insert inside a WITH, the species is inserted first and the
+// battle or the participant looks it up by name
+const (
+	PostSpeciesSQLite = `
+		INSERT INTO
+			species(
+				name
+			)
+		VALUES(
+			$1
+		)
+		ON CONFLICT (name) DO NOTHING
+	`
+
+	PostBattleSQLite = `
+		INSERT INTO
+			battles(
+				winner_id,
+				seed,
+				roster,
+				move_policy,
+				engine,
+				engine_version,
+				start_time,
+				end_time
+			)
+		SELECT
+			s.species_id, $2, $3, $4, $5, $6, $7, $8
+		FROM species s
+		WHERE s.name = $1
+		RETURNING battle_id;
+	`
+
+	PostParticipantSQLite = `
+		INSERT INTO
+			battle_participants(
+				species_id,
+				battle_id,
+				placement,
+				finish,
+				score
+			)
+		SELECT
+			s.species_id, $2, $3, $3, $4
+		FROM species s
+		WHERE s.name = $1
+	`
+)
@@ -0,0 +1,75 @@
+package query
+
+const (
+	PostTournament = `
+		INSERT INTO
+			tournament(
+				name,
+				size,
+				seed,
+				round,
+				winner,
+				created_at
+			)
+		VALUES(
+			$1, $2, $3, $4, $5, $6
+		)
+		RETURNING tournament_id;
+	`
+
+	AdvanceTournament = `
+		UPDATE tournament
+		SET round = $3, winner = $4
+		WHERE tournament_id = $1 AND round = $2 AND winner = ''
+	`
+
+	GetTournament = `
+		SELECT
+			t.tournament_id,
+			t.name,
+			t.size,
+			t.seed,
+			t.round,
+			t.winner,
+			t.created_at
+		FROM tournament t
+		WHERE t.tournament_id = $1
+	`
+
+	PostTournamentMatch = `
+		INSERT INTO
+			tournament_match(
+				tournament_id,
+				round,
+				slot,
+				pokemon_a,
+				pokemon_b,
+				winner,
+				battle_id
+			)
+		VALUES(
+			$1, $2, $3, $4, $5, $6, NULLIF($7, 0)
+		)
+	`
+
+	UpdateTournamentMatch = `
+		UPDATE tournament_match
+		SET winner = $2, battle_id = $3
+		WHERE match_id = $1 AND winner = ''
+	`
+
+	GetTournamentMatches = `
+		SELECT
+			m.match_id,
+			m.tournament_id,
+			m.round,
+			m.slot,
+			m.pokemon_a,
+			m.pokemon_b,
+			m.winner,
+			COALESCE(m.battle_id, 0)
+		FROM tournament_match m
+		WHERE m.tournament_id = $1
+		ORDER BY m.round, m.slot
+	`
+)
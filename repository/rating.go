@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"database/sql"
+	"pokemon/models"
+	"pokemon/repository/query"
+	"sort"
+	"time"
+)
+
+func (p *PokeRepo) GetRatings() (res []models.Rating, err error) {
+	row, err := p.db.Query(
+		p.rebind(query.GetRatings),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	for row.Next() {
+		temp := models.Rating{}
+		err = row.Scan(
+			&temp.Name,
+			&temp.Rating,
+			&temp.RD,
+			&temp.Volatility,
+			&temp.Games,
+			&temp.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, temp)
+	}
+	if err = row.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Rater rates the pokemons of a battle from the ratings they have, a pokemon
+// that never fought has none. before and after are matched by index.
+type Rater func(current []models.Rating) (before, after []models.Rating)
+
+// rateBattle rates the pokemons of a battle in tx together with the history
+// entries. Their rating rows are locked, in name order, before they are read:
+// a pokemon that never fought gets an unrated row first so that two battles
+// sharing a pokemon are rated one after the other.
+func (p *PokeRepo) rateBattle(tx *sql.Tx, BattleID int64, names []string, rate Rater) (err error) {
+	names = append([]string(nil), names...)
+	sort.Strings(names)
+
+	now := time.Now().UTC()
+	for _, name := range names {
+		if _, err = tx.Exec(p.rebind(query.PostUnratedRating), name, now); err != nil {
+			return err
+		}
+	}
+
+	row, err := tx.Query(
+		p.rebind(query.GetRatingsForUpdate),
+		p.array(names),
+	)
+	if err != nil {
+		return err
+	}
+	defer row.Close()
+
+	var current []models.Rating
+	for row.Next() {
+		temp := models.Rating{}
+		err = row.Scan(
+			&temp.Name,
+			&temp.Rating,
+			&temp.RD,
+			&temp.Volatility,
+			&temp.Games,
+			&temp.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		current = append(current, temp)
+	}
+	if err = row.Close(); err != nil {
+		return err
+	}
+
+	before, after := rate(current)
+	for i, rating := range after {
+		_, err = tx.Exec(
+			p.rebind(query.UpsertRating),
+			rating.Name,
+			rating.Rating,
+			rating.RD,
+			rating.Volatility,
+			rating.Games,
+			rating.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(
+			p.rebind(query.PostRatingHistory),
+			BattleID,
+			rating.Name,
+			before[i].Rating,
+			rating.Rating,
+			before[i].RD,
+			rating.RD,
+			rating.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"errors"
+	"pokemon/models"
+	"pokemon/repository/query"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Save_Battle_Ratings(t *testing.T) {
+	type testCase struct {
+		name          string
+		wantError     bool
+		mockQuery     func(mock sqlmock.Sqlmock)
+		expectedError error
+		expectedRated []models.Rating
+	}
+
+	var (
+		testTable  []testCase
+		now        = time.Now()
+		unratedQry = `
+		INSERT INTO
+			rating(
+				name,
+				rating,
+				rd,
+				volatility,
+				games,
+				updated_at
+			)
+		VALUES(
+			$1, 0, 0, 0, 0, $2
+		)
+		ON CONFLICT (name) DO NOTHING
+	`
+		lockQuery = `
+		SELECT
+			r.name,
+			r.rating,
+			r.rd,
+			r.volatility,
+			r.games,
+			r.updated_at
+		FROM rating r
+		WHERE r.name = ANY($1) AND r.games > 0
+		ORDER BY r.name
+		FOR UPDATE
+	`
+		upsertQuery = `
+		INSERT INTO
+			rating(
+				name,
+				rating,
+				rd,
+				volatility,
+				games,
+				updated_at
+			)
+		VALUES(
+			$1, $2, $3, $4, $5, $6
+		)
+		ON CONFLICT (name) DO UPDATE SET
+			rating = EXCLUDED.rating,
+			rd = EXCLUDED.rd,
+			volatility = EXCLUDED.volatility,
+			games = EXCLUDED.games,
+			updated_at = EXCLUDED.updated_at
+	`
+		historyQuery = `
+		INSERT INTO
+			rating_history(
+				battle_id,
+				name,
+				rating_before,
+				rating_after,
+				rd_before,
+				rd_after,
+				created_at
+			)
+		VALUES(
+			$1, $2, $3, $4, $5, $6, $7
+		)
+	`
+		input        = models.BattleInput{Winner: "pikachu", StartTime: now, EndTime: now}
+		participants = []models.Participant{
+			{Name: "pikachu", Placement: 1, Scores: 2},
+			{Name: "eevee", Placement: 2, Scores: 1},
+		}
+		pikachu = models.Rating{Name: "pikachu", Rating: 1550.5, RD: 120.25, Volatility: 0.06, Games: 4, UpdatedAt: now}
+		fresh   = models.Rating{Name: "eevee", Rating: 1500, RD: 350, Volatility: 0.06}
+		after   = []models.Rating{
+			{Name: "pikachu", Rating: 1562.5, RD: 118.5, Volatility: 0.06, Games: 5, UpdatedAt: now},
+			{Name: "eevee", Rating: 1337.5, RD: 290.25, Volatility: 0.06, Games: 1, UpdatedAt: now},
+		}
+		saveBattle = func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(query.PostBattle)).
+				WillReturnRows(sqlmock.NewRows([]string{"battle_id"}).AddRow(5))
+			mock.ExpectExec(regexp.QuoteMeta(query.PostParticipant)).
+				WithArgs("pikachu", 5, 1, 2).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec(regexp.QuoteMeta(query.PostParticipant)).
+				WithArgs("eevee", 5, 2, 1).
+				WillReturnResult(sqlmock.NewResult(2, 1))
+			mock.ExpectExec(regexp.QuoteMeta(unratedQry)).
+				WithArgs("eevee", sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta(unratedQry)).
+				WithArgs("pikachu", sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+		}
+	)
+
+	testTable = append(testTable, testCase{
+		name:      "failed lock rolls back the battle",
+		wantError: true,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			saveBattle(mock)
+			mock.ExpectQuery(regexp.QuoteMeta(lockQuery)).
+				WillReturnError(errors.New("deadlock detected"))
+			mock.ExpectRollback()
+		},
+		expectedError: errors.New("deadlock detected"),
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "failed history rolls back the battle",
+		wantError: true,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			saveBattle(mock)
+			mock.ExpectQuery(regexp.QuoteMeta(lockQuery)).
+				WithArgs(pq.Array([]string{"eevee", "pikachu"})).
+				WillReturnRows(sqlmock.NewRows([]string{"name", "rating", "rd", "volatility", "games", "updated_at"}).
+					AddRow("pikachu", 1550.5, 120.25, 0.06, 4, now))
+			mock.ExpectExec(regexp.QuoteMeta(upsertQuery)).
+				WithArgs("pikachu", 1562.5, 118.5, 0.06, 5, now).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta(historyQuery)).
+				WillReturnError(errors.New("unexpected error"))
+			mock.ExpectRollback()
+		},
+		expectedError: errors.New("unexpected error"),
+		expectedRated: []models.Rating{pikachu},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "success",
+		wantError: false,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			saveBattle(mock)
+			mock.ExpectQuery(regexp.QuoteMeta(lockQuery)).
+				WithArgs(pq.Array([]string{"eevee", "pikachu"})).
+				WillReturnRows(sqlmock.NewRows([]string{"name", "rating", "rd", "volatility", "games", "updated_at"}).
+					AddRow("pikachu", 1550.5, 120.25, 0.06, 4, now))
+			mock.ExpectExec(regexp.QuoteMeta(upsertQuery)).
+				WithArgs("pikachu", 1562.5, 118.5, 0.06, 5, now).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta(historyQuery)).
+				WithArgs(5, "pikachu", 1550.5, 1562.5, 120.25, 118.5, now).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec(regexp.QuoteMeta(upsertQuery)).
+				WithArgs("eevee", 1337.5, 290.25, 0.06, 1, now).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta(historyQuery)).
+				WithArgs(5, "eevee", 1500.0, 1337.5, 350.0, 290.25, now).
+				WillReturnResult(sqlmock.NewResult(2, 1))
+			mock.ExpectCommit()
+		},
+		expectedRated: []models.Rating{pikachu},
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+
+			if tc.mockQuery != nil {
+				tc.mockQuery(mock)
+			}
+			repo := PokeRepo{
+				db: db,
+			}
+
+			var rated []models.Rating
+			rate := func(current []models.Rating) (before, res []models.Rating) {
+				rated = current
+				return []models.Rating{current[0], fresh}, after
+			}
+
+			id, serr := repo.SaveBattle(input, participants, nil, rate)
+			if tc.wantError {
+				assert.EqualError(t, serr, tc.expectedError.Error())
+				assert.Equal(t, int64(0), id)
+			} else {
+				assert.Nil(t, serr)
+				assert.Equal(t, int64(5), id)
+			}
+			assert.Equal(t, tc.expectedRated, rated)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -1,15 +1,83 @@
 package repository
 
-import "database/sql"
+import (
+	"database/sql"
+	"pokemon/repository/query"
+
+	"github.com/lib/pq"
+)
+
+const (
+	StorePostgres = "postgres"
+	StoreSQLite   = "sqlite"
+	StoreMemory   = "memory"
+)
 
 type PokeRepo struct {
-	db *sql.DB
+	db      *sql.DB
+	pokedex Pokedex
+	// dialect adapts the queries, written for Postgres, to another database
+	dialect dialect
 }
 
 type PokeRepoInterface interface {
 	PokemonRepo
 }
 
-func NewPokeRepo(db *sql.DB) *PokeRepo {
-	return &PokeRepo{db: db}
+// dialect rewrites a query, turns a slice into the argument its = ANY takes
+// and tells how a battle is inserted
+type dialect interface {
+	rebind(qry string) string
+	array(values interface{}) interface{}
+	battleQueries() battleQueries
+}
+
+// battleQueries insert a battle and its participants, when species is set
+// it runs first with the name of every pokemon of the battle
+type battleQueries struct {
+	species     string
+	battle      string
+	participant string
+}
+
+// execer is a *sql.DB or a *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func NewPokeRepo(db *sql.DB, pokedex Pokedex) *PokeRepo {
+	return &PokeRepo{db: db, pokedex: pokedex}
+}
+
+func (p *PokeRepo) rebind(qry string) string {
+	if p.dialect == nil {
+		return qry
+	}
+	return p.dialect.rebind(qry)
+}
+
+func (p *PokeRepo) array(values interface{}) interface{} {
+	if p.dialect == nil {
+		return pq.Array(values)
+	}
+	return p.dialect.array(values)
+}
+
+func (p *PokeRepo) battleQueries() battleQueries {
+	if p.dialect == nil {
+		return battleQueries{battle: query.PostBattle, participant: query.PostParticipant}
+	}
+	return p.dialect.battleQueries()
+}
+
+// expectOneRow turns an update that matched no row into err
+func expectOneRow(result sql.Result, err error) error {
+	n, rerr := result.RowsAffected()
+	if rerr != nil {
+		return rerr
+	}
+	if n == 0 {
+		return err
+	}
+	return nil
 }
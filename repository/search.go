@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"pokemon/models"
+	"pokemon/repository/query"
+	"strconv"
+	"strings"
+)
+
+// searchBuilder adds conditions to a query, numbering the ? of every
+// condition after the arguments already given
+type searchBuilder struct {
+	where []string
+	args  []interface{}
+}
+
+func (b *searchBuilder) placeholders(clause string, args ...interface{}) string {
+	var sb strings.Builder
+	for _, arg := range args {
+		i := strings.Index(clause, "?")
+		b.args = append(b.args, arg)
+		sb.WriteString(clause[:i])
+		sb.WriteString("$" + strconv.Itoa(len(b.args)))
+		clause = clause[i+1:]
+	}
+	sb.WriteString(clause)
+	return sb.String()
+}
+
+func (b *searchBuilder) and(condition string, args ...interface{}) {
+	b.where = append(b.where, b.placeholders(condition, args...))
+}
+
+// battleSearchQuery turns the search into SQL, every value is an argument
+func battleSearchQuery(search models.BattleSearch) (qry string, args []interface{}) {
+	var b searchBuilder
+
+	if !search.StartFrom.IsZero() {
+		b.and(query.BattleStartFrom, search.StartFrom)
+	}
+	if !search.StartBefore.IsZero() {
+		b.and(query.BattleStartBefore, search.StartBefore)
+	}
+	if search.Winner != "" {
+		b.and(query.BattleWinner, search.Winner)
+	}
+	if search.Participant != "" {
+		b.and(query.BattleParticipant, search.Participant)
+	}
+	if search.MinParticipants > 0 {
+		b.and(query.BattleMinParticipants, search.MinParticipants)
+	}
+	if search.Engine != "" {
+		b.and(query.BattleEngine, search.Engine)
+	}
+
+	order := query.BattleOrderDesc
+	if search.Ascending {
+		order = query.BattleOrderAsc
+	}
+	if search.AfterID > 0 {
+		if search.Ascending {
+			b.and(query.BattleAfterAsc, search.AfterID)
+		} else {
+			b.and(query.BattleAfterDesc, search.AfterID)
+		}
+	}
+
+	qry = query.SearchBattles
+	if len(b.where) > 0 {
+		qry += "WHERE " + strings.Join(b.where, " AND ") + "\n"
+	}
+	qry += order
+	if search.Limit > 0 {
+		qry += "\n" + b.placeholders(query.BattleLimit, search.Limit)
+	}
+
+	return qry, b.args
+}
+
+// pokemonScoreQuery sums the scores of the battles started in r
+func pokemonScoreQuery(r models.ScoreRange) (qry string, args []interface{}) {
+	var b searchBuilder
+
+	b.and(query.ScoreNotAnnulled)
+	if !r.From.IsZero() {
+		b.and(query.BattleStartFrom, r.From)
+	}
+	if !r.Before.IsZero() {
+		b.and(query.BattleStartBefore, r.Before)
+	}
+
+	qry = query.PokemonScore + "WHERE " + strings.Join(b.where, " AND ") + "\n" + query.ScoreOrder
+	return qry, b.args
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"pokemon/models"
+	"strings"
+)
+
+var ErrInvalidSnapshot = errors.New("invalid pokedex snapshot")
+
+// ReadSnapshot reads a pokedex snapshot, either a JSON array of pokemon or one
+// pokemon per line (NDJSON), each shaped like the PokeAPI pokemon response
+func ReadSnapshot(r io.Reader) (res []models.GetPokemon, err error) {
+	reader := bufio.NewReader(r)
+	decoder := json.NewDecoder(reader)
+
+	first, err := firstByte(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if first == '[' {
+		if err = decoder.Decode(&res); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+		}
+	} else {
+		for {
+			var poke models.GetPokemon
+			err = decoder.Decode(&poke)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%w: pokemon %d: %v", ErrInvalidSnapshot, len(res)+1, err)
+			}
+			res = append(res, poke)
+		}
+	}
+
+	seen := make(map[string]bool, len(res))
+	for i := range res {
+		res[i].Name = strings.ToLower(strings.TrimSpace(res[i].Name))
+		if res[i].Name == "" {
+			return nil, fmt.Errorf("%w: pokemon %d has no name", ErrInvalidSnapshot, i+1)
+		}
+		if seen[res[i].Name] {
+			return nil, fmt.Errorf("%w: %s is listed twice", ErrInvalidSnapshot, res[i].Name)
+		}
+		seen[res[i].Name] = true
+	}
+
+	if len(res) == 0 {
+		return nil, fmt.Errorf("%w: no pokemon", ErrInvalidSnapshot)
+	}
+
+	return res, nil
+}
+
+// firstByte peeks the first non blank byte, 0 when the input is empty
+func firstByte(reader *bufio.Reader) (byte, error) {
+	for {
+		b, err := reader.Peek(1)
+		if errors.Is(err, io.EOF) {
+			return 0, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			reader.Discard(1)
+		default:
+			return b[0], nil
+		}
+	}
+}
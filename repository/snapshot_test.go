@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"errors"
+	"pokemon/models"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ReadSnapshot(t *testing.T) {
+	type testCase struct {
+		name           string
+		input          string
+		wantError      bool
+		expectedResult []models.GetPokemon
+	}
+
+	var (
+		testTable []testCase
+		pikachu   = models.GetPokemon{
+			ID:    25,
+			Name:  "pikachu",
+			Stats: []models.Stats{{BaseStat: 35, Stat: models.Stat{Name: "hp"}}},
+			Types: []models.Types{{Slot: 1, Type: models.Type{Name: "electric"}}},
+		}
+		eevee = models.GetPokemon{ID: 133, Name: "eevee"}
+	)
+
+	testTable = append(testTable, testCase{
+		name: "success json array",
+		input: ` [
+			{"id": 25, "name": "pikachu", "stats": [{"base_stat": 35, "stat": {"name": "hp"}}], "types": [{"slot": 1, "type": {"name": "electric"}}]},
+			{"id": 133, "name": "eevee"}
+		]`,
+		expectedResult: []models.GetPokemon{pikachu, eevee},
+	})
+
+	testTable = append(testTable, testCase{
+		name: "success ndjson",
+		input: `{"id": 25, "name": "Pikachu", "stats": [{"base_stat": 35, "stat": {"name": "hp"}}], "types": [{"slot": 1, "type": {"name": "electric"}}]}
+{"id": 133, "name": "eevee"}
+`,
+		expectedResult: []models.GetPokemon{pikachu, eevee},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "failed empty",
+		input:     " \n",
+		wantError: true,
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "failed broken line",
+		input:     "{\"name\": \"eevee\"}\n{\"name\": ",
+		wantError: true,
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "failed missing name",
+		input:     `[{"id": 1}]`,
+		wantError: true,
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "failed duplicate",
+		input:     `[{"name": "eevee"}, {"name": "eevee"}]`,
+		wantError: true,
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := ReadSnapshot(strings.NewReader(tc.input))
+			if tc.wantError {
+				assert.True(t, errors.Is(err, ErrInvalidSnapshot), err)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tc.expectedResult, res)
+			}
+		})
+	}
+}
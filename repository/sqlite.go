@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"pokemon/repository/query"
+	"regexp"
+)
+
+var (
+	anyPlaceholder = regexp.MustCompile(`= ANY\(\$(\d+)\)`)
+	placeholder    = regexp.MustCompile(`\$(\d+)`)
+	forUpdate      = regexp.MustCompile(`\s+FOR UPDATE`)
+)
+
+// sqliteDialect numbers the placeholders the way SQLite does, ?N rather than
+// $N, and reads the = ANY arrays from JSON. FOR UPDATE is dropped, SQLite
+// runs one write transaction at a time anyway.
+type sqliteDialect struct{}
+
+func (sqliteDialect) rebind(qry string) string {
+	qry = forUpdate.ReplaceAllString(qry, "")
+	qry = anyPlaceholder.ReplaceAllString(qry, "IN (SELECT value FROM json_each(?$1))")
+	return placeholder.ReplaceAllString(qry, "?$1")
+}
+
+func (sqliteDialect) array(values interface{}) interface{} {
+	return jsonArray{values: values}
+}
+
+// battleQueries insert the species on their own first, see
+// query.PostSpeciesSQLite
+func (sqliteDialect) battleQueries() battleQueries {
+	return battleQueries{
+		species:     query.PostSpeciesSQLite,
+		battle:      query.PostBattleSQLite,
+		participant: query.PostParticipantSQLite,
+	}
+}
+
+type jsonArray struct {
+	values interface{}
+}
+
+func (a jsonArray) Value() (driver.Value, error) {
+	body, err := json.Marshal(a.values)
+	if err != nil {
+		return nil, err
+	}
+	return string(body), nil
+}
+
+// SQLiteRepo keeps everything in a SQLite database migrated with
+// migrations.NewSQLite, it runs the queries of PokeRepo rewritten by
+// sqliteDialect
+type SQLiteRepo struct {
+	*PokeRepo
+}
+
+func NewSQLiteRepo(db *sql.DB, pokedex Pokedex) *SQLiteRepo {
+	return &SQLiteRepo{PokeRepo: &PokeRepo{db: db, pokedex: pokedex, dialect: sqliteDialect{}}}
+}
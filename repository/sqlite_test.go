@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SQLiteDialect_Rebind(t *testing.T) {
+	type testCase struct {
+		name           string
+		query          string
+		expectedResult string
+	}
+
+	var testTable []testCase
+
+	testTable = append(testTable, testCase{
+		name:           "placeholders keep their number",
+		query:          `UPDATE tournament SET round = $2, winner = $3 WHERE tournament_id = $1`,
+		expectedResult: `UPDATE tournament SET round = ?2, winner = ?3 WHERE tournament_id = ?1`,
+	})
+
+	testTable = append(testTable, testCase{
+		name:           "any reads a json array",
+		query:          `SELECT r.name FROM rating r WHERE r.name = ANY($1) AND r.games > $2`,
+		expectedResult: `SELECT r.name FROM rating r WHERE r.name IN (SELECT value FROM json_each(?1)) AND r.games > ?2`,
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedResult, sqliteDialect{}.rebind(tc.query))
+		})
+	}
+}
+
+func Test_SQLiteDialect_Array(t *testing.T) {
+	value, err := sqliteDialect{}.array([]string{"pikachu", "mewtwo"}).(jsonArray).Value()
+
+	assert.NoError(t, err)
+	assert.Equal(t, `["pikachu","mewtwo"]`, value)
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"pokemon/models"
+	"pokemon/repository/query"
+)
+
+var (
+	ErrTournamentNotFound = errors.New("tournament not found")
+	ErrTournamentAdvanced = errors.New("tournament already left that round")
+	ErrMatchPlayed        = errors.New("tournament match was already played")
+)
+
+// PostTournament stores the tournament and the matches of its first round in
+// one transaction, the matches get the id of the new tournament
+func (p *PokeRepo) PostTournament(input models.Tournament, matches []models.TournamentMatch) (Id int64, err error) {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return Id, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	err = tx.QueryRow(
+		p.rebind(query.PostTournament),
+		input.Name,
+		input.Size,
+		input.Seed,
+		input.Round,
+		input.Winner,
+		input.CreatedAt,
+	).Scan(&Id)
+	if err != nil {
+		return Id, err
+	}
+
+	for _, match := range matches {
+		match.TournamentID = int(Id)
+		if err = p.postTournamentMatch(tx, match); err != nil {
+			return Id, err
+		}
+	}
+
+	return Id, tx.Commit()
+}
+
+// AdvanceTournament moves the tournament on from round in one transaction:
+// without a winner it goes to the next round and next holds its matches,
+// with one it's closed on round. When the tournament already left round
+// nothing changes and ErrTournamentAdvanced is returned.
+func (p *PokeRepo) AdvanceTournament(TournamentID, round int, winner string, next []models.TournamentMatch) (err error) {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	to := round
+	if winner == "" {
+		to = round + 1
+	}
+
+	result, err := tx.Exec(
+		p.rebind(query.AdvanceTournament),
+		TournamentID,
+		round,
+		to,
+		winner,
+	)
+	if err != nil {
+		return err
+	}
+	if err = expectOneRow(result, ErrTournamentAdvanced); err != nil {
+		return err
+	}
+
+	for _, match := range next {
+		if err = p.postTournamentMatch(tx, match); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (p *PokeRepo) GetTournament(TournamentID int) (res models.Tournament, err error) {
+	err = p.db.QueryRow(
+		p.rebind(query.GetTournament),
+		TournamentID,
+	).Scan(
+		&res.TournamentID,
+		&res.Name,
+		&res.Size,
+		&res.Seed,
+		&res.Round,
+		&res.Winner,
+		&res.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return res, ErrTournamentNotFound
+	}
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (p *PokeRepo) PostTournamentMatch(input models.TournamentMatch) error {
+	return p.postTournamentMatch(p.db, input)
+}
+
+func (p *PokeRepo) postTournamentMatch(db execer, input models.TournamentMatch) error {
+	_, err := db.Exec(
+		p.rebind(query.PostTournamentMatch),
+		input.TournamentID,
+		input.Round,
+		input.Slot,
+		input.PokemonA,
+		input.PokemonB,
+		input.Winner,
+		input.BattleID,
+	)
+
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SaveTournamentBattle stores and rates the battle of a tournament match like
+// SaveBattle and records its winner in the same transaction. A match that
+// already has a winner keeps it, nothing of the battle is kept and
+// ErrMatchPlayed is returned.
+func (p *PokeRepo) SaveTournamentBattle(MatchID int, input models.BattleInput, participants []models.Participant, events []models.BattleEvent, rate Rater) (Id int64, err error) {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return Id, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if Id, err = p.insertBattle(tx, input, participants, events, rate); err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec(
+		p.rebind(query.UpdateTournamentMatch),
+		MatchID,
+		input.Winner,
+		Id,
+	)
+	if err != nil {
+		return 0, err
+	}
+	if err = expectOneRow(result, ErrMatchPlayed); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return Id, nil
+}
+
+func (p *PokeRepo) GetTournamentMatches(TournamentID int) (res []models.TournamentMatch, err error) {
+	row, err := p.db.Query(
+		p.rebind(query.GetTournamentMatches),
+		TournamentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	for row.Next() {
+		temp := models.TournamentMatch{}
+		err = row.Scan(
+			&temp.MatchID,
+			&temp.TournamentID,
+			&temp.Round,
+			&temp.Slot,
+			&temp.PokemonA,
+			&temp.PokemonB,
+			&temp.Winner,
+			&temp.BattleID,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, temp)
+	}
+	if err = row.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
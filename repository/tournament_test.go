@@ -0,0 +1,485 @@
+package repository
+
+import (
+	"errors"
+	"pokemon/models"
+	"pokemon/repository/query"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Post_Tournament(t *testing.T) {
+	type testCase struct {
+		name          string
+		wantError     bool
+		mockQuery     func(mock sqlmock.Sqlmock)
+		expectedError error
+		expectedID    int64
+	}
+
+	var (
+		testTable     []testCase
+		expectedQuery = `
+		INSERT INTO
+			tournament(
+				name,
+				size,
+				seed,
+				round,
+				winner,
+				created_at
+			)
+		VALUES(
+			$1, $2, $3, $4, $5, $6
+		)
+		RETURNING tournament_id;
+	`
+		arg     = models.Tournament{Name: "cup", Size: 8, Seed: 42, Round: 1, CreatedAt: time.Now()}
+		matches = []models.TournamentMatch{
+			{Round: 1, Slot: 0, PokemonA: "mewtwo", PokemonB: "ditto"},
+			{Round: 1, Slot: 1, PokemonA: "eevee", PokemonB: "pichu"},
+		}
+	)
+
+	testTable = append(testTable, testCase{
+		name:      "failed unexpected error",
+		wantError: true,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+				WithArgs("cup", 8, 42, 1, "", sqlmock.AnyArg()).
+				WillReturnError(errors.New("unexpected error"))
+			mock.ExpectRollback()
+		},
+		expectedError: errors.New("unexpected error"),
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "failed match rolls back the tournament",
+		wantError: true,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+				WithArgs("cup", 8, 42, 1, "", sqlmock.AnyArg()).
+				WillReturnRows(sqlmock.NewRows([]string{"tournament_id"}).AddRow(3))
+			mock.ExpectExec(regexp.QuoteMeta(query.PostTournamentMatch)).
+				WithArgs(3, 1, 0, "mewtwo", "ditto", "", 0).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta(query.PostTournamentMatch)).
+				WithArgs(3, 1, 1, "eevee", "pichu", "", 0).
+				WillReturnError(errors.New("duplicate key value violates unique constraint"))
+			mock.ExpectRollback()
+		},
+		expectedError: errors.New("duplicate key value violates unique constraint"),
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "success",
+		wantError: false,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+				WithArgs("cup", 8, 42, 1, "", sqlmock.AnyArg()).
+				WillReturnRows(sqlmock.NewRows([]string{"tournament_id"}).AddRow(3))
+			mock.ExpectExec(regexp.QuoteMeta(query.PostTournamentMatch)).
+				WithArgs(3, 1, 0, "mewtwo", "ditto", "", 0).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta(query.PostTournamentMatch)).
+				WithArgs(3, 1, 1, "eevee", "pichu", "", 0).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+		},
+		expectedID: 3,
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+
+			if tc.mockQuery != nil {
+				tc.mockQuery(mock)
+			}
+			repo := PokeRepo{
+				db: db,
+			}
+
+			id, serr := repo.PostTournament(arg, matches)
+			if tc.wantError {
+				assert.EqualError(t, serr, tc.expectedError.Error())
+			} else {
+				assert.Nil(t, serr)
+				assert.Equal(t, tc.expectedID, id)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_Get_Tournament(t *testing.T) {
+	type testCase struct {
+		name           string
+		wantError      bool
+		mockQuery      func(mock sqlmock.Sqlmock)
+		expectedError  error
+		expectedResult models.Tournament
+	}
+
+	var (
+		testTable     []testCase
+		now           = time.Now()
+		columns       = []string{"tournament_id", "name", "size", "seed", "round", "winner", "created_at"}
+		expectedQuery = `
+		SELECT
+			t.tournament_id,
+			t.name,
+			t.size,
+			t.seed,
+			t.round,
+			t.winner,
+			t.created_at
+		FROM tournament t
+		WHERE t.tournament_id = $1
+	`
+	)
+
+	testTable = append(testTable, testCase{
+		name:      "failed not found",
+		wantError: true,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+				WithArgs(1).
+				WillReturnRows(sqlmock.NewRows(columns))
+		},
+		expectedError: ErrTournamentNotFound,
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "success",
+		wantError: false,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+				WithArgs(1).
+				WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "cup", 8, 42, 2, "", now))
+		},
+		expectedResult: models.Tournament{TournamentID: 1, Name: "cup", Size: 8, Seed: 42, Round: 2, CreatedAt: now},
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+
+			if tc.mockQuery != nil {
+				tc.mockQuery(mock)
+			}
+			repo := PokeRepo{
+				db: db,
+			}
+
+			res, serr := repo.GetTournament(1)
+			if tc.wantError {
+				assert.EqualError(t, serr, tc.expectedError.Error())
+			} else {
+				assert.Nil(t, serr)
+				assert.Equal(t, tc.expectedResult, res)
+			}
+		})
+	}
+}
+
+func Test_Advance_Tournament(t *testing.T) {
+	type testCase struct {
+		name          string
+		winner        string
+		next          []models.TournamentMatch
+		wantError     bool
+		mockQuery     func(mock sqlmock.Sqlmock)
+		expectedError error
+	}
+
+	var (
+		testTable    []testCase
+		advanceQuery = `
+		UPDATE tournament
+		SET round = $3, winner = $4
+		WHERE tournament_id = $1 AND round = $2 AND winner = ''
+	`
+		matchQuery = `
+		INSERT INTO
+			tournament_match(
+				tournament_id,
+				round,
+				slot,
+				pokemon_a,
+				pokemon_b,
+				winner,
+				battle_id
+			)
+		VALUES(
+			$1, $2, $3, $4, $5, $6, NULLIF($7, 0)
+		)
+	`
+		next = []models.TournamentMatch{
+			{TournamentID: 1, Round: 3, Slot: 0, PokemonA: "mewtwo", PokemonB: "ditto"},
+			{TournamentID: 1, Round: 3, Slot: 1, PokemonA: "eevee", PokemonB: "pichu"},
+		}
+	)
+
+	testTable = append(testTable, testCase{
+		name:          "failed already advanced",
+		next:          next,
+		wantError:     true,
+		expectedError: ErrTournamentAdvanced,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(advanceQuery)).
+				WithArgs(1, 2, 3, "").
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectRollback()
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "failed match rolls back the round",
+		next:          next,
+		wantError:     true,
+		expectedError: errors.New("duplicate key value violates unique constraint"),
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(advanceQuery)).
+				WithArgs(1, 2, 3, "").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta(matchQuery)).
+				WithArgs(1, 3, 0, "mewtwo", "ditto", "", 0).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta(matchQuery)).
+				WithArgs(1, 3, 1, "eevee", "pichu", "", 0).
+				WillReturnError(errors.New("duplicate key value violates unique constraint"))
+			mock.ExpectRollback()
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name: "success next round",
+		next: next,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(advanceQuery)).
+				WithArgs(1, 2, 3, "").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta(matchQuery)).
+				WithArgs(1, 3, 0, "mewtwo", "ditto", "", 0).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta(matchQuery)).
+				WithArgs(1, 3, 1, "eevee", "pichu", "", 0).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:   "success winner",
+		winner: "mewtwo",
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(advanceQuery)).
+				WithArgs(1, 2, 2, "mewtwo").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+		},
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+
+			if tc.mockQuery != nil {
+				tc.mockQuery(mock)
+			}
+			repo := PokeRepo{
+				db: db,
+			}
+
+			serr := repo.AdvanceTournament(1, 2, tc.winner, tc.next)
+			if tc.wantError {
+				assert.EqualError(t, serr, tc.expectedError.Error())
+			} else {
+				assert.Nil(t, serr)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_Save_TournamentBattle(t *testing.T) {
+	type testCase struct {
+		name          string
+		wantError     bool
+		mockQuery     func(mock sqlmock.Sqlmock)
+		expectedError error
+		expectedID    int64
+	}
+
+	var (
+		testTable  []testCase
+		matchQuery = `
+		UPDATE tournament_match
+		SET winner = $2, battle_id = $3
+		WHERE match_id = $1 AND winner = ''
+	`
+		input = models.BattleInput{
+			Winner:    "mewtwo",
+			Roster:    []string{"mewtwo", "ditto"},
+			StartTime: time.Now(),
+			EndTime:   time.Now(),
+		}
+		participants = []models.Participant{
+			{Name: "mewtwo", Placement: 1, Scores: 2},
+			{Name: "ditto", Placement: 2, Scores: 1},
+		}
+		expectBattle = func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(query.PostBattle)).
+				WillReturnRows(sqlmock.NewRows([]string{"battle_id"}).AddRow(7))
+			mock.ExpectExec(regexp.QuoteMeta(query.PostParticipant)).
+				WithArgs("mewtwo", int64(7), 1, 2).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta(query.PostParticipant)).
+				WithArgs("ditto", int64(7), 2, 1).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+	)
+
+	testTable = append(testTable, testCase{
+		name:          "failed match already played rolls back the battle",
+		wantError:     true,
+		expectedError: ErrMatchPlayed,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			expectBattle(mock)
+			mock.ExpectExec(regexp.QuoteMeta(matchQuery)).
+				WithArgs(5, "mewtwo", int64(7)).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectRollback()
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:       "success",
+		expectedID: 7,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			expectBattle(mock)
+			mock.ExpectExec(regexp.QuoteMeta(matchQuery)).
+				WithArgs(5, "mewtwo", int64(7)).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+		},
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+
+			if tc.mockQuery != nil {
+				tc.mockQuery(mock)
+			}
+			repo := PokeRepo{
+				db: db,
+			}
+
+			id, serr := repo.SaveTournamentBattle(5, input, participants, nil, nil)
+			if tc.wantError {
+				assert.EqualError(t, serr, tc.expectedError.Error())
+			} else {
+				assert.Nil(t, serr)
+			}
+			assert.Equal(t, tc.expectedID, id)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_Get_TournamentMatches(t *testing.T) {
+	type testCase struct {
+		name           string
+		wantError      bool
+		mockQuery      func(mock sqlmock.Sqlmock)
+		expectedError  error
+		expectedResult []models.TournamentMatch
+	}
+
+	var (
+		testTable     []testCase
+		columns       = []string{"match_id", "tournament_id", "round", "slot", "pokemon_a", "pokemon_b", "winner", "battle_id"}
+		expectedQuery = `
+		SELECT
+			m.match_id,
+			m.tournament_id,
+			m.round,
+			m.slot,
+			m.pokemon_a,
+			m.pokemon_b,
+			m.winner,
+			COALESCE(m.battle_id, 0)
+		FROM tournament_match m
+		WHERE m.tournament_id = $1
+		ORDER BY m.round, m.slot
+	`
+	)
+
+	testTable = append(testTable, testCase{
+		name:      "failed unexpected error",
+		wantError: true,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+				WillReturnError(errors.New("unexpected error"))
+		},
+		expectedError: errors.New("unexpected error"),
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "success",
+		wantError: false,
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+				WithArgs(1).
+				WillReturnRows(sqlmock.NewRows(columns).
+					AddRow(1, 1, 1, 0, "mewtwo", "pichu", "mewtwo", 7).
+					AddRow(2, 1, 1, 1, "eevee", "ditto", "", 0))
+		},
+		expectedResult: []models.TournamentMatch{
+			{MatchID: 1, TournamentID: 1, Round: 1, Slot: 0, PokemonA: "mewtwo", PokemonB: "pichu", Winner: "mewtwo", BattleID: 7},
+			{MatchID: 2, TournamentID: 1, Round: 1, Slot: 1, PokemonA: "eevee", PokemonB: "ditto"},
+		},
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+
+			if tc.mockQuery != nil {
+				tc.mockQuery(mock)
+			}
+			repo := PokeRepo{
+				db: db,
+			}
+
+			res, serr := repo.GetTournamentMatches(1)
+			if tc.wantError {
+				assert.EqualError(t, serr, tc.expectedError.Error())
+			} else {
+				assert.Nil(t, serr)
+				assert.Equal(t, tc.expectedResult, res)
+			}
+		})
+	}
+}
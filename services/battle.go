@@ -0,0 +1,271 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"pokemon/models"
+	"sort"
+)
+
+const (
+	// battleLevel is the level every fighter is scaled to before entering the ring
+	battleLevel = 50
+	// basePower is the power of the single generic attack every fighter uses
+	basePower = 60
+	// maxTurns stops a battle that can not finish, e.g. when nobody can hurt anybody
+	maxTurns = 200
+
+	// DefaultPokemons is the number of participants when the request does not ask for one
+	DefaultPokemons = 5
+	MinPokemons     = 2
+	MaxPokemons     = 16
+)
+
+var (
+	ErrNoFighters      = errors.New("there is no pokemon to fight")
+	ErrInvalidPokemons = fmt.Errorf("pokemons must be between %d and %d", MinPokemons, MaxPokemons)
+
+	ErrLastParticipant = errors.New("the last participant of a battle can't be annulled")
+)
+
+// Fighter is a pokemon prepared for the ring with its battle stats at battleLevel
+type Fighter struct {
+	Name      string
+	MaxHP     int
+	HP        int
+	Attack    int
+	Defense   int
+	SpAttack  int
+	SpDefense int
+	Speed     int
+	// Types holds the types of the pokemon by slot, the first one is the type of its default attack
+	Types []string
+	Moves []BattleMove
+}
+
+// BattleResult is the outcome of a simulated battle
+type BattleResult struct {
+	// Placements holds the fighter names ordered from the longest survivor to the first eliminated
+	Placements []string
+	Turns      int
+	// Draw is set when the turn limit stopped the battle and the best two
+	// survivors are left with the same share of their HP
+	Draw bool
+	// Events holds every hit in the order it happened
+	Events []models.BattleEvent
+	// MovePolicy is the name of the policy the fighters picked their moves with
+	MovePolicy string
+	// Engine and EngineVersion identify the rules that decided the battle
+	Engine        string
+	EngineVersion int
+}
+
+// NewFighter scales the base stats of a pokemon to battleLevel
+func NewFighter(p models.GetPokemon) Fighter {
+	f := Fighter{Name: p.Name}
+	for _, s := range p.Stats {
+		switch s.Stat.Name {
+		case "hp":
+			f.MaxHP = (2*s.BaseStat*battleLevel)/100 + battleLevel + 10
+		case "attack":
+			f.Attack = scaleStat(s.BaseStat)
+		case "defense":
+			f.Defense = scaleStat(s.BaseStat)
+		case "special-attack":
+			f.SpAttack = scaleStat(s.BaseStat)
+		case "special-defense":
+			f.SpDefense = scaleStat(s.BaseStat)
+		case "speed":
+			f.Speed = scaleStat(s.BaseStat)
+		}
+	}
+	f.HP = f.MaxHP
+
+	types := append([]models.Types(nil), p.Types...)
+	sort.SliceStable(types, func(i, j int) bool {
+		return types[i].Slot < types[j].Slot
+	})
+	for _, t := range types {
+		f.Types = append(f.Types, t.Type.Name)
+	}
+	return f
+}
+
+func scaleStat(base int) int {
+	return (2*base*battleLevel)/100 + 5
+}
+
+// Simulate runs a free-for-all battle turn by turn until one fighter is left.
+// Every turn the living fighters act in speed order and hit a random opponent
+// with the move the policy picks, the ones whose HP reaches zero are eliminated.
+// A nil policy picks moves at random.
+func Simulate(fighters []Fighter, policy MovePolicy, rng *rand.Rand) BattleResult {
+	var (
+		ring       = make([]*Fighter, len(fighters))
+		eliminated = make([]string, 0, len(fighters))
+		events     = make([]models.BattleEvent, 0)
+		turn       int
+	)
+
+	if policy == nil {
+		policy = RandomMovePolicy{}
+	}
+
+	for i := range fighters {
+		f := fighters[i]
+		// every battle spends the PP of its own copy of the moves
+		f.Moves = append([]BattleMove(nil), f.Moves...)
+		ring[i] = &f
+	}
+
+	for turn = 1; turn <= maxTurns && countAlive(ring) > 1; turn++ {
+		order := alive(ring)
+		rng.Shuffle(len(order), func(i, j int) {
+			order[i], order[j] = order[j], order[i]
+		})
+		sort.SliceStable(order, func(i, j int) bool {
+			return order[i].Speed > order[j].Speed
+		})
+
+		for _, attacker := range order {
+			if attacker.HP <= 0 {
+				continue
+			}
+
+			targets := opponents(ring, attacker)
+			if len(targets) == 0 {
+				break
+			}
+			target := targets[rng.Intn(len(targets))]
+
+			var (
+				move *BattleMove
+				name = defaultMove
+				dmg  int
+			)
+			if len(attacker.Moves) > 0 {
+				if i := policy.ChooseMove(attacker, target, rng); i >= 0 {
+					move = &attacker.Moves[i]
+					move.PP--
+					name = move.Name
+				}
+			}
+
+			if move == nil || move.Accuracy == 0 || rng.Intn(100) < move.Accuracy {
+				dmg = damage(attacker, target, move, rng)
+			}
+			target.HP -= dmg
+			if target.HP <= 0 {
+				target.HP = 0
+				eliminated = append(eliminated, target.Name)
+			}
+
+			events = append(events, models.BattleEvent{
+				Turn:        turn,
+				Actor:       attacker.Name,
+				Target:      target.Name,
+				Move:        name,
+				Damage:      dmg,
+				RemainingHP: target.HP,
+				Eliminated:  target.HP == 0,
+			})
+		}
+	}
+
+	// whoever is still standing is ranked by remaining HP
+	survivors := alive(ring)
+	sort.SliceStable(survivors, func(i, j int) bool {
+		return survivors[i].HP*survivors[j].MaxHP > survivors[j].HP*survivors[i].MaxHP
+	})
+
+	res := BattleResult{Turns: turn - 1, Events: events}
+	if len(survivors) > 1 {
+		res.Draw = survivors[0].HP*survivors[1].MaxHP == survivors[1].HP*survivors[0].MaxHP
+	}
+	for _, f := range survivors {
+		res.Placements = append(res.Placements, f.Name)
+	}
+	for i := len(eliminated) - 1; i >= 0; i-- {
+		res.Placements = append(res.Placements, eliminated[i])
+	}
+
+	return res
+}
+
+// damage follows the main series formula. A nil move is the generic attack:
+// it has a fixed power, carries the primary type of the attacker and picks the
+// physical or special side depending on which one suits the attacker better.
+// A real move gets the STAB bonus when it shares a type with the attacker.
+// Either way the hit is scaled by how effective its type is against the target.
+func damage(attacker, target *Fighter, move *BattleMove, rng *rand.Rand) int {
+	var (
+		power      = basePower
+		special    = attacker.SpAttack > attacker.Attack
+		attackType string
+		bonus      = 1.0
+	)
+	if len(attacker.Types) > 0 {
+		attackType = attacker.Types[0]
+	}
+	if move != nil {
+		power, special, attackType = move.Power, move.Special, move.Type
+		bonus = stab(attacker, move.Type)
+	}
+
+	att, def := attacker.Attack, target.Defense
+	if special {
+		att, def = attacker.SpAttack, target.SpDefense
+	}
+	if def < 1 {
+		def = 1
+	}
+
+	base := ((2*battleLevel/5+2)*power*att/def)/50 + 2
+	// random factor between 85% and 100%
+	dmg := base * (85 + rng.Intn(16)) / 100
+
+	if attackType != "" {
+		multiplier := bonus * Effectiveness(attackType, target.Types)
+		dmg = int(float64(dmg) * multiplier)
+		// only an immunity can bring a hit down to nothing
+		if multiplier > 0 && dmg < 1 {
+			dmg = 1
+		}
+	}
+	return dmg
+}
+
+// stab is the same-type attack bonus
+func stab(attacker *Fighter, moveType string) float64 {
+	for _, t := range attacker.Types {
+		if t == moveType {
+			return 1.5
+		}
+	}
+	return 1
+}
+
+func alive(ring []*Fighter) []*Fighter {
+	res := make([]*Fighter, 0, len(ring))
+	for _, f := range ring {
+		if f.HP > 0 {
+			res = append(res, f)
+		}
+	}
+	return res
+}
+
+func countAlive(ring []*Fighter) int {
+	return len(alive(ring))
+}
+
+func opponents(ring []*Fighter, attacker *Fighter) []*Fighter {
+	res := make([]*Fighter, 0, len(ring))
+	for _, f := range ring {
+		if f != attacker && f.HP > 0 {
+			res = append(res, f)
+		}
+	}
+	return res
+}
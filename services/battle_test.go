@@ -0,0 +1,186 @@
+package services
+
+import (
+	"math/rand"
+	"pokemon/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPokemon(name string, hp, att, def, spAtt, spDef, speed int) models.GetPokemon {
+	stat := func(name string, base int) models.Stats {
+		return models.Stats{BaseStat: base, Stat: models.Stat{Name: name}}
+	}
+
+	return models.GetPokemon{
+		Name: name,
+		Stats: []models.Stats{
+			stat("hp", hp),
+			stat("attack", att),
+			stat("defense", def),
+			stat("special-attack", spAtt),
+			stat("special-defense", spDef),
+			stat("speed", speed),
+		},
+	}
+}
+
+func Test_NewFighter(t *testing.T) {
+	f := NewFighter(newTestPokemon("pikachu", 35, 55, 40, 50, 50, 90))
+
+	assert.Equal(t, Fighter{
+		Name:      "pikachu",
+		MaxHP:     95,
+		HP:        95,
+		Attack:    60,
+		Defense:   45,
+		SpAttack:  55,
+		SpDefense: 55,
+		Speed:     95,
+	}, f)
+}
+
+func Test_Simulate(t *testing.T) {
+	type testCase struct {
+		name             string
+		fighters         []models.GetPokemon
+		expectedWinner   string
+		expectedLoser    string
+		expectedNumTurns func(turns int) bool
+	}
+
+	var testTable []testCase
+
+	testTable = append(testTable, testCase{
+		name: "strongest survives longest",
+		fighters: []models.GetPokemon{
+			newTestPokemon("magikarp", 20, 10, 55, 15, 20, 80),
+			newTestPokemon("mewtwo", 106, 110, 90, 154, 90, 130),
+			newTestPokemon("pikachu", 35, 55, 40, 50, 50, 90),
+		},
+		expectedWinner: "mewtwo",
+		expectedLoser:  "magikarp",
+		expectedNumTurns: func(turns int) bool {
+			return turns > 0 && turns < maxTurns
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name: "stops at turn limit and ranks survivors by remaining hp",
+		fighters: []models.GetPokemon{
+			newTestPokemon("wall", 200, 0, 230, 0, 230, 5),
+			newTestPokemon("shuckle", 255, 0, 230, 0, 230, 5),
+		},
+		expectedWinner: "shuckle",
+		expectedLoser:  "wall",
+		expectedNumTurns: func(turns int) bool {
+			return turns == maxTurns
+		},
+	})
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			fighters := make([]Fighter, 0, len(testCase.fighters))
+			for _, p := range testCase.fighters {
+				fighters = append(fighters, NewFighter(p))
+			}
+
+			res := Simulate(fighters, nil, rand.New(rand.NewSource(1)))
+
+			assert.Len(t, res.Placements, len(testCase.fighters))
+			assert.Equal(t, testCase.expectedWinner, res.Placements[0])
+			assert.Equal(t, testCase.expectedLoser, res.Placements[len(res.Placements)-1])
+			assert.True(t, testCase.expectedNumTurns(res.Turns))
+
+			eliminated := make([]string, 0)
+			for _, event := range res.Events {
+				assert.LessOrEqual(t, event.Turn, res.Turns)
+				assert.NotEqual(t, event.Actor, event.Target)
+				if event.Eliminated {
+					assert.Equal(t, 0, event.RemainingHP)
+					eliminated = append([]string{event.Target}, eliminated...)
+				}
+			}
+			assert.Equal(t, res.Placements[len(res.Placements)-len(eliminated):], eliminated)
+		})
+	}
+}
+
+func Test_Simulate_Draw(t *testing.T) {
+	fighters := []Fighter{
+		NewFighter(newTestPokemon("shuckle", 255, 0, 230, 0, 230, 5)),
+		NewFighter(newTestPokemon("wall", 255, 0, 230, 0, 230, 5)),
+	}
+
+	res := Simulate(fighters, nil, rand.New(rand.NewSource(4)))
+
+	assert.Equal(t, maxTurns, res.Turns)
+	assert.True(t, res.Draw)
+}
+
+func Test_NewFighter_Types(t *testing.T) {
+	poke := newTestPokemon("gyarados", 95, 125, 79, 60, 100, 81)
+	poke.Types = []models.Types{
+		{Slot: 2, Type: models.Type{Name: "flying"}},
+		{Slot: 1, Type: models.Type{Name: "water"}},
+	}
+
+	f := NewFighter(poke)
+
+	assert.Equal(t, []string{"water", "flying"}, f.Types)
+}
+
+func Test_Damage_TypeEffectiveness(t *testing.T) {
+	type testCase struct {
+		name          string
+		attackerTypes []string
+		targetTypes   []string
+		expectedRatio float64
+	}
+
+	var testTable []testCase
+
+	testTable = append(testTable, testCase{
+		name:          "water against fire deals double",
+		attackerTypes: []string{"water"},
+		targetTypes:   []string{"fire"},
+		expectedRatio: 2,
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "fire against water deals half",
+		attackerTypes: []string{"fire"},
+		targetTypes:   []string{"water"},
+		expectedRatio: 0.5,
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "normal against ghost deals none",
+		attackerTypes: []string{"normal"},
+		targetTypes:   []string{"ghost"},
+		expectedRatio: 0,
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "only the primary type attacks",
+		attackerTypes: []string{"normal", "water"},
+		targetTypes:   []string{"fire"},
+		expectedRatio: 1,
+	})
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			attacker := NewFighter(newTestPokemon("attacker", 80, 100, 80, 100, 80, 80))
+			target := NewFighter(newTestPokemon("target", 80, 100, 80, 100, 80, 80))
+
+			neutral := damage(&attacker, &target, nil, rand.New(rand.NewSource(1)))
+
+			attacker.Types = testCase.attackerTypes
+			target.Types = testCase.targetTypes
+			dmg := damage(&attacker, &target, nil, rand.New(rand.NewSource(1)))
+
+			assert.Equal(t, int(float64(neutral)*testCase.expectedRatio), dmg)
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package services
+
+import "pokemon/models"
+
+type CacheUsecase interface {
+	GetCacheStats() (res models.CacheStats, err error)
+}
+
+// GetCacheStats tells how well the PokeAPI cache is doing
+func (p *PokeUsecase) GetCacheStats() (res models.CacheStats, err error) {
+	res, err = p.PokeRepository.GetCacheStats()
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"errors"
+	"math/rand"
+	"pokemon/models"
+	"sort"
+	"strings"
+)
+
+const (
+	EngineLegacy    = "legacy"
+	EngineStats     = "stats"
+	EngineTypeAware = "type_aware"
+
+	// DefaultEngine runs the battles that don't ask for an engine
+	DefaultEngine = EngineTypeAware
+)
+
+var (
+	ErrInvalidEngine = errors.New("engine must be one of: " + strings.Join([]string{EngineLegacy, EngineStats, EngineTypeAware}, ", "))
+	ErrEngineChanged = errors.New("the battle was fought by another version of its engine and can't be replayed")
+
+	battleEngines = map[string]BattleEngine{
+		EngineLegacy:    LegacyEngine{},
+		EngineStats:     StatEngine{},
+		EngineTypeAware: TypeAwareEngine{},
+	}
+)
+
+// BattleEngine decides the placements of a battle. The name and version of
+// the engine are stored with every battle, the version has to change whenever
+// the same fighters and seed could end up with a different result.
+type BattleEngine interface {
+	Name() string
+	Version() int
+	// UsesMoves tells whether the fighters need their moves to fight
+	UsesMoves() bool
+	Fight(fighters []Fighter, policy MovePolicy, rng *rand.Rand) BattleResult
+}
+
+// LegacyEngine is the original rule: every pair of fighters compares its
+// attack stat, the stronger one scores 3 and a tie gives both 1
+type LegacyEngine struct{}
+
+func (LegacyEngine) Name() string    { return EngineLegacy }
+func (LegacyEngine) Version() int    { return 1 }
+func (LegacyEngine) UsesMoves() bool { return false }
+
+func (LegacyEngine) Fight(fighters []Fighter, policy MovePolicy, rng *rand.Rand) BattleResult {
+	scores := make([]int, len(fighters))
+	for i := range fighters {
+		for j := i + 1; j < len(fighters); j++ {
+			switch {
+			case fighters[i].Attack > fighters[j].Attack:
+				scores[i] += 3
+			case fighters[j].Attack > fighters[i].Attack:
+				scores[j] += 3
+			default:
+				scores[i]++
+				scores[j]++
+			}
+		}
+	}
+
+	order := make([]int, len(fighters))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return scores[order[i]] > scores[order[j]]
+	})
+
+	res := BattleResult{Turns: 1, Events: make([]models.BattleEvent, 0)}
+	for _, i := range order {
+		res.Placements = append(res.Placements, fighters[i].Name)
+	}
+	if len(order) > 1 {
+		res.Draw = scores[order[0]] == scores[order[1]]
+	}
+	return res
+}
+
+// StatEngine simulates the battle turn by turn from the stats alone, every
+// fighter uses the generic attack and types don't matter
+type StatEngine struct{}
+
+func (StatEngine) Name() string    { return EngineStats }
+func (StatEngine) Version() int    { return 1 }
+func (StatEngine) UsesMoves() bool { return false }
+
+func (StatEngine) Fight(fighters []Fighter, policy MovePolicy, rng *rand.Rand) BattleResult {
+	plain := make([]Fighter, 0, len(fighters))
+	for _, f := range fighters {
+		f.Types = nil
+		f.Moves = nil
+		plain = append(plain, f)
+	}
+	return Simulate(plain, nil, rng)
+}
+
+// TypeAwareEngine simulates the battle turn by turn with the moves of the
+// fighters and the type chart
+type TypeAwareEngine struct{}
+
+func (TypeAwareEngine) Name() string    { return EngineTypeAware }
+func (TypeAwareEngine) Version() int    { return 1 }
+func (TypeAwareEngine) UsesMoves() bool { return true }
+
+func (TypeAwareEngine) Fight(fighters []Fighter, policy MovePolicy, rng *rand.Rand) BattleResult {
+	return Simulate(fighters, policy, rng)
+}
+
+// battleEngine resolves an engine by name, an empty name is DefaultEngine
+func battleEngine(name string) (BattleEngine, error) {
+	if name == "" {
+		name = DefaultEngine
+	}
+
+	engine, ok := battleEngines[name]
+	if !ok {
+		return nil, ErrInvalidEngine
+	}
+	return engine, nil
+}
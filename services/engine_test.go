@@ -0,0 +1,98 @@
+package services
+
+import (
+	"math/rand"
+	"pokemon/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LegacyEngine(t *testing.T) {
+	type testCase struct {
+		name               string
+		fighters           []models.GetPokemon
+		expectedPlacements []string
+		expectedDraw       bool
+	}
+
+	var testTable []testCase
+
+	testTable = append(testTable, testCase{
+		name: "strongest attack wins",
+		fighters: []models.GetPokemon{
+			newTestPokemon("pikachu", 35, 55, 40, 50, 50, 90),
+			newTestPokemon("machamp", 90, 130, 80, 65, 85, 55),
+			newTestPokemon("magikarp", 20, 10, 55, 15, 20, 80),
+		},
+		expectedPlacements: []string{"machamp", "pikachu", "magikarp"},
+	})
+
+	testTable = append(testTable, testCase{
+		name: "ranks everybody by attack",
+		fighters: []models.GetPokemon{
+			newTestPokemon("magikarp", 20, 10, 55, 15, 20, 80),
+			newTestPokemon("charizard", 78, 84, 78, 109, 85, 100),
+			newTestPokemon("blastoise", 79, 83, 100, 85, 105, 78),
+			newTestPokemon("dragonite", 91, 134, 95, 100, 100, 80),
+			newTestPokemon("gyarados", 95, 125, 79, 60, 100, 81),
+			newTestPokemon("rhydon", 105, 130, 120, 45, 45, 40),
+		},
+		expectedPlacements: []string{"dragonite", "rhydon", "gyarados", "charizard", "blastoise", "magikarp"},
+	})
+
+	testTable = append(testTable, testCase{
+		name: "tied leaders draw",
+		fighters: []models.GetPokemon{
+			newTestPokemon("shuckle", 20, 10, 230, 10, 230, 5),
+			newTestPokemon("wall", 255, 10, 230, 10, 230, 5),
+		},
+		expectedPlacements: []string{"shuckle", "wall"},
+		expectedDraw:       true,
+	})
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			fighters := make([]Fighter, 0, len(testCase.fighters))
+			for _, p := range testCase.fighters {
+				fighters = append(fighters, NewFighter(p))
+			}
+
+			res := LegacyEngine{}.Fight(fighters, nil, rand.New(rand.NewSource(1)))
+
+			assert.Equal(t, testCase.expectedPlacements, res.Placements)
+			assert.Equal(t, testCase.expectedDraw, res.Draw)
+			assert.Empty(t, res.Events)
+		})
+	}
+}
+
+func Test_StatEngine(t *testing.T) {
+	squirtle := NewFighter(newTestPokemon("squirtle", 44, 48, 65, 50, 64, 43))
+	charmander := NewFighter(newTestPokemon("charmander", 39, 52, 43, 60, 50, 65))
+	plain := StatEngine{}.Fight([]Fighter{squirtle, charmander}, nil, rand.New(rand.NewSource(3)))
+
+	squirtle.Types = []string{"water"}
+	squirtle.Moves = []BattleMove{{Name: "water-gun", Type: "water", Power: 40, PP: 25, Special: true}}
+	charmander.Types = []string{"fire"}
+	typed := StatEngine{}.Fight([]Fighter{squirtle, charmander}, HighestDamagePolicy{}, rand.New(rand.NewSource(3)))
+
+	assert.Equal(t, plain, typed)
+	for _, event := range typed.Events {
+		assert.Equal(t, defaultMove, event.Move)
+	}
+}
+
+func Test_BattleEngine(t *testing.T) {
+	engine, err := battleEngine("")
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultEngine, engine.Name())
+
+	for name, engine := range battleEngines {
+		assert.Equal(t, name, engine.Name())
+		assert.Greater(t, engine.Version(), 0)
+	}
+
+	_, err = battleEngine("quantum")
+	assert.ErrorIs(t, err, ErrInvalidEngine)
+}
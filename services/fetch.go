@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"pokemon/models"
+	"pokemon/repository"
+	"sync"
+)
+
+// DefaultFetchWorkers bounds how many pokemon are looked up at once
+const DefaultFetchWorkers = 8
+
+// fetchConcurrently calls fetch for every index below n on a bounded pool of
+// workers. Once a call fails the context handed to fetch is cancelled, so
+// the calls in flight are aborted and the ones that haven't started yet are
+// skipped, and the failure of the lowest index is returned.
+func (p *PokeUsecase) fetchConcurrently(ctx context.Context, n int, fetch func(ctx context.Context, i int) error) error {
+	fetchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		errs    = make([]error, n)
+		jobs    = make(chan int)
+		wg      sync.WaitGroup
+		workers = p.fetchWorkers()
+	)
+
+	if workers > n {
+		workers = n
+	}
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if fetchCtx.Err() != nil {
+					continue
+				}
+
+				if errs[i] = fetch(fetchCtx, i); errs[i] != nil {
+					cancel()
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for i := 0; i < n; i++ {
+		select {
+		case jobs <- i:
+		case <-fetchCtx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	// a call aborted by the cancel above only failed because another one did
+	var aborted error
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if ctx.Err() == nil && errors.Is(err, context.Canceled) {
+			if aborted == nil {
+				aborted = err
+			}
+			continue
+		}
+		return err
+	}
+	if aborted != nil {
+		return aborted
+	}
+	return ctx.Err()
+}
+
+// fetchPokemons looks the names up concurrently, res and missing follow the
+// order of names. A pokemon that doesn't exist only sets missing.
+func (p *PokeUsecase) fetchPokemons(ctx context.Context, names []string) (res []models.GetPokemon, missing []bool, err error) {
+	res = make([]models.GetPokemon, len(names))
+	missing = make([]bool, len(names))
+
+	err = p.fetchConcurrently(ctx, len(names), func(ctx context.Context, i int) (err error) {
+		res[i], err = p.PokeRepository.GetPokemonByName(ctx, names[i])
+		if errors.Is(err, repository.ErrPokemonNotFound) {
+			missing[i] = true
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return res, missing, nil
+}
+
+// fetchSpecies looks up the species of every pokemon concurrently, in the
+// same order. A species that doesn't exist only sets missing.
+func (p *PokeUsecase) fetchSpecies(ctx context.Context, pokes []models.GetPokemon) (res []models.Species, missing []bool, err error) {
+	res = make([]models.Species, len(pokes))
+	missing = make([]bool, len(pokes))
+
+	err = p.fetchConcurrently(ctx, len(pokes), func(ctx context.Context, i int) (err error) {
+		name := pokes[i].Species.Name
+		if name == "" {
+			name = pokes[i].Name
+		}
+
+		res[i], err = p.PokeRepository.GetSpecies(ctx, name)
+		if errors.Is(err, repository.ErrSpeciesNotFound) {
+			missing[i] = true
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return res, missing, nil
+}
+
+// fetchMoves looks the moves up concurrently, res and missing follow the
+// order of names. A move that doesn't exist only sets missing.
+func (p *PokeUsecase) fetchMoves(ctx context.Context, names []string) (res []models.Move, missing []bool, err error) {
+	res = make([]models.Move, len(names))
+	missing = make([]bool, len(names))
+
+	err = p.fetchConcurrently(ctx, len(names), func(ctx context.Context, i int) (err error) {
+		res[i], err = p.PokeRepository.GetMove(ctx, names[i])
+		if errors.Is(err, repository.ErrMoveNotFound) {
+			missing[i] = true
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return res, missing, nil
+}
+
+func (p *PokeUsecase) fetchWorkers() int {
+	if p.FetchWorkers <= 0 {
+		return DefaultFetchWorkers
+	}
+	return p.FetchWorkers
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"pokemon/models"
+	"pokemon/pokeapi"
+	"pokemon/pokeapi/fakeapi"
+	"pokemon/repository"
+	postgres_mock "pokemon/repository/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PokemonUsecase_FetchPokemons(t *testing.T) {
+	type testCase struct {
+		name            string
+		workers         int
+		wantError       bool
+		expectedError   error
+		expectedNames   []string
+		expectedMissing []bool
+		onGetPokemon    func(mock *postgres_mock.MockPokemonRepo)
+	}
+
+	var (
+		testTable []testCase
+		names     = []string{"pikachu", "agumon", "eevee", "snorlax"}
+	)
+
+	testTable = append(testTable, testCase{
+		name:            "success keeps the order",
+		workers:         4,
+		expectedNames:   []string{"pikachu", "", "eevee", "snorlax"},
+		expectedMissing: []bool{false, true, false, false},
+		onGetPokemon: func(mock *postgres_mock.MockPokemonRepo) {
+			mock.EXPECT().GetPokemonByName(gomock.Any(), "agumon").Return(models.GetPokemon{}, repository.ErrPokemonNotFound).Times(1)
+			mock.EXPECT().GetPokemonByName(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, name string) (models.GetPokemon, error) {
+				// the later names come back first
+				if name == "pikachu" {
+					time.Sleep(5 * time.Millisecond)
+				}
+				return newTestPokemon(name, 50, 50, 50, 50, 50, 50), nil
+			}).Times(3)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "failed skips the rest",
+		workers:       1,
+		wantError:     true,
+		expectedError: errors.New("unexpected error"),
+		onGetPokemon: func(mock *postgres_mock.MockPokemonRepo) {
+			mock.EXPECT().GetPokemonByName(gomock.Any(), "pikachu").Return(newTestPokemon("pikachu", 35, 55, 40, 50, 50, 90), nil).Times(1)
+			mock.EXPECT().GetPokemonByName(gomock.Any(), "agumon").Return(models.GetPokemon{}, errors.New("unexpected error")).Times(1)
+		},
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			pokeRepo := postgres_mock.NewMockPokemonRepo(mockCtrl)
+			tc.onGetPokemon(pokeRepo)
+
+			p := PokeUsecase{
+				PokeRepository: pokeRepo,
+				FetchWorkers:   tc.workers,
+			}
+
+			res, missing, err := p.fetchPokemons(context.Background(), names)
+			if tc.wantError {
+				assert.EqualError(t, err, tc.expectedError.Error())
+			} else {
+				assert.Nil(t, err)
+				for i := range res {
+					assert.Equal(t, tc.expectedNames[i], res[i].Name)
+				}
+				assert.Equal(t, tc.expectedMissing, missing)
+			}
+		})
+	}
+}
+
+func Test_PokemonUsecase_FetchPokemons_AbortsSlowLookups(t *testing.T) {
+	fake := fakeapi.New()
+	fake.SetMode(fakeapi.ModeSlow, 10*time.Second)
+
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	var (
+		client   = pokeapi.NewClient(pokeapi.Config{BaseURL: srv.URL})
+		slowRepo = repository.NewPokeRepo(nil, repository.NewPokeAPIPokedex(client))
+		started  = make(chan struct{})
+		slowErr  = make(chan error, 1)
+		mockCtrl = gomock.NewController(t)
+	)
+	defer mockCtrl.Finish()
+
+	pokeRepo := postgres_mock.NewMockPokemonRepo(mockCtrl)
+	pokeRepo.EXPECT().GetPokemonByName(gomock.Any(), "pikachu").DoAndReturn(func(ctx context.Context, name string) (models.GetPokemon, error) {
+		close(started)
+		res, err := slowRepo.GetPokemonByName(ctx, name)
+		slowErr <- err
+		return res, err
+	}).Times(1)
+	pokeRepo.EXPECT().GetPokemonByName(gomock.Any(), "eevee").DoAndReturn(func(ctx context.Context, name string) (models.GetPokemon, error) {
+		<-started
+		return models.GetPokemon{}, errors.New("unexpected error")
+	}).Times(1)
+
+	p := PokeUsecase{
+		PokeRepository: pokeRepo,
+		FetchWorkers:   2,
+	}
+
+	begin := time.Now()
+	_, _, err := p.fetchPokemons(context.Background(), []string{"pikachu", "eevee"})
+
+	assert.EqualError(t, err, "unexpected error")
+	assert.Less(t, time.Since(begin), 5*time.Second)
+	assert.ErrorIs(t, <-slowErr, context.Canceled)
+}
+
+// Benchmark_FetchRoster fetches a full roster from a fake PokeAPI answering
+// every request after 10ms, one lookup at a time against the worker pool
+func Benchmark_FetchRoster(b *testing.B) {
+	fake := fakeapi.New()
+	fake.SetMode(fakeapi.ModeSlow, 10*time.Millisecond)
+
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	client := pokeapi.NewClient(pokeapi.Config{BaseURL: srv.URL})
+	repo := repository.NewPokeRepo(nil, repository.NewPokeAPIPokedex(client))
+	roster := fake.Pokemon()
+
+	for _, workers := range []int{1, DefaultFetchWorkers} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			p := PokeUsecase{
+				PokeRepository: repo,
+				FetchWorkers:   workers,
+			}
+
+			for i := 0; i < b.N; i++ {
+				if _, err := p.fetchRoster(context.Background(), roster); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"pokemon/models"
+	"strings"
+)
+
+// MaxGeneration is the latest generation a roster can be filtered on
+const MaxGeneration = 9
+
+var (
+	ErrInvalidFilter     = errors.New("invalid filter")
+	ErrNotEnoughPokemons = errors.New("not enough pokemon match the filter")
+)
+
+func validateFilter(filter models.RosterFilter) error {
+	if filter.Generation < 0 || filter.Generation > MaxGeneration {
+		return fmt.Errorf("%w: generation must be between 1 and %d", ErrInvalidFilter, MaxGeneration)
+	}
+	if _, ok := typeIndex[filter.Type]; filter.Type != "" && !ok {
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidFilter, filter.Type)
+	}
+	if filter.MinBST < 0 || filter.MaxBST < 0 {
+		return fmt.Errorf("%w: base stat total can't be negative", ErrInvalidFilter)
+	}
+	if filter.MaxBST > 0 && filter.MinBST > filter.MaxBST {
+		return fmt.Errorf("%w: min_bst can't be above max_bst", ErrInvalidFilter)
+	}
+	return nil
+}
+
+// needsSpecies tells whether the filter looks at the species document on top
+// of the pokemon one
+func needsSpecies(filter models.RosterFilter) bool {
+	return filter.Generation != 0 || filter.ExcludeLegendary || filter.ExcludeMythical
+}
+
+// matchesPokemon checks the type and the base stat total
+func matchesPokemon(filter models.RosterFilter, poke models.GetPokemon) bool {
+	if filter.Type != "" {
+		found := false
+		for _, t := range poke.Types {
+			found = found || t.Type.Name == filter.Type
+		}
+		if !found {
+			return false
+		}
+	}
+
+	bst := baseStatTotal(poke)
+	if filter.MinBST > 0 && bst < filter.MinBST {
+		return false
+	}
+	if filter.MaxBST > 0 && bst > filter.MaxBST {
+		return false
+	}
+	return true
+}
+
+// matchesSpecies checks the generation and the legendary and mythical flags
+func matchesSpecies(filter models.RosterFilter, species models.Species) bool {
+	if filter.Generation != 0 && generationNumber(species.Generation.Name) != filter.Generation {
+		return false
+	}
+	if filter.ExcludeLegendary && species.IsLegendary {
+		return false
+	}
+	if filter.ExcludeMythical && species.IsMythical {
+		return false
+	}
+	return true
+}
+
+// generationNumber turns PokeAPI's generation-iv into 4, 0 when it can't
+func generationNumber(name string) int {
+	numerals := map[byte]int{'i': 1, 'v': 5, 'x': 10}
+
+	roman := strings.TrimPrefix(name, "generation-")
+	if roman == name || roman == "" {
+		return 0
+	}
+
+	total := 0
+	for i := 0; i < len(roman); i++ {
+		value, ok := numerals[roman[i]]
+		if !ok {
+			return 0
+		}
+		if i+1 < len(roman) && numerals[roman[i+1]] > value {
+			total -= value
+		} else {
+			total += value
+		}
+	}
+	return total
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"math/rand"
+	"net/http/httptest"
+	"pokemon/models"
+	"pokemon/pokeapi"
+	"pokemon/pokeapi/fakeapi"
+	"pokemon/repository"
+	postgres_mock "pokemon/repository/mocks"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GenerationNumber(t *testing.T) {
+	for name, expected := range map[string]int{
+		"generation-i":    1,
+		"generation-iv":   4,
+		"generation-viii": 8,
+		"generation-ix":   9,
+		"generation-":     0,
+		"kanto":           0,
+	} {
+		assert.Equal(t, expected, generationNumber(name), name)
+	}
+}
+
+func Test_ValidateFilter(t *testing.T) {
+	assert.Nil(t, validateFilter(models.RosterFilter{}))
+	assert.Nil(t, validateFilter(models.RosterFilter{Generation: 4, Type: "steel", MinBST: 300, MaxBST: 500}))
+
+	assert.ErrorIs(t, validateFilter(models.RosterFilter{Generation: MaxGeneration + 1}), ErrInvalidFilter)
+	assert.ErrorIs(t, validateFilter(models.RosterFilter{Type: "sound"}), ErrInvalidFilter)
+	assert.ErrorIs(t, validateFilter(models.RosterFilter{MinBST: -1}), ErrInvalidFilter)
+	assert.ErrorIs(t, validateFilter(models.RosterFilter{MinBST: 500, MaxBST: 300}), ErrInvalidFilter)
+}
+
+func Test_PokemonUsecase_DrawRoster_Filter(t *testing.T) {
+	type testCase struct {
+		name          string
+		count         int
+		filter        models.RosterFilter
+		wantError     bool
+		expectedError error
+		expectedPool  []string
+	}
+
+	var testTable []testCase
+
+	testTable = append(testTable, testCase{
+		name:          "failed not enough in generation",
+		count:         2,
+		filter:        models.RosterFilter{Generation: 4},
+		wantError:     true,
+		expectedError: ErrNotEnoughPokemons,
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "failed not enough without legendaries",
+		count:         2,
+		filter:        models.RosterFilter{Type: "psychic", ExcludeLegendary: true},
+		wantError:     true,
+		expectedError: ErrNotEnoughPokemons,
+	})
+
+	testTable = append(testTable, testCase{
+		name:         "success type",
+		count:        2,
+		filter:       models.RosterFilter{Type: "poison"},
+		expectedPool: []string{"bulbasaur", "gengar"},
+	})
+
+	testTable = append(testTable, testCase{
+		name:         "success base stat total without legendaries and mythicals",
+		count:        2,
+		filter:       models.RosterFilter{MinBST: 500, ExcludeLegendary: true, ExcludeMythical: true},
+		expectedPool: []string{"gengar", "lucario", "snorlax"},
+	})
+
+	testTable = append(testTable, testCase{
+		name:         "success generation",
+		count:        3,
+		filter:       models.RosterFilter{Generation: 1, MaxBST: 320},
+		expectedPool: []string{"bulbasaur", "charmander", "pikachu", "squirtle"},
+	})
+
+	fake := fakeapi.New()
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	client := pokeapi.NewClient(pokeapi.Config{BaseURL: srv.URL})
+	p := PokeUsecase{
+		PokeRepository: repository.NewPokeRepo(nil, repository.NewPokeAPIPokedex(client)),
+		FetchWorkers:   3,
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := p.drawRoster(context.Background(), tc.count, tc.filter, rand.New(rand.NewSource(42)))
+			if tc.wantError {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Len(t, res, tc.count)
+
+			seen := map[string]bool{}
+			for _, poke := range res {
+				assert.Contains(t, tc.expectedPool, poke.Name)
+				assert.False(t, seen[poke.Name], "drawn twice")
+				seen[poke.Name] = true
+			}
+
+			again, err := p.drawRoster(context.Background(), tc.count, tc.filter, rand.New(rand.NewSource(42)))
+			assert.Nil(t, err)
+			assert.Equal(t, res, again)
+		})
+	}
+
+}
+
+func Test_PokemonUsecase_DrawRoster_SpeciesUnavailable(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	all := models.AllPokemon{Count: 2}
+	for _, name := range []string{"pikachu", "eevee"} {
+		all.Results = append(all.Results, struct {
+			Name string `json:"name"`
+			Url  string `json:"url"`
+		}{Name: name})
+	}
+
+	pokeRepo := postgres_mock.NewMockPokemonRepo(mockCtrl)
+	pokeRepo.EXPECT().GetAllPokemons(gomock.Any()).Return(all, nil).Times(1)
+	pokeRepo.EXPECT().GetPokemonByName(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, name string) (models.GetPokemon, error) {
+		return newTestPokemon(name, 50, 50, 50, 50, 50, 50), nil
+	}).AnyTimes()
+	pokeRepo.EXPECT().GetSpecies(gomock.Any(), gomock.Any()).Return(models.Species{}, repository.ErrSpeciesUnavailable).AnyTimes()
+
+	p := PokeUsecase{
+		PokeRepository: pokeRepo,
+	}
+
+	// an offline pokedex refuses the filter instead of matching nothing
+	_, err := p.drawRoster(context.Background(), 2, models.RosterFilter{Generation: 1}, rand.New(rand.NewSource(42)))
+	assert.ErrorIs(t, err, repository.ErrSpeciesUnavailable)
+}
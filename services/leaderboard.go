@@ -0,0 +1,238 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"pokemon/models"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	WindowAllTime = "all_time"
+	WindowDaily   = "daily"
+	WindowWeekly  = "weekly"
+	WindowMonthly = "monthly"
+	WindowCustom  = "custom"
+
+	MovementUp   = "up"
+	MovementDown = "down"
+	MovementSame = "same"
+	MovementNew  = "new"
+
+	DefaultLeaderboardPageSize = 20
+	MaxLeaderboardPageSize     = 100
+
+	leaderboardCursorPrefix = "scores:"
+)
+
+var ErrInvalidLeaderboard = errors.New("invalid leaderboard")
+
+// leaderboardQuery is a validated RequestLeaderboard, previous is nil when
+// there is no window before to compare with
+type leaderboardQuery struct {
+	window   string
+	current  models.ScoreRange
+	previous *models.ScoreRange
+	offset   int
+	limit    int
+}
+
+// GetPokemonScore ranks the pokemon by the scores of the battles started in
+// the window and tells how each moved since the window before
+func (p *PokeUsecase) GetPokemonScore(input models.RequestLeaderboard) (res models.Leaderboard, err error) {
+	q, err := leaderboard(input, p.now())
+	if err != nil {
+		return res, err
+	}
+
+	scores, err := p.PokeRepository.GetPokemonScore(q.current)
+	if err != nil {
+		return res, err
+	}
+	entries := rankScores(scores)
+
+	if q.previous != nil {
+		scores, err = p.PokeRepository.GetPokemonScore(*q.previous)
+		if err != nil {
+			return res, err
+		}
+		compareRanks(entries, rankScores(scores))
+	}
+
+	res = models.Leaderboard{
+		Window:  q.window,
+		Entries: []models.LeaderboardEntry{},
+	}
+	if !q.current.From.IsZero() {
+		res.From = q.current.From.Format(searchDateLayout)
+		res.To = q.current.Before.AddDate(0, 0, -1).Format(searchDateLayout)
+	}
+
+	if q.offset < len(entries) {
+		end := q.offset + q.limit
+		if end < len(entries) {
+			res.NextCursor = encodeLeaderboardCursor(end)
+		} else {
+			end = len(entries)
+		}
+		res.Entries = entries[q.offset:end]
+	}
+	return res, nil
+}
+
+// leaderboard validates the query string of GET /scores, the windows are
+// whole days in UTC
+func leaderboard(input models.RequestLeaderboard, now time.Time) (res leaderboardQuery, err error) {
+	var from, to time.Time
+
+	if input.From != "" {
+		if from, err = time.Parse(searchDateLayout, input.From); err != nil {
+			return res, fmt.Errorf("%w: from must look like %s", ErrInvalidLeaderboard, searchDateLayout)
+		}
+	}
+	if input.To != "" {
+		if to, err = time.Parse(searchDateLayout, input.To); err != nil {
+			return res, fmt.Errorf("%w: to must look like %s", ErrInvalidLeaderboard, searchDateLayout)
+		}
+	}
+
+	res.window = strings.ToLower(input.Window)
+	if res.window == "" {
+		res.window = WindowAllTime
+		if input.From != "" || input.To != "" {
+			res.window = WindowCustom
+		}
+	}
+
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if from.IsZero() {
+		from = today
+	}
+
+	switch res.window {
+	case WindowAllTime:
+		if input.From != "" || input.To != "" {
+			return res, fmt.Errorf("%w: an all_time window takes no from nor to", ErrInvalidLeaderboard)
+		}
+	case WindowDaily, WindowWeekly, WindowMonthly:
+		if input.To != "" {
+			return res, fmt.Errorf("%w: a %s window takes no to, from picks the window", ErrInvalidLeaderboard, res.window)
+		}
+
+		var before, previous time.Time
+		switch res.window {
+		case WindowDaily:
+			before, previous = from.AddDate(0, 0, 1), from.AddDate(0, 0, -1)
+		case WindowWeekly:
+			// weeks start on monday
+			from = from.AddDate(0, 0, -(int(from.Weekday())+6)%7)
+			before, previous = from.AddDate(0, 0, 7), from.AddDate(0, 0, -7)
+		case WindowMonthly:
+			from = time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+			before, previous = from.AddDate(0, 1, 0), from.AddDate(0, -1, 0)
+		}
+
+		res.current = models.ScoreRange{From: from, Before: before}
+		res.previous = &models.ScoreRange{From: previous, Before: from}
+	case WindowCustom:
+		if input.From == "" {
+			return res, fmt.Errorf("%w: a custom window needs from", ErrInvalidLeaderboard)
+		}
+		if to.IsZero() {
+			to = today
+		}
+		if to.Before(from) {
+			return res, fmt.Errorf("%w: from can't be after to", ErrInvalidLeaderboard)
+		}
+
+		// the whole to day is included, the window before is as long
+		before := to.AddDate(0, 0, 1)
+		days := int(before.Sub(from).Hours() / 24)
+		res.current = models.ScoreRange{From: from, Before: before}
+		res.previous = &models.ScoreRange{From: from.AddDate(0, 0, -days), Before: from}
+	default:
+		return res, fmt.Errorf("%w: window must be %s, %s, %s, %s or %s", ErrInvalidLeaderboard,
+			WindowAllTime, WindowDaily, WindowWeekly, WindowMonthly, WindowCustom)
+	}
+
+	res.limit = input.Limit
+	switch {
+	case res.limit == 0:
+		res.limit = DefaultLeaderboardPageSize
+	case res.limit < 0 || res.limit > MaxLeaderboardPageSize:
+		return res, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidLeaderboard, MaxLeaderboardPageSize)
+	}
+
+	if input.Cursor != "" {
+		if res.offset, err = decodeLeaderboardCursor(input.Cursor); err != nil {
+			return res, err
+		}
+	}
+
+	return res, nil
+}
+
+// rankScores orders the scores highest first, level pokemon share the rank
+func rankScores(scores []models.DetailPlayers) (res []models.LeaderboardEntry) {
+	scores = append([]models.DetailPlayers(nil), scores...)
+	sort.SliceStable(scores, func(i, j int) bool {
+		if scores[i].Scores != scores[j].Scores {
+			return scores[i].Scores > scores[j].Scores
+		}
+		return scores[i].Name < scores[j].Name
+	})
+
+	for i, score := range scores {
+		entry := models.LeaderboardEntry{Rank: i + 1, Name: score.Name, Scores: score.Scores}
+		if i > 0 && score.Scores == scores[i-1].Scores {
+			entry.Rank = res[i-1].Rank
+		}
+		res = append(res, entry)
+	}
+	return res
+}
+
+// compareRanks fills the previous rank and the movement of every entry
+func compareRanks(entries, previous []models.LeaderboardEntry) {
+	ranks := make(map[string]int, len(previous))
+	for _, entry := range previous {
+		ranks[entry.Name] = entry.Rank
+	}
+
+	for i := range entries {
+		rank, ok := ranks[entries[i].Name]
+		switch {
+		case !ok:
+			entries[i].Movement = MovementNew
+		case rank > entries[i].Rank:
+			entries[i].Movement = MovementUp
+		case rank < entries[i].Rank:
+			entries[i].Movement = MovementDown
+		default:
+			entries[i].Movement = MovementSame
+		}
+		entries[i].PreviousRank = rank
+	}
+}
+
+func encodeLeaderboardCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(leaderboardCursorPrefix + strconv.Itoa(offset)))
+}
+
+func decodeLeaderboardCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), leaderboardCursorPrefix) {
+		return 0, fmt.Errorf("%w: bad cursor", ErrInvalidLeaderboard)
+	}
+
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(raw), leaderboardCursorPrefix))
+	if err != nil || offset <= 0 {
+		return 0, fmt.Errorf("%w: bad cursor", ErrInvalidLeaderboard)
+	}
+	return offset, nil
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"errors"
+	"pokemon/models"
+	postgres_mock "pokemon/repository/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PokemonUsecase_GetPokemonScore(t *testing.T) {
+	type testCase struct {
+		name              string
+		input             models.RequestLeaderboard
+		wantError         bool
+		expectedResult    models.Leaderboard
+		expectedError     error
+		onGetPokemonScore func(mock *postgres_mock.MockPokemonRepo)
+	}
+
+	var (
+		testTable []testCase
+		// a wednesday
+		now  = time.Date(2022, 10, 12, 15, 4, 5, 0, time.UTC)
+		week = models.ScoreRange{
+			From:   time.Date(2022, 10, 10, 0, 0, 0, 0, time.UTC),
+			Before: time.Date(2022, 10, 17, 0, 0, 0, 0, time.UTC),
+		}
+		lastWeek = models.ScoreRange{
+			From:   time.Date(2022, 10, 3, 0, 0, 0, 0, time.UTC),
+			Before: time.Date(2022, 10, 10, 0, 0, 0, 0, time.UTC),
+		}
+		scores = []models.DetailPlayers{
+			{Name: "snorlax", Scores: 3},
+			{Name: "pikachu", Scores: 5},
+			{Name: "eevee", Scores: 5},
+			{Name: "mew", Scores: 1},
+		}
+	)
+
+	testTable = append(testTable, testCase{
+		name:          "failed unexpected error",
+		wantError:     true,
+		expectedError: errors.New("unexpected error"),
+		onGetPokemonScore: func(mock *postgres_mock.MockPokemonRepo) {
+			mock.EXPECT().GetPokemonScore(models.ScoreRange{}).Return(nil, errors.New("unexpected error")).Times(1)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "failed invalid window",
+		input:         models.RequestLeaderboard{Window: "yearly"},
+		wantError:     true,
+		expectedError: errors.New("invalid leaderboard: window must be all_time, daily, weekly, monthly or custom"),
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "failed bad cursor",
+		input:         models.RequestLeaderboard{Cursor: "nope"},
+		wantError:     true,
+		expectedError: errors.New("invalid leaderboard: bad cursor"),
+	})
+
+	testTable = append(testTable, testCase{
+		name:  "success all time ties share the rank",
+		input: models.RequestLeaderboard{},
+		onGetPokemonScore: func(mock *postgres_mock.MockPokemonRepo) {
+			mock.EXPECT().GetPokemonScore(models.ScoreRange{}).Return(scores, nil).Times(1)
+		},
+		expectedResult: models.Leaderboard{
+			Window: WindowAllTime,
+			Entries: []models.LeaderboardEntry{
+				{Rank: 1, Name: "eevee", Scores: 5},
+				{Rank: 1, Name: "pikachu", Scores: 5},
+				{Rank: 3, Name: "snorlax", Scores: 3},
+				{Rank: 4, Name: "mew", Scores: 1},
+			},
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:  "success weekly with movers",
+		input: models.RequestLeaderboard{Window: "weekly"},
+		onGetPokemonScore: func(mock *postgres_mock.MockPokemonRepo) {
+			mock.EXPECT().GetPokemonScore(week).Return(scores, nil).Times(1)
+			mock.EXPECT().GetPokemonScore(lastWeek).Return([]models.DetailPlayers{
+				{Name: "snorlax", Scores: 9},
+				{Name: "pikachu", Scores: 4},
+				{Name: "eevee", Scores: 2},
+			}, nil).Times(1)
+		},
+		expectedResult: models.Leaderboard{
+			Window: WindowWeekly,
+			From:   "2022-10-10",
+			To:     "2022-10-16",
+			Entries: []models.LeaderboardEntry{
+				{Rank: 1, Name: "eevee", Scores: 5, PreviousRank: 3, Movement: MovementUp},
+				{Rank: 1, Name: "pikachu", Scores: 5, PreviousRank: 2, Movement: MovementUp},
+				{Rank: 3, Name: "snorlax", Scores: 3, PreviousRank: 1, Movement: MovementDown},
+				{Rank: 4, Name: "mew", Scores: 1, Movement: MovementNew},
+			},
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:  "success pages",
+		input: models.RequestLeaderboard{Limit: 2, Cursor: encodeLeaderboardCursor(1)},
+		onGetPokemonScore: func(mock *postgres_mock.MockPokemonRepo) {
+			mock.EXPECT().GetPokemonScore(models.ScoreRange{}).Return(scores, nil).Times(1)
+		},
+		expectedResult: models.Leaderboard{
+			Window: WindowAllTime,
+			Entries: []models.LeaderboardEntry{
+				{Rank: 1, Name: "pikachu", Scores: 5},
+				{Rank: 3, Name: "snorlax", Scores: 3},
+			},
+			NextCursor: encodeLeaderboardCursor(3),
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:  "success past the last page",
+		input: models.RequestLeaderboard{Cursor: encodeLeaderboardCursor(10)},
+		onGetPokemonScore: func(mock *postgres_mock.MockPokemonRepo) {
+			mock.EXPECT().GetPokemonScore(models.ScoreRange{}).Return(scores, nil).Times(1)
+		},
+		expectedResult: models.Leaderboard{
+			Window:  WindowAllTime,
+			Entries: []models.LeaderboardEntry{},
+		},
+	})
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			pokeRepo := postgres_mock.NewMockPokemonRepo(mockCtrl)
+
+			if testCase.onGetPokemonScore != nil {
+				testCase.onGetPokemonScore(pokeRepo)
+			}
+
+			usecase := PokeUsecase{
+				PokeRepository: pokeRepo,
+				Now: func() time.Time {
+					return now
+				},
+			}
+
+			data, serr := usecase.GetPokemonScore(testCase.input)
+
+			if testCase.wantError {
+				assert.EqualError(t, serr, testCase.expectedError.Error())
+			} else {
+				assert.Nil(t, serr)
+				assert.Equal(t, testCase.expectedResult, data)
+			}
+		})
+	}
+}
+
+func Test_Leaderboard(t *testing.T) {
+	now := time.Date(2022, 10, 12, 15, 4, 5, 0, time.UTC)
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2022, month, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		input    models.RequestLeaderboard
+		current  models.ScoreRange
+		previous *models.ScoreRange
+		wantErr  bool
+	}{
+		{name: "all time", input: models.RequestLeaderboard{}},
+		{name: "daily today", input: models.RequestLeaderboard{Window: "daily"},
+			current: models.ScoreRange{From: day(10, 12), Before: day(10, 13)}, previous: &models.ScoreRange{From: day(10, 11), Before: day(10, 12)}},
+		{name: "weekly from a sunday", input: models.RequestLeaderboard{Window: "weekly", From: "2022-10-02"},
+			current: models.ScoreRange{From: day(9, 26), Before: day(10, 3)}, previous: &models.ScoreRange{From: day(9, 19), Before: day(9, 26)}},
+		{name: "monthly", input: models.RequestLeaderboard{Window: "MONTHLY", From: "2022-03-31"},
+			current: models.ScoreRange{From: day(3, 1), Before: day(4, 1)}, previous: &models.ScoreRange{From: day(2, 1), Before: day(3, 1)}},
+		{name: "custom from to", input: models.RequestLeaderboard{From: "2022-10-01", To: "2022-10-03"},
+			current: models.ScoreRange{From: day(10, 1), Before: day(10, 4)}, previous: &models.ScoreRange{From: day(9, 28), Before: day(10, 1)}},
+		{name: "custom to today", input: models.RequestLeaderboard{Window: "custom", From: "2022-10-12"},
+			current: models.ScoreRange{From: day(10, 12), Before: day(10, 13)}, previous: &models.ScoreRange{From: day(10, 11), Before: day(10, 12)}},
+		{name: "all time with from", input: models.RequestLeaderboard{Window: "all_time", From: "2022-10-01"}, wantErr: true},
+		{name: "weekly with to", input: models.RequestLeaderboard{Window: "weekly", To: "2022-10-01"}, wantErr: true},
+		{name: "custom without from", input: models.RequestLeaderboard{Window: "custom"}, wantErr: true},
+		{name: "custom backwards", input: models.RequestLeaderboard{From: "2022-10-03", To: "2022-10-01"}, wantErr: true},
+		{name: "bad date", input: models.RequestLeaderboard{From: "10/01/2022"}, wantErr: true},
+		{name: "limit too big", input: models.RequestLeaderboard{Limit: MaxLeaderboardPageSize + 1}, wantErr: true},
+		{name: "negative limit", input: models.RequestLeaderboard{Limit: -1}, wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			q, err := leaderboard(tc.input, now)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidLeaderboard)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.current, q.current)
+			assert.Equal(t, tc.previous, q.previous)
+		})
+	}
+}
@@ -0,0 +1,364 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"pokemon/models"
+	"sort"
+)
+
+const (
+	MinLeagueMembers = 2
+	MaxLeagueMembers = 20
+
+	TieBreakHeadToHead = "head_to_head"
+	TieBreakWins       = "wins"
+	TieBreakLosses     = "losses"
+	TieBreakDraws      = "draws"
+	TieBreakName       = "name"
+
+	// tieBreakPoints always ranks the standings first, it can't be configured
+	tieBreakPoints = "points"
+)
+
+var (
+	ErrInvalidLeagueRoster = fmt.Errorf("league roster must have between %d and %d pokemons", MinLeagueMembers, MaxLeagueMembers)
+	ErrInvalidTieBreaker   = errors.New("tie breakers must be head_to_head, wins, losses, draws or name")
+	ErrLeagueFinished      = errors.New("every round of the league is already played")
+)
+
+var (
+	tieBreakers        = map[string]bool{TieBreakHeadToHead: true, TieBreakWins: true, TieBreakLosses: true, TieBreakDraws: true, TieBreakName: true}
+	defaultTieBreakers = []string{TieBreakHeadToHead, TieBreakWins, TieBreakName}
+)
+
+type LeagueUsecase interface {
+	CreateLeague(ctx context.Context, input models.RequestLeague) (res models.LeagueResponse, err error)
+	PlayLeagueRound(ctx context.Context, leagueID int) (res models.LeagueResponse, err error)
+	GetLeague(leagueID int) (res models.LeagueResponse, err error)
+	GetLeagueStandings(leagueID int) (res []models.Standing, err error)
+}
+
+// CreateLeague schedules a season where every member meets every other once
+func (p *PokeUsecase) CreateLeague(ctx context.Context, input models.RequestLeague) (res models.LeagueResponse, err error) {
+	var seed int64
+
+	if len(input.Roster) < MinLeagueMembers || len(input.Roster) > MaxLeagueMembers {
+		return res, ErrInvalidLeagueRoster
+	}
+
+	if len(input.TieBreakers) == 0 {
+		input.TieBreakers = defaultTieBreakers
+	}
+	for _, tb := range input.TieBreakers {
+		if !tieBreakers[tb] {
+			return res, ErrInvalidTieBreaker
+		}
+	}
+
+	if input.Seed != nil {
+		seed = *input.Seed
+	} else {
+		seed = p.newSeed()
+	}
+
+	members, err := p.fetchRoster(ctx, input.Roster)
+	if err != nil {
+		return res, err
+	}
+
+	names := make([]string, 0, len(members))
+	for _, member := range members {
+		names = append(names, member.Name)
+	}
+	rng := rand.New(rand.NewSource(seed))
+	rng.Shuffle(len(names), func(i, j int) {
+		names[i], names[j] = names[j], names[i]
+	})
+
+	schedule := roundRobin(names)
+
+	league := models.League{
+		Name:        input.Name,
+		Seed:        seed,
+		Round:       1,
+		Rounds:      len(schedule),
+		PointsWin:   intOr(input.PointsWin, 3),
+		PointsDraw:  intOr(input.PointsDraw, 1),
+		PointsLoss:  intOr(input.PointsLoss, 0),
+		TieBreakers: input.TieBreakers,
+		CreatedAt:   p.now(),
+	}
+
+	fixtures := make([]models.LeagueFixture, 0)
+	for i, pairs := range schedule {
+		for _, pair := range pairs {
+			fixtures = append(fixtures, models.LeagueFixture{
+				Round:    i + 1,
+				PokemonA: pair[0],
+				PokemonB: pair[1],
+			})
+		}
+	}
+
+	Id, err := p.PokeRepository.PostLeague(league, fixtures)
+	if err != nil {
+		return res, err
+	}
+
+	return p.GetLeague(int(Id))
+}
+
+// PlayLeagueRound fights every fixture of the current round of the season
+func (p *PokeUsecase) PlayLeagueRound(ctx context.Context, leagueID int) (res models.LeagueResponse, err error) {
+	league, err := p.PokeRepository.GetLeague(leagueID)
+	if err != nil {
+		return res, err
+	}
+	if league.Round > league.Rounds {
+		return res, ErrLeagueFinished
+	}
+
+	fixtures, err := p.PokeRepository.GetLeagueFixtures(leagueID)
+	if err != nil {
+		return res, err
+	}
+
+	for _, fixture := range fixtures {
+		if fixture.Round != league.Round || fixture.Played {
+			continue
+		}
+
+		fight, err := p.fetchRoster(ctx, []string{fixture.PokemonA, fixture.PokemonB})
+		if err != nil {
+			return res, err
+		}
+
+		seed := league.Seed + int64(fixture.FixtureID)
+		result, err := p.simulateRoster(ctx, fight, seed, DefaultEngine, MovePolicyRandom)
+		if err != nil {
+			return res, err
+		}
+
+		// a fixture played by a concurrent request keeps its result and the
+		// round is left to that request
+		if _, err = p.saveFixtureBattle(fixture.FixtureID, fight, seed, result, p.now()); err != nil {
+			return res, err
+		}
+	}
+
+	err = p.PokeRepository.AdvanceLeague(leagueID, league.Round)
+	if err != nil {
+		return res, err
+	}
+
+	return p.GetLeague(leagueID)
+}
+
+func (p *PokeUsecase) GetLeague(leagueID int) (res models.LeagueResponse, err error) {
+	league, err := p.PokeRepository.GetLeague(leagueID)
+	if err != nil {
+		return res, err
+	}
+
+	fixtures, err := p.PokeRepository.GetLeagueFixtures(leagueID)
+	if err != nil {
+		return res, err
+	}
+
+	res = models.LeagueResponse{
+		League:    league,
+		Standings: standings(league, fixtures),
+		Fixtures:  fixtures,
+	}
+	return res, nil
+}
+
+func (p *PokeUsecase) GetLeagueStandings(leagueID int) (res []models.Standing, err error) {
+	league, err := p.GetLeague(leagueID)
+	if err != nil {
+		return nil, err
+	}
+	return league.Standings, nil
+}
+
+// roundRobin pairs the members with the circle method, the first member
+// stays in place while the others rotate around it. An odd number of members
+// gets a bye, whoever meets it sits the round out.
+func roundRobin(members []string) (rounds [][][2]string) {
+	ring := append([]string(nil), members...)
+	if len(ring)%2 == 1 {
+		ring = append(ring, "")
+	}
+
+	n := len(ring)
+	for r := 0; r < n-1; r++ {
+		pairs := make([][2]string, 0, n/2)
+		for i := 0; i < n/2; i++ {
+			a, b := ring[i], ring[n-1-i]
+			if a == "" || b == "" {
+				continue
+			}
+			// switch sides of the fixed member so it isn't always first
+			if i == 0 && r%2 == 1 {
+				a, b = b, a
+			}
+			pairs = append(pairs, [2]string{a, b})
+		}
+		rounds = append(rounds, pairs)
+
+		ring = append([]string{ring[0], ring[n-1]}, ring[1:n-1]...)
+	}
+
+	return rounds
+}
+
+// standings builds the table from the played fixtures, ranking by points
+// first and then by the tie breakers of the league in order
+func standings(league models.League, fixtures []models.LeagueFixture) []models.Standing {
+	var (
+		rows  = make(map[string]*models.Standing)
+		table = make([]*models.Standing, 0)
+	)
+
+	row := func(name string) *models.Standing {
+		if rows[name] == nil {
+			rows[name] = &models.Standing{Name: name}
+			table = append(table, rows[name])
+		}
+		return rows[name]
+	}
+
+	for _, fixture := range fixtures {
+		a, b := row(fixture.PokemonA), row(fixture.PokemonB)
+		if !fixture.Played {
+			continue
+		}
+
+		a.Played++
+		b.Played++
+		switch {
+		case fixture.Draw:
+			a.Draws++
+			b.Draws++
+			a.Points += league.PointsDraw
+			b.Points += league.PointsDraw
+		case fixture.Winner == fixture.PokemonA:
+			a.Wins++
+			b.Losses++
+			a.Points += league.PointsWin
+			b.Points += league.PointsLoss
+		default:
+			b.Wins++
+			a.Losses++
+			b.Points += league.PointsWin
+			a.Points += league.PointsLoss
+		}
+	}
+
+	breakers := append([]string{tieBreakPoints}, league.TieBreakers...)
+
+	res := make([]models.Standing, 0, len(table))
+	for _, group := range breakTies(table, breakers, league, fixtures) {
+		rank := len(res) + 1
+		for _, s := range group {
+			s.Rank = rank
+			res = append(res, *s)
+		}
+	}
+	return res
+}
+
+// breakTies orders the group by the first tie breaker and hands every run of
+// members that are still level to the next one, members left level after the
+// last tie breaker share a group and therefore a rank
+func breakTies(group []*models.Standing, breakers []string, league models.League, fixtures []models.LeagueFixture) [][]*models.Standing {
+	if len(group) <= 1 || len(breakers) == 0 {
+		return [][]*models.Standing{group}
+	}
+
+	if breakers[0] == TieBreakName {
+		sort.SliceStable(group, func(i, j int) bool {
+			return group[i].Name < group[j].Name
+		})
+		res := make([][]*models.Standing, 0, len(group))
+		for _, s := range group {
+			res = append(res, []*models.Standing{s})
+		}
+		return res
+	}
+
+	key := tieBreakKeys(breakers[0], group, league, fixtures)
+	sort.SliceStable(group, func(i, j int) bool {
+		return key[group[i].Name] > key[group[j].Name]
+	})
+
+	res := make([][]*models.Standing, 0)
+	for start := 0; start < len(group); {
+		end := start + 1
+		for end < len(group) && key[group[end].Name] == key[group[start].Name] {
+			end++
+		}
+		res = append(res, breakTies(group[start:end], breakers[1:], league, fixtures)...)
+		start = end
+	}
+	return res
+}
+
+// tieBreakKeys scores every member of the group for the tie breaker, higher is better
+func tieBreakKeys(breaker string, group []*models.Standing, league models.League, fixtures []models.LeagueFixture) map[string]int {
+	key := make(map[string]int, len(group))
+
+	switch breaker {
+	case tieBreakPoints:
+		for _, s := range group {
+			key[s.Name] = s.Points
+		}
+	case TieBreakWins:
+		for _, s := range group {
+			key[s.Name] = s.Wins
+		}
+	case TieBreakLosses:
+		for _, s := range group {
+			key[s.Name] = -s.Losses
+		}
+	case TieBreakDraws:
+		for _, s := range group {
+			key[s.Name] = s.Draws
+		}
+	case TieBreakHeadToHead:
+		// points earned in the fixtures between members of the group only
+		inGroup := make(map[string]bool, len(group))
+		for _, s := range group {
+			inGroup[s.Name] = true
+			key[s.Name] = 0
+		}
+		for _, fixture := range fixtures {
+			if !fixture.Played || !inGroup[fixture.PokemonA] || !inGroup[fixture.PokemonB] {
+				continue
+			}
+			switch {
+			case fixture.Draw:
+				key[fixture.PokemonA] += league.PointsDraw
+				key[fixture.PokemonB] += league.PointsDraw
+			case fixture.Winner == fixture.PokemonA:
+				key[fixture.PokemonA] += league.PointsWin
+				key[fixture.PokemonB] += league.PointsLoss
+			default:
+				key[fixture.PokemonB] += league.PointsWin
+				key[fixture.PokemonA] += league.PointsLoss
+			}
+		}
+	}
+
+	return key
+}
+
+func intOr(v *int, def int) int {
+	if v == nil {
+		return def
+	}
+	return *v
+}
@@ -0,0 +1,307 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"pokemon/models"
+	"pokemon/repository"
+	postgres_mock "pokemon/repository/mocks"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RoundRobin(t *testing.T) {
+	for _, n := range []int{2, 4, 5, 8} {
+		t.Run(fmt.Sprintf("%d members", n), func(t *testing.T) {
+			var members []string
+			for i := 0; i < n; i++ {
+				members = append(members, fmt.Sprintf("pokemon-%d", i))
+			}
+
+			rounds := roundRobin(members)
+			met := make(map[[2]string]int)
+
+			if n%2 == 0 {
+				assert.Len(t, rounds, n-1)
+			} else {
+				assert.Len(t, rounds, n)
+			}
+
+			for _, pairs := range rounds {
+				busy := make(map[string]bool)
+				for _, pair := range pairs {
+					assert.False(t, busy[pair[0]] || busy[pair[1]], "a member plays twice in a round")
+					busy[pair[0]], busy[pair[1]] = true, true
+
+					if pair[0] > pair[1] {
+						pair[0], pair[1] = pair[1], pair[0]
+					}
+					met[pair]++
+				}
+			}
+
+			assert.Len(t, met, n*(n-1)/2)
+			for pair, times := range met {
+				assert.Equal(t, 1, times, "%v meet more than once", pair)
+			}
+		})
+	}
+}
+
+func Test_Standings(t *testing.T) {
+	type testCase struct {
+		name           string
+		tieBreakers    []string
+		expectedResult []models.Standing
+	}
+
+	var (
+		testTable []testCase
+		// a beats b, b beats c, c beats a, everybody beats d except a draw between a and d
+		fixtures = []models.LeagueFixture{
+			{Round: 1, PokemonA: "a", PokemonB: "b", Played: true, Winner: "a"},
+			{Round: 1, PokemonA: "c", PokemonB: "d", Played: true, Winner: "c"},
+			{Round: 2, PokemonA: "b", PokemonB: "c", Played: true, Winner: "b"},
+			{Round: 2, PokemonA: "a", PokemonB: "d", Played: true, Draw: true},
+			{Round: 3, PokemonA: "c", PokemonB: "a", Played: true, Winner: "c"},
+			{Round: 3, PokemonA: "d", PokemonB: "b", Played: true, Winner: "b"},
+			{Round: 4, PokemonA: "a", PokemonB: "c"},
+		}
+	)
+
+	testTable = append(testTable, testCase{
+		name:        "points then head to head then name",
+		tieBreakers: []string{TieBreakHeadToHead, TieBreakName},
+		expectedResult: []models.Standing{
+			{Rank: 1, Name: "b", Played: 3, Wins: 2, Losses: 1, Points: 6},
+			{Rank: 2, Name: "c", Played: 3, Wins: 2, Losses: 1, Points: 6},
+			{Rank: 3, Name: "a", Played: 3, Wins: 1, Losses: 1, Draws: 1, Points: 4},
+			{Rank: 4, Name: "d", Played: 3, Losses: 2, Draws: 1, Points: 1},
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:        "level members share the rank",
+		tieBreakers: []string{TieBreakWins},
+		expectedResult: []models.Standing{
+			{Rank: 1, Name: "b", Played: 3, Wins: 2, Losses: 1, Points: 6},
+			{Rank: 1, Name: "c", Played: 3, Wins: 2, Losses: 1, Points: 6},
+			{Rank: 3, Name: "a", Played: 3, Wins: 1, Losses: 1, Draws: 1, Points: 4},
+			{Rank: 4, Name: "d", Played: 3, Losses: 2, Draws: 1, Points: 1},
+		},
+	})
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			league := models.League{PointsWin: 3, PointsDraw: 1, TieBreakers: testCase.tieBreakers}
+
+			assert.Equal(t, testCase.expectedResult, standings(league, fixtures))
+		})
+	}
+}
+
+func Test_PokemonUsecase_CreateLeague(t *testing.T) {
+	type testCase struct {
+		name             string
+		input            models.RequestLeague
+		wantError        bool
+		expectedError    error
+		expectedFixtures int
+		onPokemonRepo    func(mock *postgres_mock.MockPokemonRepo, fixtures *[]models.LeagueFixture)
+	}
+
+	var (
+		testTable []testCase
+		seed      = int64(7)
+		draw      = 2
+	)
+
+	testTable = append(testTable, testCase{
+		name:          "failed roster too small",
+		input:         models.RequestLeague{Roster: []string{"pikachu"}},
+		wantError:     true,
+		expectedError: ErrInvalidLeagueRoster,
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "failed invalid tie breaker",
+		input:         models.RequestLeague{Roster: []string{"pikachu", "eevee"}, TieBreakers: []string{"goal_difference"}},
+		wantError:     true,
+		expectedError: ErrInvalidTieBreaker,
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "failed unknown pokemon",
+		input:         models.RequestLeague{Roster: []string{"pikachu", "agumon"}},
+		wantError:     true,
+		expectedError: &UnknownPokemonError{Names: []string{"agumon"}},
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo, fixtures *[]models.LeagueFixture) {
+			mock.EXPECT().GetPokemonByName(gomock.Any(), "pikachu").Return(newTestPokemon("pikachu", 35, 55, 40, 50, 50, 90), nil).Times(1)
+			mock.EXPECT().GetPokemonByName(gomock.Any(), "agumon").Return(models.GetPokemon{}, repository.ErrPokemonNotFound).Times(1)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:             "success",
+		input:            models.RequestLeague{Name: "weekly", Roster: []string{"a", "b", "c", "d", "e"}, Seed: &seed, PointsDraw: &draw},
+		expectedFixtures: 10,
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo, fixtures *[]models.LeagueFixture) {
+			mock.EXPECT().GetPokemonByName(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, name string) (models.GetPokemon, error) {
+				return newTestPokemon(name, 50, 50, 50, 50, 50, 50), nil
+			}).Times(5)
+			mock.EXPECT().PostLeague(gomock.Any(), gomock.Any()).DoAndReturn(func(input models.League, scheduled []models.LeagueFixture) (int64, error) {
+				if input.Rounds != 5 || input.PointsWin != 3 || input.PointsDraw != 2 || len(input.TieBreakers) != len(defaultTieBreakers) {
+					return 0, fmt.Errorf("unexpected league %+v", input)
+				}
+				for _, fixture := range scheduled {
+					fixture.LeagueID = 1
+					*fixtures = append(*fixtures, fixture)
+				}
+				return 1, nil
+			}).Times(1)
+			mock.EXPECT().GetLeague(1).Return(models.League{LeagueID: 1, Round: 1, Rounds: 5}, nil).Times(1)
+			mock.EXPECT().GetLeagueFixtures(1).DoAndReturn(func(id int) ([]models.LeagueFixture, error) {
+				return *fixtures, nil
+			}).Times(1)
+		},
+	})
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			var fixtures []models.LeagueFixture
+			pokeRepo := postgres_mock.NewMockPokemonRepo(mockCtrl)
+
+			if testCase.onPokemonRepo != nil {
+				testCase.onPokemonRepo(pokeRepo, &fixtures)
+			}
+
+			usecase := PokeUsecase{
+				PokeRepository: pokeRepo,
+			}
+
+			data, serr := usecase.CreateLeague(context.Background(), testCase.input)
+
+			if testCase.wantError {
+				assert.EqualError(t, serr, testCase.expectedError.Error())
+			} else {
+				assert.Nil(t, serr)
+				assert.Len(t, fixtures, testCase.expectedFixtures)
+				assert.Len(t, data.Standings, 5)
+			}
+		})
+	}
+}
+
+func Test_PokemonUsecase_PlayLeagueRound(t *testing.T) {
+	type testCase struct {
+		name          string
+		wantError     bool
+		expectedError error
+		onPokemonRepo func(mock *postgres_mock.MockPokemonRepo)
+	}
+
+	var (
+		testTable []testCase
+		league    = models.League{LeagueID: 1, Seed: 1, Round: 2, Rounds: 3, PointsWin: 3, PointsDraw: 1}
+		fixtures  = []models.LeagueFixture{
+			{FixtureID: 1, LeagueID: 1, Round: 1, PokemonA: "mewtwo", PokemonB: "magikarp", Played: true, Winner: "mewtwo", BattleID: 3},
+			{FixtureID: 2, LeagueID: 1, Round: 2, PokemonA: "magikarp", PokemonB: "mewtwo"},
+			{FixtureID: 3, LeagueID: 1, Round: 3, PokemonA: "mewtwo", PokemonB: "magikarp"},
+		}
+	)
+
+	testTable = append(testTable, testCase{
+		name:          "failed league not found",
+		wantError:     true,
+		expectedError: repository.ErrLeagueNotFound,
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo) {
+			mock.EXPECT().GetLeague(1).Return(models.League{}, repository.ErrLeagueNotFound).Times(1)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "failed league finished",
+		wantError:     true,
+		expectedError: ErrLeagueFinished,
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo) {
+			mock.EXPECT().GetLeague(1).Return(models.League{LeagueID: 1, Round: 4, Rounds: 3}, nil).Times(1)
+		},
+	})
+
+	onFight := func(mock *postgres_mock.MockPokemonRepo) {
+		mock.EXPECT().GetPokemonByName(gomock.Any(), "magikarp").Return(newTestPokemon("magikarp", 20, 10, 55, 15, 20, 80), nil).Times(1)
+		mock.EXPECT().GetPokemonByName(gomock.Any(), "mewtwo").Return(newTestPokemon("mewtwo", 106, 110, 90, 154, 90, 130), nil).Times(1)
+	}
+
+	testTable = append(testTable, testCase{
+		name:          "failed fixture played by a concurrent request",
+		wantError:     true,
+		expectedError: repository.ErrFixturePlayed,
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo) {
+			mock.EXPECT().GetLeague(1).Return(league, nil).Times(1)
+			mock.EXPECT().GetLeagueFixtures(1).Return(fixtures, nil).Times(1)
+			onFight(mock)
+			mock.EXPECT().SaveLeagueBattle(2, false, gomock.Any(), gomock.Len(2), gomock.Any(), gomock.Any()).Return(int64(0), repository.ErrFixturePlayed).Times(1)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "failed round advanced by a concurrent request",
+		wantError:     true,
+		expectedError: repository.ErrLeagueAdvanced,
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo) {
+			mock.EXPECT().GetLeague(1).Return(league, nil).Times(1)
+			mock.EXPECT().GetLeagueFixtures(1).Return(fixtures, nil).Times(1)
+			onFight(mock)
+			mock.EXPECT().SaveLeagueBattle(2, false, gomock.Any(), gomock.Len(2), gomock.Any(), gomock.Any()).Return(int64(9), nil).Times(1)
+			mock.EXPECT().AdvanceLeague(1, 2).Return(repository.ErrLeagueAdvanced).Times(1)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name: "success",
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo) {
+			mock.EXPECT().GetLeague(1).Return(league, nil).Times(2)
+			mock.EXPECT().GetLeagueFixtures(1).Return(fixtures, nil).Times(2)
+			onFight(mock)
+			mock.EXPECT().SaveLeagueBattle(2, false, gomock.Any(), gomock.Len(2), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(fixtureID int, draw bool, input models.BattleInput, participants []models.Participant, events []models.BattleEvent, rate repository.Rater) (int64, error) {
+					if input.Winner != "mewtwo" {
+						return 0, fmt.Errorf("unexpected winner %s", input.Winner)
+					}
+					return 9, nil
+				}).Times(1)
+			mock.EXPECT().AdvanceLeague(1, 2).Return(nil).Times(1)
+		},
+	})
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			pokeRepo := postgres_mock.NewMockPokemonRepo(mockCtrl)
+
+			if testCase.onPokemonRepo != nil {
+				testCase.onPokemonRepo(pokeRepo)
+			}
+
+			usecase := PokeUsecase{
+				PokeRepository: pokeRepo,
+			}
+
+			_, serr := usecase.PlayLeagueRound(context.Background(), 1)
+
+			if testCase.wantError {
+				assert.EqualError(t, serr, testCase.expectedError.Error())
+			} else {
+				assert.Nil(t, serr)
+			}
+		})
+	}
+}
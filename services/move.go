@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"pokemon/models"
+	"strings"
+)
+
+const (
+	// maxMoves is how many moves a fighter brings into the ring
+	maxMoves = 4
+	// maxMoveLookups bounds the learnable moves fetched for one fighter
+	// while looking for damaging ones
+	maxMoveLookups = 10
+	// defaultMove is the name of the generic attack used by a fighter
+	// without moves or out of PP
+	defaultMove = "attack"
+
+	MovePolicyRandom        = "random"
+	MovePolicyHighestDamage = "highest_damage"
+)
+
+var (
+	ErrInvalidMovePolicy = errors.New("move_policy must be one of: " + strings.Join([]string{MovePolicyRandom, MovePolicyHighestDamage}, ", "))
+
+	movePolicies = map[string]MovePolicy{
+		MovePolicyRandom:        RandomMovePolicy{},
+		MovePolicyHighestDamage: HighestDamagePolicy{},
+	}
+)
+
+// BattleMove is a damaging move a fighter brings into the ring
+type BattleMove struct {
+	Name     string
+	Type     string
+	Power    int
+	Accuracy int
+	PP       int
+	Special  bool
+}
+
+func NewBattleMove(m models.Move) BattleMove {
+	return BattleMove{
+		Name:     m.Name,
+		Type:     m.Type.Name,
+		Power:    m.Power,
+		Accuracy: m.Accuracy,
+		PP:       m.PP,
+		Special:  m.DamageClass.Name == "special",
+	}
+}
+
+// MovePolicy decides which move a fighter uses against its target
+type MovePolicy interface {
+	// ChooseMove returns the index of the move in attacker.Moves, or -1 when
+	// the attacker has no move with PP left
+	ChooseMove(attacker, target *Fighter, rng *rand.Rand) int
+}
+
+// RandomMovePolicy picks any move with PP left
+type RandomMovePolicy struct{}
+
+func (RandomMovePolicy) ChooseMove(attacker, target *Fighter, rng *rand.Rand) int {
+	usable := make([]int, 0, len(attacker.Moves))
+	for i, move := range attacker.Moves {
+		if move.PP > 0 {
+			usable = append(usable, i)
+		}
+	}
+	if len(usable) == 0 {
+		return -1
+	}
+	return usable[rng.Intn(len(usable))]
+}
+
+// HighestDamagePolicy picks the move with the highest expected damage on the
+// target, weighting its power by accuracy, STAB and type effectiveness
+type HighestDamagePolicy struct{}
+
+func (HighestDamagePolicy) ChooseMove(attacker, target *Fighter, rng *rand.Rand) int {
+	best, bestDamage := -1, -1.0
+	for i := range attacker.Moves {
+		move := &attacker.Moves[i]
+		if move.PP <= 0 {
+			continue
+		}
+
+		att, def := attacker.Attack, target.Defense
+		if move.Special {
+			att, def = attacker.SpAttack, target.SpDefense
+		}
+		if def < 1 {
+			def = 1
+		}
+
+		accuracy := 1.0
+		if move.Accuracy > 0 {
+			accuracy = float64(move.Accuracy) / 100
+		}
+
+		expected := float64(move.Power*att) / float64(def) * accuracy *
+			stab(attacker, move.Type) * Effectiveness(move.Type, target.Types)
+		if expected > bestDamage {
+			best, bestDamage = i, expected
+		}
+	}
+	return best
+}
+
+// movePolicy resolves a policy by name, an empty name is the random one
+func movePolicy(name string) (string, MovePolicy, error) {
+	if name == "" {
+		name = MovePolicyRandom
+	}
+
+	policy, ok := movePolicies[name]
+	if !ok {
+		return name, nil, ErrInvalidMovePolicy
+	}
+	return name, policy, nil
+}
+
+// loadMoves picks up to maxMoves damaging moves for every fighter from the
+// moves it can learn. The learnable moves are shuffled with the battle seed so
+// a replay brings the same moves into the ring.
+func (p *PokeUsecase) loadMoves(ctx context.Context, fight []models.GetPokemon, seed int64) (res map[string][]BattleMove, err error) {
+	var (
+		rng   = rand.New(rand.NewSource(seed))
+		perms = make([][]int, 0, len(fight))
+		seen  = make(map[string]bool)
+		names = make([]string, 0)
+	)
+
+	// every move a fighter may look up below is fetched up front: it looks
+	// up at most maxMoveLookups moves it doesn't know yet, and it knows no
+	// more than the moves of the fighters before it
+	for _, poke := range fight {
+		perm := rng.Perm(len(poke.Moves))
+		perms = append(perms, perm)
+
+		unseen := 0
+		for _, i := range perm {
+			if unseen == maxMoveLookups {
+				break
+			}
+
+			name := poke.Moves[i].Move.Name
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+				unseen++
+			}
+		}
+	}
+
+	moves, missing, err := p.fetchMoves(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	fetched := make(map[string]models.Move, len(names))
+	for i, name := range names {
+		if !missing[i] {
+			fetched[name] = moves[i]
+		}
+	}
+
+	known := make(map[string]models.Move)
+	res = make(map[string][]BattleMove, len(fight))
+	for f, poke := range fight {
+		lookups := 0
+		for _, i := range perms[f] {
+			if len(res[poke.Name]) == maxMoves || lookups == maxMoveLookups {
+				break
+			}
+
+			name := poke.Moves[i].Move.Name
+			move, ok := known[name]
+			if !ok {
+				lookups++
+				if move, ok = fetched[name]; !ok {
+					continue
+				}
+				known[name] = move
+			}
+
+			// status moves don't hurt anybody
+			if move.Power == 0 {
+				continue
+			}
+			res[poke.Name] = append(res[poke.Name], NewBattleMove(move))
+		}
+	}
+
+	return res, nil
+}
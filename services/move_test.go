@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"pokemon/models"
+	"pokemon/repository"
+	postgres_mock "pokemon/repository/mocks"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMove(name, moveType, damageClass string, power, accuracy, pp int) models.Move {
+	return models.Move{
+		Name:        name,
+		Power:       power,
+		Accuracy:    accuracy,
+		PP:          pp,
+		DamageClass: models.DamageClass{Name: damageClass},
+		Type:        models.Type{Name: moveType},
+	}
+}
+
+func Test_RandomMovePolicy(t *testing.T) {
+	attacker := NewFighter(newTestPokemon("pikachu", 35, 55, 40, 50, 50, 90))
+	target := NewFighter(newTestPokemon("pichu", 20, 40, 15, 35, 35, 60))
+
+	attacker.Moves = []BattleMove{
+		{Name: "thunderbolt", Power: 90, PP: 0},
+		{Name: "quick-attack", Power: 40, PP: 30},
+	}
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, 1, RandomMovePolicy{}.ChooseMove(&attacker, &target, rng))
+	}
+
+	attacker.Moves[1].PP = 0
+	assert.Equal(t, -1, RandomMovePolicy{}.ChooseMove(&attacker, &target, rng))
+}
+
+func Test_HighestDamagePolicy(t *testing.T) {
+	type testCase struct {
+		name         string
+		moves        []BattleMove
+		targetTypes  []string
+		expectedMove int
+	}
+
+	var testTable []testCase
+
+	testTable = append(testTable, testCase{
+		name: "strongest move",
+		moves: []BattleMove{
+			{Name: "tackle", Type: "normal", Power: 40, PP: 35},
+			{Name: "body-slam", Type: "normal", Power: 85, PP: 15},
+		},
+		targetTypes:  []string{"grass"},
+		expectedMove: 1,
+	})
+
+	testTable = append(testTable, testCase{
+		name: "super effective beats raw power",
+		moves: []BattleMove{
+			{Name: "body-slam", Type: "normal", Power: 85, PP: 15},
+			{Name: "water-gun", Type: "water", Power: 50, PP: 25},
+		},
+		targetTypes:  []string{"fire"},
+		expectedMove: 1,
+	})
+
+	testTable = append(testTable, testCase{
+		name: "inaccurate move loses",
+		moves: []BattleMove{
+			{Name: "hydro-pump", Type: "water", Power: 110, Accuracy: 50, PP: 5},
+			{Name: "surf", Type: "water", Power: 90, Accuracy: 100, PP: 15},
+		},
+		targetTypes:  []string{"normal"},
+		expectedMove: 1,
+	})
+
+	testTable = append(testTable, testCase{
+		name: "avoids immunity",
+		moves: []BattleMove{
+			{Name: "body-slam", Type: "normal", Power: 85, PP: 15},
+			{Name: "bite", Type: "dark", Power: 60, PP: 25},
+		},
+		targetTypes:  []string{"ghost"},
+		expectedMove: 1,
+	})
+
+	testTable = append(testTable, testCase{
+		name: "skips moves out of pp",
+		moves: []BattleMove{
+			{Name: "tackle", Type: "normal", Power: 40, PP: 35},
+			{Name: "body-slam", Type: "normal", Power: 85, PP: 0},
+		},
+		targetTypes:  []string{"normal"},
+		expectedMove: 0,
+	})
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			attacker := NewFighter(newTestPokemon("attacker", 80, 80, 80, 80, 80, 80))
+			attacker.Types = []string{"water"}
+			attacker.Moves = testCase.moves
+			target := NewFighter(newTestPokemon("target", 80, 80, 80, 80, 80, 80))
+			target.Types = testCase.targetTypes
+
+			assert.Equal(t, testCase.expectedMove, HighestDamagePolicy{}.ChooseMove(&attacker, &target, nil))
+		})
+	}
+}
+
+func Test_Simulate_Moves(t *testing.T) {
+	pikachu := NewFighter(newTestPokemon("pikachu", 35, 55, 40, 50, 50, 90))
+	pikachu.Moves = []BattleMove{{Name: "thunder-shock", Type: "electric", Power: 40, Accuracy: 100, PP: 3, Special: true}}
+	pichu := NewFighter(newTestPokemon("pichu", 20, 40, 15, 35, 35, 60))
+
+	res := Simulate([]Fighter{pikachu, pichu}, HighestDamagePolicy{}, rand.New(rand.NewSource(1)))
+
+	used := 0
+	for _, event := range res.Events {
+		switch event.Actor {
+		case "pikachu":
+			if used < 3 {
+				assert.Equal(t, "thunder-shock", event.Move)
+			} else {
+				assert.Equal(t, defaultMove, event.Move)
+			}
+			used++
+		case "pichu":
+			assert.Equal(t, defaultMove, event.Move)
+		}
+	}
+	assert.Greater(t, used, 0)
+	// the fighters handed to the engine keep their PP
+	assert.Equal(t, 3, pikachu.Moves[0].PP)
+}
+
+func Test_PokemonUsecase_LoadMoves(t *testing.T) {
+	type testCase struct {
+		name          string
+		learnable     []string
+		wantError     bool
+		expectedError error
+		expectedMoves []string
+		onPokemonRepo func(mock *postgres_mock.MockPokemonRepo)
+	}
+
+	var (
+		testTable []testCase
+		moves     = map[string]models.Move{
+			"tackle":       newTestMove("tackle", "normal", "physical", 40, 100, 35),
+			"growl":        newTestMove("growl", "normal", "status", 0, 100, 40),
+			"ember":        newTestMove("ember", "fire", "special", 40, 100, 25),
+			"scratch":      newTestMove("scratch", "normal", "physical", 40, 100, 35),
+			"flamethrower": newTestMove("flamethrower", "fire", "special", 90, 100, 15),
+			"slash":        newTestMove("slash", "normal", "physical", 70, 100, 20),
+		}
+	)
+
+	onGetMove := func(mock *postgres_mock.MockPokemonRepo) {
+		mock.EXPECT().GetMove(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, name string) (models.Move, error) {
+			move, ok := moves[name]
+			if !ok {
+				return models.Move{}, repository.ErrMoveNotFound
+			}
+			return move, nil
+		}).AnyTimes()
+	}
+
+	testTable = append(testTable, testCase{
+		name:          "failed unexpected error",
+		learnable:     []string{"tackle"},
+		wantError:     true,
+		expectedError: errors.New("unexpected error"),
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo) {
+			mock.EXPECT().GetMove(gomock.Any(), "tackle").Return(models.Move{}, errors.New("unexpected error")).Times(1)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "success skips status and unknown moves",
+		learnable:     []string{"tackle", "growl", "shadow-rush", "ember"},
+		wantError:     false,
+		expectedMoves: []string{"ember", "tackle"},
+		onPokemonRepo: onGetMove,
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "success looks every move up once",
+		learnable:     []string{"tackle", "growl", "tackle", "ember", "growl"},
+		wantError:     false,
+		expectedMoves: []string{"tackle", "tackle", "ember"},
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo) {
+			for _, name := range []string{"tackle", "growl", "ember"} {
+				// both loadMoves calls of the test look the moves up
+				mock.EXPECT().GetMove(gomock.Any(), name).Return(moves[name], nil).Times(2)
+			}
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "success brings at most four moves",
+		learnable:     []string{"tackle", "ember", "scratch", "flamethrower", "slash"},
+		wantError:     false,
+		onPokemonRepo: onGetMove,
+	})
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			pokeRepo := postgres_mock.NewMockPokemonRepo(mockCtrl)
+
+			if testCase.onPokemonRepo != nil {
+				testCase.onPokemonRepo(pokeRepo)
+			}
+
+			usecase := PokeUsecase{
+				PokeRepository: pokeRepo,
+			}
+
+			poke := newTestPokemon("charmander", 39, 52, 43, 60, 50, 65)
+			for _, name := range testCase.learnable {
+				poke.Moves = append(poke.Moves, models.Moves{Move: models.NamedMove{Name: name}})
+			}
+
+			res, serr := usecase.loadMoves(context.Background(), []models.GetPokemon{poke}, 42)
+
+			if testCase.wantError {
+				assert.EqualError(t, serr, testCase.expectedError.Error())
+				return
+			}
+
+			assert.Nil(t, serr)
+			names := make([]string, 0)
+			for _, move := range res["charmander"] {
+				names = append(names, move.Name)
+				assert.Greater(t, move.Power, 0)
+			}
+			if testCase.expectedMoves != nil {
+				assert.ElementsMatch(t, testCase.expectedMoves, names)
+			} else {
+				assert.Len(t, names, maxMoves)
+			}
+
+			// the same seed brings the same moves
+			again, _ := usecase.loadMoves(context.Background(), []models.GetPokemon{poke}, 42)
+			assert.Equal(t, res, again)
+		})
+	}
+}
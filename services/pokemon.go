@@ -1,177 +1,343 @@
 package services
 
 import (
-	"fmt"
+	"context"
 	"math/rand"
 	"pokemon/models"
-	"sort"
+	"pokemon/repository"
+	"reflect"
+	"strings"
 	"time"
 )
 
 type PokemonUsecase interface {
-	PostBattle(input int) (res models.BattleResponse, err error)
-	GetAllPokemons() (res models.AllPokemon, err error)
-	GetBattle(start_time, end_time string) (res []models.BattleResponse, err error)
-	GetPokemonScore() (res []models.DetailPlayers, err error)
+	PostBattle(ctx context.Context, input models.RequestBattle) (res models.BattleResponse, err error)
+	ReplayBattle(ctx context.Context, battleID int) (res models.ReplayResponse, err error)
+	GetBattleEvents(battleID int) (res []models.BattleEvent, err error)
+	AnnulPokemon(battleID int, name string) (res models.BattleResponse, err error)
+	GetAllPokemons(ctx context.Context) (res models.AllPokemon, err error)
+	GetBattle(input models.RequestBattleSearch) (res models.BattlePage, err error)
+	GetPokemonScore(input models.RequestLeaderboard) (res models.Leaderboard, err error)
 }
 
-func (p *PokeUsecase) PostBattle(input int) (resp models.BattleResponse, err error) {
+func (p *PokeUsecase) PostBattle(ctx context.Context, input models.RequestBattle) (resp models.BattleResponse, err error) {
 	var (
-		battleInput models.BattleInput
-		fight       = make([]models.GetPokemon, 0)
-		idx         = make([]int, 0)
-		scores      = make(map[string]int)
-		now         = time.Now()
+		fight []models.GetPokemon
+		seed  int64
+		now   = p.now()
 	)
 
-	res, err := p.PokeRepository.GetAllPokemons()
+	if _, err = battleEngine(input.Engine); err != nil {
+		return resp, err
+	}
+	if _, _, err = movePolicy(input.MovePolicy); err != nil {
+		return resp, err
+	}
+
+	if input.Seed != nil {
+		seed = *input.Seed
+	} else {
+		seed = p.newSeed()
+	}
+
+	if len(input.Roster) > 0 {
+		fight, err = p.lookupRoster(ctx, input)
+	} else {
+		fight, err = p.randomRoster(ctx, input.Pokemons, input.Filter, rand.New(rand.NewSource(seed)))
+	}
 	if err != nil {
 		return resp, err
 	}
 
-	for i := 0; i < 5; i++ {
-		rand.Seed(time.Now().UnixNano())
-		min := 0
-		max := 15
-		Id := rand.Intn(max-min+1) + min
-		idx = append(idx, Id)
+	result, err := p.simulateRoster(ctx, fight, seed, input.Engine, input.MovePolicy)
+	if err != nil {
+		return resp, err
+	}
+	if len(result.Placements) == 0 {
+		return resp, ErrNoFighters
 	}
 
-	for _, v := range idx {
-		for i, z := range res.Results {
-			if v == i {
-				data, err := p.PokeRepository.GetPokemonByName(z.Name)
-				if err != nil {
-					return resp, err
-				}
-				fight = append(fight, data)
-			}
-		}
+	Id, err := p.saveBattle(fight, seed, result, now)
+	if err != nil {
+		return resp, err
 	}
 
-	for _, poke1 := range fight {
-		for _, poke2 := range fight {
-			var att1, att2 int
-			for _, stat := range poke1.Stats {
-				if stat.Stat.Name == "attack" {
-					att1 = stat.BaseStat
-				}
+	resp = models.BattleResponse{
+		BattleID:      int(Id),
+		Winner:        result.Placements[0],
+		Seed:          seed,
+		Engine:        result.Engine,
+		EngineVersion: result.EngineVersion,
+	}
 
-			}
+	players, err := p.PokeRepository.GetPlayer(int(Id))
+	if err != nil {
+		return resp, err
+	}
 
-			for _, stat := range poke2.Stats {
-				if stat.Stat.Name == "attack" {
-					att2 = stat.BaseStat
-				}
+	resp.Player = players
 
-			}
+	return resp, nil
+}
 
-			if att1 > att2 {
-				scores[poke1.Name] += 3
-			} else if att2 > att1 {
-				scores[poke2.Name] += 3
-			} else {
-				scores[poke1.Name]++
-				scores[poke2.Name]++
+// GetBattleEvents returns the turn by turn log of a recorded battle
+func (p *PokeUsecase) GetBattleEvents(battleID int) (res []models.BattleEvent, err error) {
+	if _, err = p.PokeRepository.GetBattleByID(battleID); err != nil {
+		return nil, err
+	}
 
-			}
-		}
+	res, err = p.PokeRepository.GetBattleEvents(battleID)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// saveBattle stores a simulated battle with its participants and event log
+// and rates everyone who took part in the same transaction
+func (p *PokeUsecase) saveBattle(fight []models.GetPokemon, seed int64, result BattleResult, start time.Time) (Id int64, err error) {
+	battleInput, participants := battleRecord(fight, seed, result, start, p.now())
 
+	Id, err = p.PokeRepository.SaveBattle(battleInput, participants, result.Events, rateBattle(result))
+	if err != nil {
+		return Id, err
 	}
 
-	var maxScore int
-	for name, score := range scores {
+	return Id, nil
+}
 
-		if score > maxScore {
-			maxScore = score
-			battleInput.Winner = name
-			battleInput.StartTime = now
-			battleInput.EndTime = now.Add(time.Minute * time.Duration(score))
+// saveMatchBattle is saveBattle for the battle of a tournament match, the
+// match gets its winner in the same transaction as the battle
+func (p *PokeUsecase) saveMatchBattle(matchID int, fight []models.GetPokemon, seed int64, result BattleResult, start time.Time) (Id int64, err error) {
+	battleInput, participants := battleRecord(fight, seed, result, start, p.now())
 
-		}
+	Id, err = p.PokeRepository.SaveTournamentBattle(matchID, battleInput, participants, result.Events, rateBattle(result))
+	if err != nil {
+		return Id, err
 	}
 
-	Id, err := p.PokeRepository.PostBattlePokemon(battleInput)
+	return Id, nil
+}
+
+// saveFixtureBattle is saveBattle for the battle of a league fixture, the
+// fixture is marked played in the same transaction as the battle
+func (p *PokeUsecase) saveFixtureBattle(fixtureID int, fight []models.GetPokemon, seed int64, result BattleResult, start time.Time) (Id int64, err error) {
+	battleInput, participants := battleRecord(fight, seed, result, start, p.now())
+
+	Id, err = p.PokeRepository.SaveLeagueBattle(fixtureID, result.Draw, battleInput, participants, result.Events, rateBattle(result))
 	if err != nil {
-		return resp, err
+		return Id, err
 	}
 
-	type kv struct {
-		Key   string
-		Value int
+	return Id, nil
+}
+
+// battleRecord is the battle and the participants stored for a result
+func battleRecord(fight []models.GetPokemon, seed int64, result BattleResult, start, end time.Time) (battleInput models.BattleInput, participants []models.Participant) {
+	battleInput = models.BattleInput{
+		Winner:        result.Placements[0],
+		Seed:          seed,
+		MovePolicy:    result.MovePolicy,
+		Engine:        result.Engine,
+		EngineVersion: result.EngineVersion,
+		StartTime:     start,
+		EndTime:       end,
+	}
+	for _, poke := range fight {
+		battleInput.Roster = append(battleInput.Roster, poke.Name)
 	}
 
-	var ss []kv
-	for k, v := range scores {
-		ss = append(ss, kv{k, v})
+	for i, name := range result.Placements {
+		participants = append(participants, models.Participant{
+			Name:      name,
+			Placement: i + 1,
+			Scores:    len(result.Placements) - i,
+		})
 	}
 
-	sort.Slice(ss, func(i, j int) bool {
-		return ss[i].Value > ss[j].Value
-	})
+	return battleInput, participants
+}
 
-	for _, kv := range ss {
-		fmt.Printf("%s, %d\n", kv.Key, kv.Value)
+// ReplayBattle runs a recorded battle again with its stored seed and roster
+// and tells whether it reaches the same placements
+func (p *PokeUsecase) ReplayBattle(ctx context.Context, battleID int) (res models.ReplayResponse, err error) {
+	battle, err := p.PokeRepository.GetBattleByID(battleID)
+	if err != nil {
+		return res, err
 	}
 
-	resp = models.BattleResponse{
-		BattleID: int(Id),
-		Winner:   ss[0].Key,
-	}
-	for i, v := range ss {
-		dataPlayer := models.Pokemon{
-			Name:     v.Key,
-			BattleID: int(Id),
-			Scores:   5 - i,
-		}
+	// the placements change with the annulments, the replay is held against
+	// the order the engine finished the battle in
+	recorded, err := p.PokeRepository.GetFinishOrder(battleID)
+	if err != nil {
+		return res, err
+	}
 
-		err := p.PokeRepository.PostPokemonData(dataPlayer)
+	// the engine must still follow the rules the battle was fought by
+	if battle.Engine != "" {
+		engine, err := battleEngine(battle.Engine)
 		if err != nil {
-			return resp, err
+			return res, err
 		}
+		if engine.Version() != battle.EngineVersion {
+			return res, ErrEngineChanged
+		}
+	}
 
+	fight, err := p.lookupRoster(ctx, models.RequestBattle{Roster: battle.Roster})
+	if err != nil {
+		return res, err
 	}
 
-	players, err := p.PokeRepository.GetPlayer(int(Id))
+	result, err := p.simulateRoster(ctx, fight, battle.Seed, battle.Engine, battle.MovePolicy)
 	if err != nil {
-		return resp, err
+		return res, err
 	}
 
-	resp.Player = players
+	res = models.ReplayResponse{
+		BattleID:      battle.BattleID,
+		Seed:          battle.Seed,
+		Engine:        result.Engine,
+		EngineVersion: result.EngineVersion,
+		Recorded:      recorded,
+		Replayed:      result.Placements,
+	}
+	res.Matches = reflect.DeepEqual(res.Recorded, res.Replayed)
 
-	return resp, nil
+	return res, nil
 }
 
-func (p *PokeUsecase) GetAllPokemons() (res models.AllPokemon, err error) {
-	res, err = p.PokeRepository.GetAllPokemons()
+// AnnulPokemon disqualifies a participant of a recorded battle, everyone who
+// finished below it moves up one place
+func (p *PokeUsecase) AnnulPokemon(battleID int, name string) (res models.BattleResponse, err error) {
+	var (
+		target *models.DetailPlayers
+		active int
+	)
+
+	if _, err = p.PokeRepository.GetBattleByID(battleID); err != nil {
+		return res, err
+	}
+
+	players, err := p.PokeRepository.GetPlayer(battleID)
 	if err != nil {
-		return models.AllPokemon{}, err
+		return res, err
+	}
+
+	name = strings.ToLower(strings.TrimSpace(name))
+	for i, player := range players {
+		if !player.Annulled {
+			active++
+		}
+		if player.Name == name {
+			target = &players[i]
+		}
+	}
+
+	if target == nil {
+		return res, repository.ErrPokemonNotInBattle
+	}
+	if target.Annulled {
+		return res, repository.ErrAlreadyAnnulled
+	}
+	if active == 1 {
+		return res, ErrLastParticipant
 	}
+
+	if err = p.PokeRepository.AnnulPokemon(battleID, target.Name); err != nil {
+		return res, err
+	}
+
+	battle, err := p.PokeRepository.GetBattleByID(battleID)
+	if err != nil {
+		return res, err
+	}
+
+	players, err = p.PokeRepository.GetPlayer(battleID)
+	if err != nil {
+		return res, err
+	}
+
+	res = models.BattleResponse{
+		BattleID:      battle.BattleID,
+		Winner:        battle.Winner,
+		Seed:          battle.Seed,
+		Engine:        battle.Engine,
+		EngineVersion: battle.EngineVersion,
+		Player:        players,
+	}
+
 	return res, nil
 }
 
-func (p *PokeUsecase) GetBattle(start_time, end_time string) (res []models.BattleResponse, err error) {
-	res, err = p.PokeRepository.GetBattle(start_time, end_time)
+// simulateRoster brings the roster into the ring and lets the engine decide
+// the battle with its own random source, so the outcome only depends on the
+// roster order, the seed, the engine and the move policy
+func (p *PokeUsecase) simulateRoster(ctx context.Context, fight []models.GetPokemon, seed int64, engineName, policyName string) (res BattleResult, err error) {
+	engine, err := battleEngine(engineName)
 	if err != nil {
-		return nil, err
+		return res, err
 	}
 
-	for i, v := range res {
-		player, err := p.PokeRepository.GetPlayer(v.BattleID)
+	name, policy, err := movePolicy(policyName)
+	if err != nil {
+		return res, err
+	}
+
+	moves := make(map[string][]BattleMove)
+	if engine.UsesMoves() {
+		moves, err = p.loadMoves(ctx, fight, seed)
 		if err != nil {
-			return nil, err
+			return res, err
 		}
+	}
 
-		res[i].Player = player
+	fighters := make([]Fighter, 0, len(fight))
+	for _, poke := range fight {
+		f := NewFighter(poke)
+		f.Moves = moves[poke.Name]
+		fighters = append(fighters, f)
 	}
 
+	res = engine.Fight(fighters, policy, rand.New(rand.NewSource(seed)))
+	res.MovePolicy = name
+	res.Engine = engine.Name()
+	res.EngineVersion = engine.Version()
 	return res, nil
 }
 
-func (p *PokeUsecase) GetPokemonScore() (res []models.DetailPlayers, err error) {
-	res, err = p.PokeRepository.GetPokemonScore()
+func (p *PokeUsecase) GetAllPokemons(ctx context.Context) (res models.AllPokemon, err error) {
+	res, err = p.PokeRepository.GetAllPokemons(ctx)
 	if err != nil {
-		return nil, err
+		return models.AllPokemon{}, err
+	}
+	return res, nil
+}
+
+// GetBattle lists the battles matching the search a page at a time, one more
+// battle than the page holds is read to tell whether another page follows
+func (p *PokeUsecase) GetBattle(input models.RequestBattleSearch) (res models.BattlePage, err error) {
+	search, err := battleSearch(input)
+	if err != nil {
+		return res, err
+	}
+
+	pageSize := search.Limit
+	search.Limit++
+
+	battles, err := p.PokeRepository.GetBattleWithPlayers(search)
+	if err != nil {
+		return res, err
+	}
+
+	if len(battles) > pageSize {
+		battles = battles[:pageSize]
+		res.NextCursor = encodeCursor(battles[pageSize-1].BattleID)
+	}
+
+	res.Battles = battles
+	if res.Battles == nil {
+		res.Battles = []models.BattleResponse{}
 	}
 	return res, nil
 }
@@ -1,16 +1,19 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"pokemon/models"
+	"pokemon/repository"
 	postgres_mock "pokemon/repository/mocks"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
 
-
 func Test_PokemonUsecase_GetAllPokemons(t *testing.T) {
 	type testCase struct {
 		name             string
@@ -27,7 +30,7 @@ func Test_PokemonUsecase_GetAllPokemons(t *testing.T) {
 		wantError:     true,
 		expectedError: errors.New("unexpected error"),
 		onGetAllPokemons: func(mock *postgres_mock.MockPokemonRepo) {
-			mock.EXPECT().GetAllPokemons().Return(models.AllPokemon{}, errors.New("unexpected error")).AnyTimes()
+			mock.EXPECT().GetAllPokemons(gomock.Any()).Return(models.AllPokemon{}, errors.New("unexpected error")).AnyTimes()
 		},
 	})
 
@@ -35,7 +38,7 @@ func Test_PokemonUsecase_GetAllPokemons(t *testing.T) {
 		name:      "success",
 		wantError: false,
 		onGetAllPokemons: func(mock *postgres_mock.MockPokemonRepo) {
-			mock.EXPECT().GetAllPokemons().Return(models.AllPokemon{
+			mock.EXPECT().GetAllPokemons(gomock.Any()).Return(models.AllPokemon{
 				Count:    100,
 				Next:     "",
 				Previous: "",
@@ -81,7 +84,7 @@ func Test_PokemonUsecase_GetAllPokemons(t *testing.T) {
 				PokeRepository: pokeRepo,
 			}
 
-			data, serr := usecase.GetAllPokemons()
+			data, serr := usecase.GetAllPokemons(context.Background())
 
 			if testCase.wantError {
 				assert.EqualError(t, serr, testCase.expectedError.Error())
@@ -97,52 +100,90 @@ func Test_PokemonUsecase_GetAllPokemons(t *testing.T) {
 func Test_PokemonUsecase_GetBattle(t *testing.T) {
 	type testCase struct {
 		name           string
+		input          models.RequestBattleSearch
 		wantError      bool
-		expectedResult []models.BattleResponse
+		expectedResult models.BattlePage
 		expectedError  error
 		onPokemonRepo  func(mock *postgres_mock.MockPokemonRepo)
 	}
 
-	var testTable []testCase
+	var (
+		testTable []testCase
+		player    = []models.DetailPlayers{{Name: "pikachu", Scores: 68}}
+	)
+
+	testTable = append(testTable, testCase{
+		name:          "failed invalid date",
+		input:         models.RequestBattleSearch{StartTime: "2022-07-02' OR '1'='1"},
+		wantError:     true,
+		expectedError: fmt.Errorf("%w: start_time must look like 2006-01-02", ErrInvalidSearch),
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "failed bad cursor",
+		input:         models.RequestBattleSearch{Cursor: "42"},
+		wantError:     true,
+		expectedError: fmt.Errorf("%w: bad cursor", ErrInvalidSearch),
+	})
 
 	testTable = append(testTable, testCase{
 		name:          "failed unexpected error",
 		wantError:     true,
 		expectedError: errors.New("unexpected error"),
 		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo) {
-			mock.EXPECT().GetBattle(gomock.Any(), gomock.Any()).Return([]models.BattleResponse{}, errors.New("unexpected error")).AnyTimes()
-			mock.EXPECT().GetPlayer(gomock.Any()).Return([]models.DetailPlayers{}, errors.New("unexpected error")).AnyTimes()
+			mock.EXPECT().GetBattleWithPlayers(gomock.Any()).Return(nil, errors.New("unexpected error")).Times(1)
 		},
 	})
 
 	testTable = append(testTable, testCase{
 		name:      "success",
+		input:     models.RequestBattleSearch{StartTime: "2022-07-02", EndTime: "2022-07-02"},
 		wantError: false,
 		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo) {
-			mock.EXPECT().GetBattle(gomock.Any(), gomock.Any()).Return([]models.BattleResponse{
+			mock.EXPECT().GetBattleWithPlayers(models.BattleSearch{
+				StartFrom:   time.Date(2022, 7, 2, 0, 0, 0, 0, time.UTC),
+				StartBefore: time.Date(2022, 7, 3, 0, 0, 0, 0, time.UTC),
+				Limit:       DefaultBattlePageSize + 1,
+			}).Return([]models.BattleResponse{
 				{
 					BattleID: 1,
 					Winner:   "pikachu",
+					Player:   player,
 				},
-			}, nil).AnyTimes()
-			mock.EXPECT().GetPlayer(gomock.Any()).Return([]models.DetailPlayers{
+			}, nil).Times(1)
+		},
+		expectedResult: models.BattlePage{
+			Battles: []models.BattleResponse{
 				{
-					Name:   "pikachu",
-					Scores: 68,
+					BattleID: 1,
+					Winner:   "pikachu",
+					Player:   player,
 				},
-			}, nil).AnyTimes()
+			},
 		},
-		expectedResult: []models.BattleResponse{
-			{
-				BattleID: 1,
-				Winner:   "pikachu",
-				Player: []models.DetailPlayers{
-					{
-						Name:   "pikachu",
-						Scores: 68,
-					},
-				},
+	})
+
+	testTable = append(testTable, testCase{
+		name:  "success next page",
+		input: models.RequestBattleSearch{Winner: "Pikachu", Order: "asc", Limit: 2, Cursor: encodeCursor(3)},
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo) {
+			mock.EXPECT().GetBattleWithPlayers(models.BattleSearch{
+				Winner:    "pikachu",
+				Ascending: true,
+				AfterID:   3,
+				Limit:     3,
+			}).Return([]models.BattleResponse{
+				{BattleID: 4, Winner: "pikachu", Player: player},
+				{BattleID: 6, Winner: "pikachu", Player: player},
+				{BattleID: 9, Winner: "pikachu", Player: player},
+			}, nil).Times(1)
+		},
+		expectedResult: models.BattlePage{
+			Battles: []models.BattleResponse{
+				{BattleID: 4, Winner: "pikachu", Player: player},
+				{BattleID: 6, Winner: "pikachu", Player: player},
 			},
+			NextCursor: encodeCursor(6),
 		},
 	})
 
@@ -160,7 +201,7 @@ func Test_PokemonUsecase_GetBattle(t *testing.T) {
 				PokeRepository: pokeRepo,
 			}
 
-			data, serr := usecase.GetBattle("2022-07-02", "2022-07-02")
+			data, serr := usecase.GetBattle(testCase.input)
 
 			if testCase.wantError {
 				assert.EqualError(t, serr, testCase.expectedError.Error())
@@ -171,42 +212,535 @@ func Test_PokemonUsecase_GetBattle(t *testing.T) {
 		})
 	}
 }
+func Test_PokemonUsecase_PostBattle(t *testing.T) {
+	type testCase struct {
+		name          string
+		input         models.RequestBattle
+		wantError     bool
+		expectedError error
+		expectedCount int
+		onPokemonRepo func(mock *postgres_mock.MockPokemonRepo, scores *[]int)
+	}
+
+	var (
+		testTable []testCase
+		seed      = int64(42)
+	)
+
+	allPokemons := models.AllPokemon{Count: MaxPokemons}
+	for i := 0; i < MaxPokemons; i++ {
+		allPokemons.Results = append(allPokemons.Results, struct {
+			Name string "json:\"name\""
+			Url  string "json:\"url\""
+		}{Name: fmt.Sprintf("pokemon-%d", i)})
+	}
+
+	onSuccess := func(mock *postgres_mock.MockPokemonRepo, scores *[]int) {
+		mock.EXPECT().GetAllPokemons(gomock.Any()).Return(allPokemons, nil).Times(1)
+		mock.EXPECT().GetPokemonByName(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, name string) (models.GetPokemon, error) {
+			return newTestPokemon(name, 50, 50, 50, 50, 50, 50), nil
+		}).AnyTimes()
+		mock.EXPECT().SaveBattle(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(input models.BattleInput, participants []models.Participant, events []models.BattleEvent, rate repository.Rater) (int64, error) {
+			// the leaderboard windows are UTC days
+			if input.StartTime.Location() != time.UTC || input.EndTime.Location() != time.UTC {
+				return 0, fmt.Errorf("battle times must be UTC, got %v and %v", input.StartTime, input.EndTime)
+			}
+			for _, participant := range participants {
+				*scores = append(*scores, participant.Scores)
+			}
+			return 1, nil
+		}).Times(1)
+		mock.EXPECT().GetPlayer(gomock.Any()).Return([]models.DetailPlayers{}, nil).Times(1)
+	}
+
+	testTable = append(testTable, testCase{
+		name:          "failed too few pokemons",
+		input:         models.RequestBattle{Pokemons: 1},
+		wantError:     true,
+		expectedError: ErrInvalidPokemons,
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "failed too many pokemons",
+		input:         models.RequestBattle{Pokemons: MaxPokemons + 1},
+		wantError:     true,
+		expectedError: ErrInvalidPokemons,
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "failed unknown engine",
+		input:         models.RequestBattle{Engine: "quantum"},
+		wantError:     true,
+		expectedError: ErrInvalidEngine,
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "failed invalid filter",
+		input:         models.RequestBattle{Filter: models.RosterFilter{Type: "sound"}},
+		wantError:     true,
+		expectedError: fmt.Errorf("%w: unknown type %q", ErrInvalidFilter, "sound"),
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "failed unknown move policy",
+		input:         models.RequestBattle{MovePolicy: "strongest"},
+		wantError:     true,
+		expectedError: ErrInvalidMovePolicy,
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "failed unexpected error",
+		input:         models.RequestBattle{Pokemons: 2},
+		wantError:     true,
+		expectedError: errors.New("unexpected error"),
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo, scores *[]int) {
+			mock.EXPECT().GetAllPokemons(gomock.Any()).Return(models.AllPokemon{}, errors.New("unexpected error")).Times(1)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "success default size",
+		input:         models.RequestBattle{},
+		expectedCount: DefaultPokemons,
+		onPokemonRepo: onSuccess,
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "success requested size",
+		input:         models.RequestBattle{Pokemons: 8},
+		expectedCount: 8,
+		onPokemonRepo: onSuccess,
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "success seeded",
+		input:         models.RequestBattle{Pokemons: 3, Seed: &seed},
+		expectedCount: 3,
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo, scores *[]int) {
+			mock.EXPECT().GetAllPokemons(gomock.Any()).Return(allPokemons, nil).Times(1)
+			mock.EXPECT().GetPokemonByName(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, name string) (models.GetPokemon, error) {
+				return newTestPokemon(name, 50, 50, 50, 50, 50, 50), nil
+			}).Times(3)
+			mock.EXPECT().SaveBattle(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(input models.BattleInput, participants []models.Participant, events []models.BattleEvent, rate repository.Rater) (int64, error) {
+				if input.Seed != seed || len(input.Roster) != 3 || len(participants) != 3 || rate == nil {
+					return 0, errors.New("battle stored without its seed, roster or rating")
+				}
+				for _, participant := range participants {
+					*scores = append(*scores, participant.Scores)
+				}
+				return 1, nil
+			}).Times(1)
+			mock.EXPECT().GetPlayer(gomock.Any()).Return([]models.DetailPlayers{}, nil).Times(1)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "failed roster size mismatch",
+		input:         models.RequestBattle{Pokemons: 3, Roster: []string{"pikachu", "bulbasaur"}},
+		wantError:     true,
+		expectedError: ErrInvalidPokemons,
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "failed unknown pokemon in roster",
+		input:         models.RequestBattle{Roster: []string{"pikachu", "agumon", "digimon"}},
+		wantError:     true,
+		expectedError: &UnknownPokemonError{Names: []string{"agumon", "digimon"}},
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo, scores *[]int) {
+			mock.EXPECT().GetPokemonByName(gomock.Any(), "pikachu").Return(newTestPokemon("pikachu", 35, 55, 40, 50, 50, 90), nil).Times(1)
+			mock.EXPECT().GetPokemonByName(gomock.Any(), gomock.Any()).Return(models.GetPokemon{}, repository.ErrPokemonNotFound).Times(2)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "failed duplicate pokemon in roster",
+		input:         models.RequestBattle{Roster: []string{"pikachu", "25"}},
+		wantError:     true,
+		expectedError: ErrDuplicatePokemon,
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo, scores *[]int) {
+			mock.EXPECT().GetPokemonByName(gomock.Any(), gomock.Any()).Return(newTestPokemon("pikachu", 35, 55, 40, 50, 50, 90), nil).Times(2)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "success roster",
+		input:         models.RequestBattle{Roster: []string{"Pikachu", "bulbasaur", "4"}},
+		expectedCount: 3,
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo, scores *[]int) {
+			mock.EXPECT().GetPokemonByName(gomock.Any(), "pikachu").Return(newTestPokemon("pikachu", 35, 55, 40, 50, 50, 90), nil).Times(1)
+			mock.EXPECT().GetPokemonByName(gomock.Any(), "bulbasaur").Return(newTestPokemon("bulbasaur", 45, 49, 49, 65, 65, 45), nil).Times(1)
+			mock.EXPECT().GetPokemonByName(gomock.Any(), "4").Return(newTestPokemon("charmander", 39, 52, 43, 60, 50, 65), nil).Times(1)
+			mock.EXPECT().SaveBattle(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(input models.BattleInput, participants []models.Participant, events []models.BattleEvent, rate repository.Rater) (int64, error) {
+				for _, participant := range participants {
+					*scores = append(*scores, participant.Scores)
+				}
+				return 1, nil
+			}).Times(1)
+			mock.EXPECT().GetPlayer(gomock.Any()).Return([]models.DetailPlayers{}, nil).Times(1)
+		},
+	})
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			var scores []int
+			pokeRepo := postgres_mock.NewMockPokemonRepo(mockCtrl)
+
+			if testCase.onPokemonRepo != nil {
+				testCase.onPokemonRepo(pokeRepo, &scores)
+			}
+
+			usecase := PokeUsecase{
+				PokeRepository: pokeRepo,
+				Seed: func() int64 {
+					return 1
+				},
+			}
+
+			_, serr := usecase.PostBattle(context.Background(), testCase.input)
+
+			if testCase.wantError {
+				assert.EqualError(t, serr, testCase.expectedError.Error())
+			} else {
+				assert.Nil(t, serr)
+				assert.Len(t, scores, testCase.expectedCount)
+				for i, score := range scores {
+					assert.Equal(t, testCase.expectedCount-i, score)
+				}
+			}
+		})
+	}
+}
 
-func Test_PokemonUsecase_GetPokemonScore(t *testing.T) {
+func Test_PokemonUsecase_ReplayBattle(t *testing.T) {
 	type testCase struct {
-		name              string
-		wantError         bool
-		expectedResult    []models.DetailPlayers
-		expectedError     error
-		onGetPokemonScore func(mock *postgres_mock.MockPokemonRepo)
+		name           string
+		wantError      bool
+		expectedResult models.ReplayResponse
+		expectedError  error
+		onPokemonRepo  func(mock *postgres_mock.MockPokemonRepo)
+	}
+
+	var (
+		testTable []testCase
+		roster    = []models.GetPokemon{
+			newTestPokemon("pikachu", 35, 55, 40, 50, 50, 90),
+			newTestPokemon("bulbasaur", 45, 49, 49, 65, 65, 45),
+			newTestPokemon("charmander", 39, 52, 43, 60, 50, 65),
+		}
+		battle = models.Battle{
+			BattleID:      1,
+			Winner:        "pikachu",
+			Seed:          42,
+			Roster:        []string{"pikachu", "bulbasaur", "charmander"},
+			Engine:        EngineTypeAware,
+			EngineVersion: 1,
+		}
+		replay, _  = (&PokeUsecase{}).simulateRoster(context.Background(), roster, battle.Seed, "", "")
+		placements = replay.Placements
+	)
+
+	onGetPokemonByName := func(mock *postgres_mock.MockPokemonRepo) {
+		for _, poke := range roster {
+			mock.EXPECT().GetPokemonByName(gomock.Any(), poke.Name).Return(poke, nil).Times(1)
+		}
+	}
+
+	testTable = append(testTable, testCase{
+		name:          "failed battle not found",
+		wantError:     true,
+		expectedError: repository.ErrBattleNotFound,
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo) {
+			mock.EXPECT().GetBattleByID(1).Return(models.Battle{}, repository.ErrBattleNotFound).Times(1)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "failed engine changed",
+		wantError:     true,
+		expectedError: ErrEngineChanged,
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo) {
+			old := battle
+			old.EngineVersion = 0
+			mock.EXPECT().GetBattleByID(1).Return(old, nil).Times(1)
+			mock.EXPECT().GetFinishOrder(1).Return([]string{}, nil).Times(1)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "success same outcome",
+		wantError: false,
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo) {
+			mock.EXPECT().GetBattleByID(1).Return(battle, nil).Times(1)
+			mock.EXPECT().GetFinishOrder(1).Return(placements, nil).Times(1)
+			onGetPokemonByName(mock)
+		},
+		expectedResult: models.ReplayResponse{
+			BattleID:      1,
+			Seed:          42,
+			Engine:        EngineTypeAware,
+			EngineVersion: 1,
+			Matches:       true,
+			Recorded:      placements,
+			Replayed:      placements,
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "success different outcome",
+		wantError: false,
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo) {
+			mock.EXPECT().GetBattleByID(1).Return(battle, nil).Times(1)
+			mock.EXPECT().GetFinishOrder(1).Return([]string{placements[2], placements[1], placements[0]}, nil).Times(1)
+			onGetPokemonByName(mock)
+		},
+		expectedResult: models.ReplayResponse{
+			BattleID:      1,
+			Seed:          42,
+			Engine:        EngineTypeAware,
+			EngineVersion: 1,
+			Matches:       false,
+			Recorded:      []string{placements[2], placements[1], placements[0]},
+			Replayed:      placements,
+		},
+	})
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			pokeRepo := postgres_mock.NewMockPokemonRepo(mockCtrl)
+
+			if testCase.onPokemonRepo != nil {
+				testCase.onPokemonRepo(pokeRepo)
+			}
+
+			usecase := PokeUsecase{
+				PokeRepository: pokeRepo,
+			}
+
+			data, serr := usecase.ReplayBattle(context.Background(), 1)
+
+			if testCase.wantError {
+				assert.EqualError(t, serr, testCase.expectedError.Error())
+			} else {
+				assert.Nil(t, serr)
+				assert.Equal(t, testCase.expectedResult, data)
+			}
+		})
+	}
+}
+
+func Test_PokemonUsecase_GetBattleEvents(t *testing.T) {
+	type testCase struct {
+		name           string
+		wantError      bool
+		expectedResult []models.BattleEvent
+		expectedError  error
+		onPokemonRepo  func(mock *postgres_mock.MockPokemonRepo)
 	}
 
 	var testTable []testCase
 
+	testTable = append(testTable, testCase{
+		name:          "failed battle not found",
+		wantError:     true,
+		expectedError: repository.ErrBattleNotFound,
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo) {
+			mock.EXPECT().GetBattleByID(1).Return(models.Battle{}, repository.ErrBattleNotFound).Times(1)
+		},
+	})
+
 	testTable = append(testTable, testCase{
 		name:          "failed unexpected error",
 		wantError:     true,
 		expectedError: errors.New("unexpected error"),
-		onGetPokemonScore: func(mock *postgres_mock.MockPokemonRepo) {
-			mock.EXPECT().GetPokemonScore().Return([]models.DetailPlayers{}, errors.New("unexpected error")).AnyTimes()
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo) {
+			mock.EXPECT().GetBattleByID(1).Return(models.Battle{BattleID: 1}, nil).Times(1)
+			mock.EXPECT().GetBattleEvents(1).Return(nil, errors.New("unexpected error")).Times(1)
 		},
 	})
 
 	testTable = append(testTable, testCase{
 		name:      "success",
 		wantError: false,
-		onGetPokemonScore: func(mock *postgres_mock.MockPokemonRepo) {
-			mock.EXPECT().GetPokemonScore().Return([]models.DetailPlayers{
-				{
-					Name:   "pikachu",
-					Scores: 300,
-				},
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo) {
+			mock.EXPECT().GetBattleByID(1).Return(models.Battle{BattleID: 1}, nil).Times(1)
+			mock.EXPECT().GetBattleEvents(1).Return([]models.BattleEvent{
+				{BattleID: 1, Turn: 1, Actor: "pikachu", Target: "pichu", Damage: 30, RemainingHP: 0, Eliminated: true},
 			}, nil).Times(1)
 		},
-		expectedResult: []models.DetailPlayers{
-			{
-				Name:   "pikachu",
-				Scores: 300,
+		expectedResult: []models.BattleEvent{
+			{BattleID: 1, Turn: 1, Actor: "pikachu", Target: "pichu", Damage: 30, RemainingHP: 0, Eliminated: true},
+		},
+	})
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			pokeRepo := postgres_mock.NewMockPokemonRepo(mockCtrl)
+
+			if testCase.onPokemonRepo != nil {
+				testCase.onPokemonRepo(pokeRepo)
+			}
+
+			usecase := PokeUsecase{
+				PokeRepository: pokeRepo,
+			}
+
+			data, serr := usecase.GetBattleEvents(1)
+
+			if testCase.wantError {
+				assert.EqualError(t, serr, testCase.expectedError.Error())
+			} else {
+				assert.Nil(t, serr)
+				assert.Equal(t, testCase.expectedResult, data)
+			}
+		})
+	}
+}
+
+func Test_PokemonUsecase_AnnulPokemon(t *testing.T) {
+	type testCase struct {
+		name           string
+		pokemon        string
+		wantError      bool
+		expectedResult models.BattleResponse
+		expectedError  error
+		onPokemonRepo  func(mock *postgres_mock.MockPokemonRepo)
+	}
+
+	var (
+		testTable []testCase
+		battle    = models.Battle{BattleID: 1, Winner: "pikachu", Seed: 42}
+		players   = []models.DetailPlayers{
+			{Name: "pikachu", Scores: 3, Placement: 1},
+			{Name: "bulbasaur", Scores: 2, Placement: 2},
+			{Name: "charmander", Scores: 1, Placement: 3},
+		}
+	)
+
+	testTable = append(testTable, testCase{
+		name:          "failed battle not found",
+		pokemon:       "pikachu",
+		wantError:     true,
+		expectedError: repository.ErrBattleNotFound,
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo) {
+			mock.EXPECT().GetBattleByID(1).Return(models.Battle{}, repository.ErrBattleNotFound).Times(1)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "failed pokemon not in battle",
+		pokemon:       "mewtwo",
+		wantError:     true,
+		expectedError: repository.ErrPokemonNotInBattle,
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo) {
+			mock.EXPECT().GetBattleByID(1).Return(battle, nil).Times(1)
+			mock.EXPECT().GetPlayer(1).Return(players, nil).Times(1)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "failed already annulled",
+		pokemon:       "bulbasaur",
+		wantError:     true,
+		expectedError: repository.ErrAlreadyAnnulled,
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo) {
+			mock.EXPECT().GetBattleByID(1).Return(battle, nil).Times(1)
+			mock.EXPECT().GetPlayer(1).Return([]models.DetailPlayers{
+				{Name: "pikachu", Scores: 3, Placement: 1},
+				{Name: "bulbasaur", Scores: 2, Placement: 2, Annulled: true},
+				{Name: "charmander", Scores: 2, Placement: 3},
+			}, nil).Times(1)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "failed annulled by a concurrent request",
+		pokemon:       "bulbasaur",
+		wantError:     true,
+		expectedError: repository.ErrAlreadyAnnulled,
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo) {
+			mock.EXPECT().GetBattleByID(1).Return(battle, nil).Times(1)
+			mock.EXPECT().GetPlayer(1).Return(players, nil).Times(1)
+			mock.EXPECT().AnnulPokemon(1, "bulbasaur").Return(repository.ErrAlreadyAnnulled).Times(1)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "failed last participant",
+		pokemon:       "pikachu",
+		wantError:     true,
+		expectedError: ErrLastParticipant,
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo) {
+			mock.EXPECT().GetBattleByID(1).Return(battle, nil).Times(1)
+			mock.EXPECT().GetPlayer(1).Return([]models.DetailPlayers{
+				{Name: "pikachu", Scores: 2, Placement: 1},
+				{Name: "bulbasaur", Scores: 1, Placement: 2, Annulled: true},
+			}, nil).Times(1)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "success annul winner",
+		pokemon:   " Pikachu ",
+		wantError: false,
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo) {
+			gomock.InOrder(
+				mock.EXPECT().GetBattleByID(1).Return(battle, nil),
+				mock.EXPECT().GetPlayer(1).Return(players, nil),
+				mock.EXPECT().AnnulPokemon(1, "pikachu").Return(nil),
+				mock.EXPECT().GetBattleByID(1).Return(models.Battle{BattleID: 1, Winner: "bulbasaur", Seed: 42}, nil),
+				mock.EXPECT().GetPlayer(1).Return([]models.DetailPlayers{
+					{Name: "bulbasaur", Scores: 3, Placement: 1},
+					{Name: "charmander", Scores: 2, Placement: 2},
+					{Name: "pikachu", Scores: 3, Placement: 0, Annulled: true},
+				}, nil),
+			)
+		},
+		expectedResult: models.BattleResponse{
+			BattleID: 1,
+			Winner:   "bulbasaur",
+			Seed:     42,
+			Player: []models.DetailPlayers{
+				{Name: "bulbasaur", Scores: 3, Placement: 1},
+				{Name: "charmander", Scores: 2, Placement: 2},
+				{Name: "pikachu", Scores: 3, Placement: 0, Annulled: true},
+			},
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "success annul runner up",
+		pokemon:   "bulbasaur",
+		wantError: false,
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo) {
+			gomock.InOrder(
+				mock.EXPECT().GetBattleByID(1).Return(battle, nil),
+				mock.EXPECT().GetPlayer(1).Return(players, nil),
+				mock.EXPECT().AnnulPokemon(1, "bulbasaur").Return(nil),
+				mock.EXPECT().GetBattleByID(1).Return(battle, nil),
+				mock.EXPECT().GetPlayer(1).Return([]models.DetailPlayers{
+					{Name: "pikachu", Scores: 3, Placement: 1},
+					{Name: "charmander", Scores: 2, Placement: 2},
+					{Name: "bulbasaur", Scores: 2, Placement: 0, Annulled: true},
+				}, nil),
+			)
+		},
+		expectedResult: models.BattleResponse{
+			BattleID: 1,
+			Winner:   "pikachu",
+			Seed:     42,
+			Player: []models.DetailPlayers{
+				{Name: "pikachu", Scores: 3, Placement: 1},
+				{Name: "charmander", Scores: 2, Placement: 2},
+				{Name: "bulbasaur", Scores: 2, Placement: 0, Annulled: true},
 			},
 		},
 	})
@@ -218,15 +752,15 @@ func Test_PokemonUsecase_GetPokemonScore(t *testing.T) {
 
 			pokeRepo := postgres_mock.NewMockPokemonRepo(mockCtrl)
 
-			if testCase.onGetPokemonScore != nil {
-				testCase.onGetPokemonScore(pokeRepo)
+			if testCase.onPokemonRepo != nil {
+				testCase.onPokemonRepo(pokeRepo)
 			}
 
 			usecase := PokeUsecase{
 				PokeRepository: pokeRepo,
 			}
 
-			data, serr := usecase.GetPokemonScore()
+			data, serr := usecase.AnnulPokemon(1, testCase.pokemon)
 
 			if testCase.wantError {
 				assert.EqualError(t, serr, testCase.expectedError.Error())
@@ -236,4 +770,4 @@ func Test_PokemonUsecase_GetPokemonScore(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
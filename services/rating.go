@@ -0,0 +1,156 @@
+package services
+
+import (
+	"math"
+	"pokemon/models"
+	"pokemon/repository"
+	"time"
+)
+
+const (
+	// defaults of a pokemon that never fought, straight from the Glicko-2 paper
+	DefaultRating     = 1500.0
+	DefaultRD         = 350.0
+	DefaultVolatility = 0.06
+
+	// glickoScale converts between the Glicko and the Glicko-2 scale
+	glickoScale = 173.7178
+	// glickoTau constrains how fast the volatility can change
+	glickoTau     = 0.5
+	glickoEpsilon = 0.000001
+)
+
+type RatingUsecase interface {
+	GetRatings() (res []models.Rating, err error)
+}
+
+func (p *PokeUsecase) GetRatings() (res []models.Rating, err error) {
+	res, err = p.PokeRepository.GetRatings()
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// rateBattle rates a battle as one Glicko-2 rating period where every
+// participant played every other one: finishing above an opponent counts as
+// a win, below as a loss and the best two of a drawn battle split the point
+func rateBattle(result BattleResult) repository.Rater {
+	return func(current []models.Rating) (before, after []models.Rating) {
+		byName := make(map[string]models.Rating, len(current))
+		for _, r := range current {
+			byName[r.Name] = r
+		}
+
+		before = make([]models.Rating, 0, len(result.Placements))
+		for _, name := range result.Placements {
+			r, ok := byName[name]
+			if !ok {
+				r = models.Rating{Name: name, Rating: DefaultRating, RD: DefaultRD, Volatility: DefaultVolatility}
+			}
+			before = append(before, r)
+		}
+
+		now := time.Now().UTC()
+		after = make([]models.Rating, 0, len(before))
+		for i, player := range before {
+			games := make([]glickoGame, 0, len(before)-1)
+			for j, opponent := range before {
+				if i == j {
+					continue
+				}
+
+				score := 0.0
+				if i < j {
+					score = 1
+				}
+				if result.Draw && i+j == 1 {
+					score = 0.5
+				}
+
+				games = append(games, glickoGame{rating: opponent.Rating, rd: opponent.RD, score: score})
+			}
+
+			next := glicko2(player, games)
+			next.Games = player.Games + 1
+			next.UpdatedAt = now
+			after = append(after, next)
+		}
+
+		return before, after
+	}
+}
+
+type glickoGame struct {
+	rating, rd, score float64
+}
+
+// glicko2 rates the player after one rating period, following
+// http://www.glicko.net/glicko/glicko2.pdf step by step
+func glicko2(player models.Rating, games []glickoGame) models.Rating {
+	mu := (player.Rating - DefaultRating) / glickoScale
+	phi := player.RD / glickoScale
+	sigma := player.Volatility
+
+	if len(games) == 0 {
+		player.RD = math.Min(math.Sqrt(phi*phi+sigma*sigma)*glickoScale, DefaultRD)
+		return player
+	}
+
+	var v, delta float64
+	for _, game := range games {
+		muJ := (game.rating - DefaultRating) / glickoScale
+		g := glickoG(game.rd / glickoScale)
+		e := 1 / (1 + math.Exp(-g*(mu-muJ)))
+
+		v += g * g * e * (1 - e)
+		delta += g * (game.score - e)
+	}
+	v = 1 / v
+	delta *= v
+
+	// new volatility with the Illinois algorithm
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		return ex*(delta*delta-phi*phi-v-ex)/(2*math.Pow(phi*phi+v+ex, 2)) - (x-a)/(glickoTau*glickoTau)
+	}
+
+	A := a
+	var B float64
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*glickoTau) < 0 {
+			k++
+		}
+		B = a - k*glickoTau
+	}
+
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > glickoEpsilon {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+		B, fB = C, fC
+	}
+	newSigma := math.Exp(A / 2)
+
+	phiStar := math.Sqrt(phi*phi + newSigma*newSigma)
+	newPhi := 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	newMu := mu + newPhi*newPhi*(delta/v)
+
+	player.Rating = newMu*glickoScale + DefaultRating
+	player.RD = newPhi * glickoScale
+	player.Volatility = newSigma
+	return player
+}
+
+func glickoG(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"pokemon/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Glicko2(t *testing.T) {
+	// the worked example of the Glicko-2 paper
+	player := models.Rating{Name: "pikachu", Rating: 1500, RD: 200, Volatility: 0.06}
+	games := []glickoGame{
+		{rating: 1400, rd: 30, score: 1},
+		{rating: 1550, rd: 100, score: 0},
+		{rating: 1700, rd: 300, score: 0},
+	}
+
+	res := glicko2(player, games)
+
+	assert.Equal(t, "pikachu", res.Name)
+	assert.InDelta(t, 1464.06, res.Rating, 0.01)
+	assert.InDelta(t, 151.52, res.RD, 0.01)
+	assert.InDelta(t, 0.05999, res.Volatility, 0.00001)
+}
+
+func Test_RateBattle(t *testing.T) {
+	type testCase struct {
+		name    string
+		result  BattleResult
+		current []models.Rating
+		check   func(t *testing.T, before, after []models.Rating)
+	}
+
+	var testTable []testCase
+
+	testTable = append(testTable, testCase{
+		name:   "success unrated pokemons start from the default",
+		result: BattleResult{Placements: []string{"mewtwo", "pikachu", "magikarp"}},
+		current: []models.Rating{
+			{Name: "pikachu", Rating: 1600, RD: 80, Volatility: 0.06, Games: 10},
+		},
+		check: func(t *testing.T, before, after []models.Rating) {
+			assert.Equal(t, DefaultRating, before[0].Rating)
+			assert.Equal(t, DefaultRD, before[0].RD)
+			assert.Equal(t, 1600.0, before[1].Rating)
+
+			assert.Greater(t, after[0].Rating, DefaultRating)
+			assert.Less(t, after[2].Rating, DefaultRating)
+			assert.Less(t, after[0].RD, DefaultRD)
+			assert.Equal(t, []int{1, 11, 1}, []int{after[0].Games, after[1].Games, after[2].Games})
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:   "success draw splits the point",
+		result: BattleResult{Placements: []string{"shuckle", "wall"}, Draw: true},
+		check: func(t *testing.T, before, after []models.Rating) {
+			assert.InDelta(t, DefaultRating, after[0].Rating, 0.000001)
+			assert.InDelta(t, DefaultRating, after[1].Rating, 0.000001)
+		},
+	})
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			before, after := rateBattle(testCase.result)(testCase.current)
+
+			assert.Len(t, before, len(testCase.result.Placements))
+			assert.Len(t, after, len(testCase.result.Placements))
+			testCase.check(t, before, after)
+		})
+	}
+}
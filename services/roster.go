@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"pokemon/models"
+	"pokemon/repository"
+	"strings"
+)
+
+var (
+	ErrDuplicatePokemon = errors.New("roster can't contain the same pokemon twice")
+)
+
+// UnknownPokemonError lists the roster entries PokeAPI doesn't know about
+type UnknownPokemonError struct {
+	Names []string
+}
+
+func (e *UnknownPokemonError) Error() string {
+	return fmt.Sprintf("unknown pokemon: %s", strings.Join(e.Names, ", "))
+}
+
+// lookupRoster fetches every pokemon named in the battle request
+func (p *PokeUsecase) lookupRoster(ctx context.Context, input models.RequestBattle) (res []models.GetPokemon, err error) {
+	if input.Pokemons != 0 && input.Pokemons != len(input.Roster) {
+		return nil, ErrInvalidPokemons
+	}
+	if len(input.Roster) < MinPokemons || len(input.Roster) > MaxPokemons {
+		return nil, ErrInvalidPokemons
+	}
+
+	return p.fetchRoster(ctx, input.Roster)
+}
+
+// fetchRoster fetches every pokemon of the roster, by name or PokeAPI id
+func (p *PokeUsecase) fetchRoster(ctx context.Context, roster []string) (res []models.GetPokemon, err error) {
+	var (
+		names   = make([]string, len(roster))
+		seen    = make(map[string]bool)
+		unknown []string
+	)
+
+	for i, name := range roster {
+		names[i] = strings.ToLower(strings.TrimSpace(name))
+	}
+
+	fetched, missing, err := p.fetchPokemons(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, data := range fetched {
+		if missing[i] {
+			unknown = append(unknown, names[i])
+			continue
+		}
+
+		// ids and names resolve to the same pokemon, so compare the resolved name
+		if seen[data.Name] {
+			return nil, ErrDuplicatePokemon
+		}
+		seen[data.Name] = true
+
+		res = append(res, data)
+	}
+
+	if len(unknown) > 0 {
+		return nil, &UnknownPokemonError{Names: unknown}
+	}
+
+	return res, nil
+}
+
+// randomRoster draws count different pokemons matching the filter out of the
+// whole pokedex
+func (p *PokeUsecase) randomRoster(ctx context.Context, count int, filter models.RosterFilter, rng *rand.Rand) (res []models.GetPokemon, err error) {
+	if count == 0 {
+		count = DefaultPokemons
+	}
+	if count < MinPokemons || count > MaxPokemons {
+		return nil, ErrInvalidPokemons
+	}
+	if err = validateFilter(filter); err != nil {
+		return nil, err
+	}
+
+	return p.drawRoster(ctx, count, filter, rng)
+}
+
+// drawRoster draws count different pokemons matching the filter out of the
+// whole pokedex. Without a filter only the drawn pokemons are looked up,
+// with one the shuffled pokedex is looked up a batch at a time until enough
+// of them match.
+func (p *PokeUsecase) drawRoster(ctx context.Context, count int, filter models.RosterFilter, rng *rand.Rand) (res []models.GetPokemon, err error) {
+	all, err := p.PokeRepository.GetAllPokemons(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(all.Results) < count {
+		return nil, ErrInvalidPokemons
+	}
+
+	perm := rng.Perm(len(all.Results))
+	if filter == (models.RosterFilter{}) {
+		return p.fetchDrawn(ctx, all, perm[:count])
+	}
+
+	batch := p.fetchWorkers()
+	for start := 0; start < len(perm) && len(res) < count; start += batch {
+		end := start + batch
+		if end > len(perm) {
+			end = len(perm)
+		}
+
+		names := make([]string, 0, end-start)
+		for _, n := range perm[start:end] {
+			names = append(names, all.Results[n].Name)
+		}
+
+		fetched, missing, err := p.fetchPokemons(ctx, names)
+		if err != nil {
+			return nil, err
+		}
+
+		var candidates []models.GetPokemon
+		for i, poke := range fetched {
+			if !missing[i] && matchesPokemon(filter, poke) {
+				candidates = append(candidates, poke)
+			}
+		}
+
+		if needsSpecies(filter) && len(candidates) > 0 {
+			species, missing, err := p.fetchSpecies(ctx, candidates)
+			if err != nil {
+				return nil, err
+			}
+
+			matching := candidates[:0]
+			for i, poke := range candidates {
+				if !missing[i] && matchesSpecies(filter, species[i]) {
+					matching = append(matching, poke)
+				}
+			}
+			candidates = matching
+		}
+
+		for _, poke := range candidates {
+			if len(res) == count {
+				break
+			}
+			res = append(res, poke)
+		}
+	}
+
+	if len(res) < count {
+		return nil, ErrNotEnoughPokemons
+	}
+
+	return res, nil
+}
+
+// fetchDrawn looks up the pokemons at the drawn positions of the pokedex
+func (p *PokeUsecase) fetchDrawn(ctx context.Context, all models.AllPokemon, drawn []int) (res []models.GetPokemon, err error) {
+	names := make([]string, len(drawn))
+	for i, n := range drawn {
+		names[i] = all.Results[n].Name
+	}
+
+	res, missing, err := p.fetchPokemons(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+
+	// the pokedex listed it, so it vanishing is as bad as any other failure
+	for i := range missing {
+		if missing[i] {
+			return nil, repository.ErrPokemonNotFound
+		}
+	}
+
+	return res, nil
+}
+
+// baseStatTotal sums every base stat of the pokemon
+func baseStatTotal(poke models.GetPokemon) (total int) {
+	for _, s := range poke.Stats {
+		total += s.BaseStat
+	}
+	return total
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"pokemon/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultBattlePageSize = 20
+	MaxBattlePageSize     = 100
+
+	searchDateLayout = "2006-01-02"
+	cursorPrefix     = "battle:"
+)
+
+var ErrInvalidSearch = errors.New("invalid battle search")
+
+// battleSearch validates the query string of GET /battles
+func battleSearch(input models.RequestBattleSearch) (res models.BattleSearch, err error) {
+	res = models.BattleSearch{
+		Winner:          strings.ToLower(strings.TrimSpace(input.Winner)),
+		Participant:     strings.ToLower(strings.TrimSpace(input.Participant)),
+		MinParticipants: input.MinParticipants,
+		Engine:          input.Engine,
+		Limit:           input.Limit,
+	}
+
+	if input.StartTime != "" {
+		if res.StartFrom, err = time.Parse(searchDateLayout, input.StartTime); err != nil {
+			return res, fmt.Errorf("%w: start_time must look like %s", ErrInvalidSearch, searchDateLayout)
+		}
+	}
+	if input.EndTime != "" {
+		end, err := time.Parse(searchDateLayout, input.EndTime)
+		if err != nil {
+			return res, fmt.Errorf("%w: end_time must look like %s", ErrInvalidSearch, searchDateLayout)
+		}
+		// the whole end day is included
+		res.StartBefore = end.AddDate(0, 0, 1)
+	}
+	if !res.StartFrom.IsZero() && !res.StartBefore.IsZero() && !res.StartFrom.Before(res.StartBefore) {
+		return res, fmt.Errorf("%w: start_time can't be after end_time", ErrInvalidSearch)
+	}
+
+	if res.MinParticipants < 0 {
+		return res, fmt.Errorf("%w: min_participants can't be negative", ErrInvalidSearch)
+	}
+	if res.Engine != "" {
+		if _, err := battleEngine(res.Engine); err != nil {
+			return res, fmt.Errorf("%w: unknown engine %q", ErrInvalidSearch, res.Engine)
+		}
+	}
+
+	switch strings.ToLower(input.Order) {
+	case "", "desc":
+	case "asc":
+		res.Ascending = true
+	default:
+		return res, fmt.Errorf("%w: order must be asc or desc", ErrInvalidSearch)
+	}
+
+	switch {
+	case res.Limit == 0:
+		res.Limit = DefaultBattlePageSize
+	case res.Limit < 0 || res.Limit > MaxBattlePageSize:
+		return res, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidSearch, MaxBattlePageSize)
+	}
+
+	if input.Cursor != "" {
+		if res.AfterID, err = decodeCursor(input.Cursor); err != nil {
+			return res, err
+		}
+	}
+
+	return res, nil
+}
+
+// encodeCursor hides the id of the last battle of a page, callers only hand
+// it back
+func encodeCursor(battleID int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(battleID)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), cursorPrefix) {
+		return 0, fmt.Errorf("%w: bad cursor", ErrInvalidSearch)
+	}
+
+	id, err := strconv.Atoi(strings.TrimPrefix(string(raw), cursorPrefix))
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("%w: bad cursor", ErrInvalidSearch)
+	}
+	return id, nil
+}
@@ -1,17 +1,49 @@
 package services
 
-import "pokemon/repository"
+import (
+	"pokemon/repository"
+	"time"
+)
 
 type PokeUsecase struct {
 	PokeRepository repository.PokeRepoInterface
+	// Seed returns the seed of a battle that doesn't ask for one
+	Seed func() int64
+	// Now is the clock of the times stored and of the day of the leaderboard
+	// windows that don't name one, it's read in UTC
+	Now func() time.Time
+	// FetchWorkers bounds the concurrent pokemon lookups, 0 uses
+	// DefaultFetchWorkers
+	FetchWorkers int
 }
 
 type PokeUsecaseInterface interface {
 	PokemonUsecase
+	TournamentUsecase
+	LeagueUsecase
+	RatingUsecase
+	CacheUsecase
 }
 
 func NewPokeUsecase(pokeRepo repository.PokeRepoInterface) PokeUsecaseInterface {
 	return &PokeUsecase{
 		PokeRepository: pokeRepo,
+		Seed: func() int64 {
+			return time.Now().UnixNano()
+		},
 	}
 }
+
+func (p *PokeUsecase) newSeed() int64 {
+	if p.Seed == nil {
+		return time.Now().UnixNano()
+	}
+	return p.Seed()
+}
+
+func (p *PokeUsecase) now() time.Time {
+	if p.Now == nil {
+		return time.Now().UTC()
+	}
+	return p.Now().UTC()
+}
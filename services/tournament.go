@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"pokemon/models"
+	"sort"
+)
+
+var (
+	ErrInvalidTournamentSize   = errors.New("tournament size must be 8, 16 or 32")
+	ErrInvalidTournamentRoster = errors.New("tournament roster must have exactly size pokemons")
+	ErrTournamentFinished      = errors.New("tournament is already finished")
+)
+
+var tournamentSizes = map[int]bool{8: true, 16: true, 32: true}
+
+type TournamentUsecase interface {
+	CreateTournament(ctx context.Context, input models.RequestTournament) (res models.TournamentResponse, err error)
+	AdvanceTournament(ctx context.Context, tournamentID int) (res models.TournamentResponse, err error)
+	GetTournament(tournamentID int) (res models.TournamentResponse, err error)
+}
+
+// CreateTournament seeds the entrants by base stat total and draws the first
+// round so the top seeds can only meet late in the bracket
+func (p *PokeUsecase) CreateTournament(ctx context.Context, input models.RequestTournament) (res models.TournamentResponse, err error) {
+	var (
+		entrants []models.GetPokemon
+		seed     int64
+	)
+
+	if !tournamentSizes[input.Size] {
+		return res, ErrInvalidTournamentSize
+	}
+
+	if input.Seed != nil {
+		seed = *input.Seed
+	} else {
+		seed = p.newSeed()
+	}
+
+	if len(input.Roster) > 0 {
+		if len(input.Roster) != input.Size {
+			return res, ErrInvalidTournamentRoster
+		}
+		entrants, err = p.fetchRoster(ctx, input.Roster)
+	} else {
+		entrants, err = p.drawRoster(ctx, input.Size, models.RosterFilter{}, rand.New(rand.NewSource(seed)))
+	}
+	if err != nil {
+		return res, err
+	}
+
+	sort.SliceStable(entrants, func(i, j int) bool {
+		ti, tj := baseStatTotal(entrants[i]), baseStatTotal(entrants[j])
+		if ti != tj {
+			return ti > tj
+		}
+		return entrants[i].Name < entrants[j].Name
+	})
+
+	order := bracketOrder(input.Size)
+	matches := make([]models.TournamentMatch, 0, input.Size/2)
+	for slot := 0; slot < input.Size/2; slot++ {
+		matches = append(matches, models.TournamentMatch{
+			Round:    1,
+			Slot:     slot,
+			PokemonA: entrants[order[2*slot]-1].Name,
+			PokemonB: entrants[order[2*slot+1]-1].Name,
+		})
+	}
+
+	Id, err := p.PokeRepository.PostTournament(models.Tournament{
+		Name:      input.Name,
+		Size:      input.Size,
+		Seed:      seed,
+		Round:     1,
+		CreatedAt: p.now(),
+	}, matches)
+	if err != nil {
+		return res, err
+	}
+
+	return p.GetTournament(int(Id))
+}
+
+// AdvanceTournament fights every match of the current round and pairs the
+// winners up for the next one, the winner of the final wins the tournament
+func (p *PokeUsecase) AdvanceTournament(ctx context.Context, tournamentID int) (res models.TournamentResponse, err error) {
+	tournament, err := p.PokeRepository.GetTournament(tournamentID)
+	if err != nil {
+		return res, err
+	}
+	if tournament.Winner != "" {
+		return res, ErrTournamentFinished
+	}
+
+	matches, err := p.PokeRepository.GetTournamentMatches(tournamentID)
+	if err != nil {
+		return res, err
+	}
+
+	winners := make([]string, 0)
+	for _, match := range matches {
+		if match.Round != tournament.Round {
+			continue
+		}
+
+		// matches already fought by an advance that failed half way are kept
+		if match.Winner != "" {
+			winners = append(winners, match.Winner)
+			continue
+		}
+
+		fight, err := p.fetchRoster(ctx, []string{match.PokemonA, match.PokemonB})
+		if err != nil {
+			return res, err
+		}
+
+		seed := tournament.Seed + int64(match.Round*tournament.Size+match.Slot)
+		result, err := p.simulateRoster(ctx, fight, seed, DefaultEngine, MovePolicyRandom)
+		if err != nil {
+			return res, err
+		}
+
+		_, err = p.saveMatchBattle(match.MatchID, fight, seed, result, p.now())
+		if err != nil {
+			return res, err
+		}
+
+		winners = append(winners, result.Placements[0])
+	}
+
+	// the next round and the round number are written together, a retry
+	// after a failure finds the round as it was
+	var (
+		winner string
+		next   []models.TournamentMatch
+	)
+	if len(winners) == 1 {
+		winner = winners[0]
+	}
+	for slot := 0; slot < len(winners)/2; slot++ {
+		next = append(next, models.TournamentMatch{
+			TournamentID: tournamentID,
+			Round:        tournament.Round + 1,
+			Slot:         slot,
+			PokemonA:     winners[2*slot],
+			PokemonB:     winners[2*slot+1],
+		})
+	}
+
+	err = p.PokeRepository.AdvanceTournament(tournamentID, tournament.Round, winner, next)
+	if err != nil {
+		return res, err
+	}
+
+	return p.GetTournament(tournamentID)
+}
+
+// GetTournament returns the tournament with its bracket as a tree rooted at the final
+func (p *PokeUsecase) GetTournament(tournamentID int) (res models.TournamentResponse, err error) {
+	tournament, err := p.PokeRepository.GetTournament(tournamentID)
+	if err != nil {
+		return res, err
+	}
+
+	matches, err := p.PokeRepository.GetTournamentMatches(tournamentID)
+	if err != nil {
+		return res, err
+	}
+
+	res = models.TournamentResponse{
+		Tournament: tournament,
+		Bracket:    buildBracket(tournament.Size, matches),
+	}
+	return res, nil
+}
+
+// bracketOrder lists the seeds in bracket order, e.g. 1 8 4 5 2 7 3 6 for 8
+// entrants, so that neighbours meet in the first round
+func bracketOrder(size int) []int {
+	order := []int{1}
+	for len(order) < size {
+		next := make([]int, 0, len(order)*2)
+		for _, seed := range order {
+			next = append(next, seed, len(order)*2+1-seed)
+		}
+		order = next
+	}
+	return order
+}
+
+// buildBracket links the matches into a tree, matches of rounds that are not
+// drawn yet show up as empty nodes
+func buildBracket(size int, matches []models.TournamentMatch) *models.BracketNode {
+	type key struct{ round, slot int }
+
+	var (
+		rounds  int
+		byRound = make(map[key]models.TournamentMatch)
+		build   func(round, slot int) *models.BracketNode
+	)
+
+	for n := size; n > 1; n /= 2 {
+		rounds++
+	}
+
+	for _, match := range matches {
+		byRound[key{match.Round, match.Slot}] = match
+	}
+
+	build = func(round, slot int) *models.BracketNode {
+		node := &models.BracketNode{TournamentMatch: byRound[key{round, slot}]}
+		node.Round, node.Slot = round, slot
+		if round > 1 {
+			node.Children = []*models.BracketNode{
+				build(round-1, 2*slot),
+				build(round-1, 2*slot+1),
+			}
+		}
+		return node
+	}
+
+	return build(rounds, 0)
+}
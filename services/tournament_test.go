@@ -0,0 +1,338 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pokemon/models"
+	"pokemon/repository"
+	postgres_mock "pokemon/repository/mocks"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BracketOrder(t *testing.T) {
+	assert.Equal(t, []int{1, 8, 4, 5, 2, 7, 3, 6}, bracketOrder(8))
+	assert.Equal(t, []int{1, 16, 8, 9, 4, 13, 5, 12, 2, 15, 7, 10, 3, 14, 6, 11}, bracketOrder(16))
+	assert.Len(t, bracketOrder(32), 32)
+}
+
+func Test_BuildBracket(t *testing.T) {
+	matches := []models.TournamentMatch{
+		{MatchID: 1, Round: 1, Slot: 0, PokemonA: "a", PokemonB: "h", Winner: "a"},
+		{MatchID: 2, Round: 1, Slot: 1, PokemonA: "d", PokemonB: "e", Winner: "e"},
+		{MatchID: 3, Round: 1, Slot: 2, PokemonA: "b", PokemonB: "g"},
+		{MatchID: 4, Round: 1, Slot: 3, PokemonA: "c", PokemonB: "f"},
+		{MatchID: 5, Round: 2, Slot: 0, PokemonA: "a", PokemonB: "e"},
+	}
+
+	root := buildBracket(8, matches)
+
+	assert.Equal(t, 3, root.Round)
+	assert.Equal(t, 0, root.MatchID)
+	assert.Len(t, root.Children, 2)
+	assert.Equal(t, 5, root.Children[0].MatchID)
+	assert.Equal(t, 2, root.Children[1].Round)
+	assert.Equal(t, 1, root.Children[1].Slot)
+	assert.Equal(t, "h", root.Children[0].Children[0].PokemonB)
+	assert.Equal(t, "f", root.Children[1].Children[1].PokemonB)
+	assert.Nil(t, root.Children[1].Children[1].Children)
+}
+
+func Test_PokemonUsecase_CreateTournament(t *testing.T) {
+	type testCase struct {
+		name            string
+		input           models.RequestTournament
+		wantError       bool
+		expectedError   error
+		expectedMatches []models.TournamentMatch
+		onPokemonRepo   func(mock *postgres_mock.MockPokemonRepo, matches *[]models.TournamentMatch)
+	}
+
+	var (
+		testTable []testCase
+		roster    []string
+	)
+
+	// pokemon-0 is the strongest, pokemon-7 the weakest
+	for i := 0; i < 8; i++ {
+		roster = append(roster, fmt.Sprintf("pokemon-%d", i))
+	}
+
+	testTable = append(testTable, testCase{
+		name:          "failed invalid size",
+		input:         models.RequestTournament{Size: 10},
+		wantError:     true,
+		expectedError: ErrInvalidTournamentSize,
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "failed roster size mismatch",
+		input:         models.RequestTournament{Size: 8, Roster: roster[:4]},
+		wantError:     true,
+		expectedError: ErrInvalidTournamentRoster,
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "failed unknown pokemon",
+		input:         models.RequestTournament{Size: 8, Roster: roster},
+		wantError:     true,
+		expectedError: &UnknownPokemonError{Names: []string{"pokemon-7"}},
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo, matches *[]models.TournamentMatch) {
+			mock.EXPECT().GetPokemonByName(gomock.Any(), "pokemon-7").Return(models.GetPokemon{}, repository.ErrPokemonNotFound).Times(1)
+			mock.EXPECT().GetPokemonByName(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, name string) (models.GetPokemon, error) {
+				return newTestPokemon(name, 50, 50, 50, 50, 50, 50), nil
+			}).AnyTimes()
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:  "success seeded by base stat total",
+		input: models.RequestTournament{Name: "cup", Size: 8, Roster: []string{roster[3], roster[6], roster[0], roster[5], roster[7], roster[1], roster[4], roster[2]}},
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo, matches *[]models.TournamentMatch) {
+			mock.EXPECT().GetPokemonByName(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, name string) (models.GetPokemon, error) {
+				var i int
+				fmt.Sscanf(name, "pokemon-%d", &i)
+				base := 100 - i*10
+				return newTestPokemon(name, base, base, base, base, base, base), nil
+			}).Times(8)
+			mock.EXPECT().PostTournament(gomock.Any(), gomock.Any()).DoAndReturn(func(input models.Tournament, drawn []models.TournamentMatch) (int64, error) {
+				for _, match := range drawn {
+					match.TournamentID = 1
+					*matches = append(*matches, match)
+				}
+				return 1, nil
+			}).Times(1)
+			mock.EXPECT().GetTournament(1).Return(models.Tournament{TournamentID: 1, Name: "cup", Size: 8, Round: 1}, nil).Times(1)
+			mock.EXPECT().GetTournamentMatches(1).DoAndReturn(func(id int) ([]models.TournamentMatch, error) {
+				return *matches, nil
+			}).Times(1)
+		},
+		expectedMatches: []models.TournamentMatch{
+			{TournamentID: 1, Round: 1, Slot: 0, PokemonA: "pokemon-0", PokemonB: "pokemon-7"},
+			{TournamentID: 1, Round: 1, Slot: 1, PokemonA: "pokemon-3", PokemonB: "pokemon-4"},
+			{TournamentID: 1, Round: 1, Slot: 2, PokemonA: "pokemon-1", PokemonB: "pokemon-6"},
+			{TournamentID: 1, Round: 1, Slot: 3, PokemonA: "pokemon-2", PokemonB: "pokemon-5"},
+		},
+	})
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			var matches []models.TournamentMatch
+			pokeRepo := postgres_mock.NewMockPokemonRepo(mockCtrl)
+
+			if testCase.onPokemonRepo != nil {
+				testCase.onPokemonRepo(pokeRepo, &matches)
+			}
+
+			usecase := PokeUsecase{
+				PokeRepository: pokeRepo,
+			}
+
+			data, serr := usecase.CreateTournament(context.Background(), testCase.input)
+
+			if testCase.wantError {
+				assert.EqualError(t, serr, testCase.expectedError.Error())
+			} else {
+				assert.Nil(t, serr)
+				assert.Equal(t, testCase.expectedMatches, matches)
+				assert.Equal(t, 3, data.Bracket.Round)
+			}
+		})
+	}
+}
+
+func Test_PokemonUsecase_AdvanceTournament(t *testing.T) {
+	type testCase struct {
+		name           string
+		wantError      bool
+		expectedError  error
+		expectedWinner string
+		onPokemonRepo  func(mock *postgres_mock.MockPokemonRepo)
+	}
+
+	var (
+		testTable []testCase
+		strong    = newTestPokemon("mewtwo", 106, 110, 90, 154, 90, 130)
+		weak      = newTestPokemon("magikarp", 20, 10, 55, 15, 20, 80)
+	)
+
+	onGetPokemonByName := func(mock *postgres_mock.MockPokemonRepo) {
+		mock.EXPECT().GetPokemonByName(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, name string) (models.GetPokemon, error) {
+			if name == strong.Name {
+				return strong, nil
+			}
+			p := weak
+			p.Name = name
+			return p, nil
+		}).AnyTimes()
+	}
+
+	onSaveBattle := func(mock *postgres_mock.MockPokemonRepo, matchID int) {
+		mock.EXPECT().SaveTournamentBattle(matchID, gomock.Any(), gomock.Len(2), gomock.Any(), gomock.Any()).Return(int64(7), nil).Times(1)
+	}
+
+	testTable = append(testTable, testCase{
+		name:          "failed tournament not found",
+		wantError:     true,
+		expectedError: repository.ErrTournamentNotFound,
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo) {
+			mock.EXPECT().GetTournament(1).Return(models.Tournament{}, repository.ErrTournamentNotFound).Times(1)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "failed tournament finished",
+		wantError:     true,
+		expectedError: ErrTournamentFinished,
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo) {
+			mock.EXPECT().GetTournament(1).Return(models.Tournament{TournamentID: 1, Size: 8, Round: 3, Winner: "mewtwo"}, nil).Times(1)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "failed unexpected error",
+		wantError:     true,
+		expectedError: errors.New("unexpected error"),
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo) {
+			mock.EXPECT().GetTournament(1).Return(models.Tournament{TournamentID: 1, Size: 8, Round: 1}, nil).Times(1)
+			mock.EXPECT().GetTournamentMatches(1).Return(nil, errors.New("unexpected error")).Times(1)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name: "success semi finals drawn",
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo) {
+			tournament := models.Tournament{TournamentID: 1, Size: 8, Round: 2}
+			matches := []models.TournamentMatch{
+				{MatchID: 5, TournamentID: 1, Round: 2, Slot: 0, PokemonA: "mewtwo", PokemonB: "pichu"},
+				{MatchID: 6, TournamentID: 1, Round: 2, Slot: 1, PokemonA: "eevee", PokemonB: "ditto", Winner: "ditto", BattleID: 3},
+			}
+			mock.EXPECT().GetTournament(1).Return(tournament, nil).Times(2)
+			mock.EXPECT().GetTournamentMatches(1).Return(matches, nil).Times(2)
+			onGetPokemonByName(mock)
+			onSaveBattle(mock, 5)
+			mock.EXPECT().AdvanceTournament(1, 2, "", []models.TournamentMatch{
+				{TournamentID: 1, Round: 3, Slot: 0, PokemonA: "mewtwo", PokemonB: "ditto"},
+			}).Return(nil).Times(1)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "failed match fought by another advance",
+		wantError:     true,
+		expectedError: repository.ErrMatchPlayed,
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo) {
+			mock.EXPECT().GetTournament(1).Return(models.Tournament{TournamentID: 1, Size: 8, Round: 3}, nil).Times(1)
+			mock.EXPECT().GetTournamentMatches(1).Return([]models.TournamentMatch{
+				{MatchID: 7, TournamentID: 1, Round: 3, Slot: 0, PokemonA: "ditto", PokemonB: "mewtwo"},
+			}, nil).Times(1)
+			onGetPokemonByName(mock)
+			mock.EXPECT().SaveTournamentBattle(7, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(0), repository.ErrMatchPlayed).Times(1)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:           "success final crowns the winner",
+		expectedWinner: "mewtwo",
+		onPokemonRepo: func(mock *postgres_mock.MockPokemonRepo) {
+			matches := []models.TournamentMatch{
+				{MatchID: 7, TournamentID: 1, Round: 3, Slot: 0, PokemonA: "ditto", PokemonB: "mewtwo"},
+			}
+			gomock.InOrder(
+				mock.EXPECT().GetTournament(1).Return(models.Tournament{TournamentID: 1, Size: 8, Round: 3}, nil),
+				mock.EXPECT().GetTournament(1).Return(models.Tournament{TournamentID: 1, Size: 8, Round: 3, Winner: "mewtwo"}, nil),
+			)
+			mock.EXPECT().GetTournamentMatches(1).Return(matches, nil).Times(2)
+			onGetPokemonByName(mock)
+			onSaveBattle(mock, 7)
+			mock.EXPECT().AdvanceTournament(1, 3, "mewtwo", nil).Return(nil).Times(1)
+		},
+	})
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			pokeRepo := postgres_mock.NewMockPokemonRepo(mockCtrl)
+
+			if testCase.onPokemonRepo != nil {
+				testCase.onPokemonRepo(pokeRepo)
+			}
+
+			usecase := PokeUsecase{
+				PokeRepository: pokeRepo,
+			}
+
+			data, serr := usecase.AdvanceTournament(context.Background(), 1)
+
+			if testCase.wantError {
+				assert.EqualError(t, serr, testCase.expectedError.Error())
+			} else {
+				assert.Nil(t, serr)
+				assert.Equal(t, testCase.expectedWinner, data.Winner)
+			}
+		})
+	}
+}
+
+func Test_PokemonUsecase_AdvanceTournament_Retry(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	var (
+		pokeRepo   = postgres_mock.NewMockPokemonRepo(mockCtrl)
+		tournament = models.Tournament{TournamentID: 1, Size: 8, Round: 2}
+		next       = []models.TournamentMatch{
+			{TournamentID: 1, Round: 3, Slot: 0, PokemonA: "mewtwo", PokemonB: "ditto"},
+		}
+		open = []models.TournamentMatch{
+			{MatchID: 5, TournamentID: 1, Round: 2, Slot: 0, PokemonA: "mewtwo", PokemonB: "pichu"},
+			{MatchID: 6, TournamentID: 1, Round: 2, Slot: 1, PokemonA: "eevee", PokemonB: "ditto", Winner: "ditto", BattleID: 3},
+		}
+		fought = []models.TournamentMatch{
+			{MatchID: 5, TournamentID: 1, Round: 2, Slot: 0, PokemonA: "mewtwo", PokemonB: "pichu", Winner: "mewtwo", BattleID: 7},
+			open[1],
+		}
+	)
+
+	pokeRepo.EXPECT().GetPokemonByName(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, name string) (models.GetPokemon, error) {
+		if name == "mewtwo" {
+			return newTestPokemon("mewtwo", 106, 110, 90, 154, 90, 130), nil
+		}
+		return newTestPokemon(name, 20, 10, 55, 15, 20, 80), nil
+	}).AnyTimes()
+
+	gomock.InOrder(
+		// the first advance fights the open match but fails to draw the final
+		pokeRepo.EXPECT().GetTournament(1).Return(tournament, nil),
+		pokeRepo.EXPECT().GetTournamentMatches(1).Return(open, nil),
+		pokeRepo.EXPECT().SaveTournamentBattle(5, gomock.Any(), gomock.Len(2), gomock.Any(), gomock.Any()).Return(int64(7), nil),
+		pokeRepo.EXPECT().AdvanceTournament(1, 2, "", next).Return(errors.New("unexpected error")),
+
+		// the retry finds the round unchanged and only draws the final again
+		pokeRepo.EXPECT().GetTournament(1).Return(tournament, nil),
+		pokeRepo.EXPECT().GetTournamentMatches(1).Return(fought, nil),
+		pokeRepo.EXPECT().AdvanceTournament(1, 2, "", next).Return(nil),
+		pokeRepo.EXPECT().GetTournament(1).Return(models.Tournament{TournamentID: 1, Size: 8, Round: 3}, nil),
+		pokeRepo.EXPECT().GetTournamentMatches(1).Return(append(fought, models.TournamentMatch{MatchID: 8, TournamentID: 1, Round: 3, Slot: 0, PokemonA: "mewtwo", PokemonB: "ditto"}), nil),
+	)
+
+	usecase := PokeUsecase{
+		PokeRepository: pokeRepo,
+	}
+
+	_, err := usecase.AdvanceTournament(context.Background(), 1)
+	assert.EqualError(t, err, "unexpected error")
+
+	data, err := usecase.AdvanceTournament(context.Background(), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, data.Round)
+	assert.Equal(t, 8, data.Bracket.MatchID)
+}
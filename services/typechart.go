@@ -0,0 +1,56 @@
+package services
+
+// pokemonTypes lists the 18 types in the order of the rows and columns of typeChart
+var pokemonTypes = []string{
+	"normal", "fire", "water", "electric", "grass", "ice",
+	"fighting", "poison", "ground", "flying", "psychic", "bug",
+	"rock", "ghost", "dragon", "dark", "steel", "fairy",
+}
+
+// typeChart holds the damage multiplier of an attacking type (row) against a
+// defending type (column), as of generation 6
+var typeChart = [18][18]float64{
+	{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, .5, 0, 1, 1, .5, 1},      // normal
+	{1, .5, .5, 1, 2, 2, 1, 1, 1, 1, 1, 2, .5, 1, .5, 1, 2, 1},    // fire
+	{1, 2, .5, 1, .5, 1, 1, 1, 2, 1, 1, 1, 2, 1, .5, 1, 1, 1},     // water
+	{1, 1, 2, .5, .5, 1, 1, 1, 0, 2, 1, 1, 1, 1, .5, 1, 1, 1},     // electric
+	{1, .5, 2, 1, .5, 1, 1, .5, 2, .5, 1, .5, 2, 1, .5, 1, .5, 1}, // grass
+	{1, .5, .5, 1, 2, .5, 1, 1, 2, 2, 1, 1, 1, 1, 2, 1, .5, 1},    // ice
+	{2, 1, 1, 1, 1, 2, 1, .5, 1, .5, .5, .5, 2, 0, 1, 2, 2, .5},   // fighting
+	{1, 1, 1, 1, 2, 1, 1, .5, .5, 1, 1, 1, .5, .5, 1, 1, 0, 2},    // poison
+	{1, 2, 1, 2, .5, 1, 1, 2, 1, 0, 1, .5, 2, 1, 1, 1, 2, 1},      // ground
+	{1, 1, 1, .5, 2, 1, 2, 1, 1, 1, 1, 2, .5, 1, 1, 1, .5, 1},     // flying
+	{1, 1, 1, 1, 1, 1, 2, 2, 1, 1, .5, 1, 1, 1, 1, 0, .5, 1},      // psychic
+	{1, .5, 1, 1, 2, 1, .5, .5, 1, .5, 2, 1, 1, .5, 1, 2, .5, .5}, // bug
+	{1, 2, 1, 1, 1, 2, .5, 1, .5, 2, 1, 2, 1, 1, 1, 1, .5, 1},     // rock
+	{0, 1, 1, 1, 1, 1, 1, 1, 1, 1, 2, 1, 1, 2, 1, .5, 1, 1},       // ghost
+	{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 2, 1, .5, 0},       // dragon
+	{1, 1, 1, 1, 1, 1, .5, 1, 1, 1, 2, 1, 1, 2, 1, .5, 1, .5},     // dark
+	{1, .5, .5, .5, 1, 2, 1, 1, 1, 1, 1, 1, 2, 1, 1, 1, .5, 2},    // steel
+	{1, .5, 1, 1, 1, 1, 2, .5, 1, 1, 1, 1, 1, 1, 2, 2, .5, 1},     // fairy
+}
+
+var typeIndex = func() map[string]int {
+	res := make(map[string]int, len(pokemonTypes))
+	for i, name := range pokemonTypes {
+		res[name] = i
+	}
+	return res
+}()
+
+// Effectiveness multiplies the chart entries of the attacking type against
+// every type of the defender, types that aren't on the chart count as neutral
+func Effectiveness(attack string, defender []string) float64 {
+	row, ok := typeIndex[attack]
+	if !ok {
+		return 1
+	}
+
+	res := 1.0
+	for _, t := range defender {
+		if col, ok := typeIndex[t]; ok {
+			res *= typeChart[row][col]
+		}
+	}
+	return res
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Effectiveness(t *testing.T) {
+	type testCase struct {
+		name     string
+		attack   string
+		defender []string
+		expected float64
+	}
+
+	var testTable []testCase
+
+	testTable = append(testTable, testCase{
+		name:     "super effective",
+		attack:   "water",
+		defender: []string{"fire"},
+		expected: 2,
+	})
+
+	testTable = append(testTable, testCase{
+		name:     "immune",
+		attack:   "electric",
+		defender: []string{"ground"},
+		expected: 0,
+	})
+
+	testTable = append(testTable, testCase{
+		name:     "dual type multiplies",
+		attack:   "fire",
+		defender: []string{"grass", "steel"},
+		expected: 4,
+	})
+
+	testTable = append(testTable, testCase{
+		name:     "dual type resists",
+		attack:   "fire",
+		defender: []string{"water", "rock"},
+		expected: 0.25,
+	})
+
+	testTable = append(testTable, testCase{
+		name:     "immunity wins over weakness",
+		attack:   "ground",
+		defender: []string{"electric", "flying"},
+		expected: 0,
+	})
+
+	testTable = append(testTable, testCase{
+		name:     "unknown types are neutral",
+		attack:   "shadow",
+		defender: []string{"fire"},
+		expected: 1,
+	})
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, Effectiveness(testCase.attack, testCase.defender))
+		})
+	}
+}
+
+func Test_TypeChart(t *testing.T) {
+	assert.Len(t, pokemonTypes, len(typeChart))
+	for i, name := range pokemonTypes {
+		assert.Equal(t, i, typeIndex[name])
+	}
+}